@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/internal/fieldgen"
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+func main() {
+	var (
+		dumpPath     = flag.String("dump", "", "read field definitions from this JSON dump instead of the API")
+		saveDumpPath = flag.String("save-dump", "", "write fetched field definitions to this JSON dump")
+		outPath      = flag.String("out", "-", "output path for generated Go code (- for stdout)")
+		pkg          = flag.String("package", "customfields", "package name of the generated code")
+		entitiesCSV  = flag.String("entities", "", "comma-separated entities to include (default: all)")
+		timeout      = flag.Duration("timeout", time.Minute, "timeout for fetching field definitions")
+	)
+	flag.Parse()
+
+	entities, err := fieldgen.ParseEntities(*entitiesCSV)
+	fatalIf(err, "parse entities")
+
+	var dump fieldgen.Dump
+	if *dumpPath != "" {
+		data, err := os.ReadFile(*dumpPath)
+		fatalIf(err, "read dump")
+		dump, err = fieldgen.LoadDump(data)
+		fatalIf(err, "load dump")
+		selected := make(fieldgen.Dump, len(entities))
+		for _, entity := range entities {
+			if fields, ok := dump[entity]; ok {
+				selected[entity] = fields
+			}
+		}
+		dump = selected
+	} else {
+		token := strings.TrimSpace(os.Getenv("PIPEDRIVE_API_TOKEN"))
+		if token == "" {
+			fatalIf(fmt.Errorf("PIPEDRIVE_API_TOKEN is required without -dump"), "configure client")
+		}
+		cfg := pipedrive.Config{Auth: pipedrive.APITokenAuth(token)}
+		if baseURL := strings.TrimSpace(os.Getenv("PIPEDRIVE_BASE_URL_V2")); baseURL != "" {
+			cfg.BaseURL = baseURL
+		}
+		client, err := v2.NewClient(cfg)
+		fatalIf(err, "create client")
+
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		dump, err = fieldgen.Fetch(ctx, client, entities)
+		cancel()
+		fatalIf(err, "fetch field definitions")
+
+		if *saveDumpPath != "" {
+			data, err := fieldgen.SaveDump(dump)
+			fatalIf(err, "encode dump")
+			writeFile(*saveDumpPath, data, "write dump")
+		}
+	}
+
+	code, err := fieldgen.Generate(dump, fieldgen.Options{Package: *pkg})
+	fatalIf(err, "generate code")
+
+	if *outPath == "-" {
+		_, err = os.Stdout.Write(code)
+		fatalIf(err, "write output")
+		return
+	}
+	writeFile(*outPath, code, "write output")
+	fmt.Fprintf(os.Stderr, "generated %s\n", *outPath)
+}
+
+func writeFile(path string, data []byte, msg string) {
+	fatalIf(os.MkdirAll(filepath.Dir(path), 0o750), "create output directory")
+	// #nosec G306,G703 -- This CLI intentionally writes to the explicit user-provided output path.
+	fatalIf(os.WriteFile(path, data, 0o600), msg)
+}
+
+func fatalIf(err error, msg string) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %v\n", msg, err)
+	os.Exit(1)
+}
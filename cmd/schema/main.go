@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	"github.com/juhokoskela/pipedrive-go/pipedrive/schema"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+const usage = `usage: schema [flags] plan|apply <schema.yaml>
+
+Diffs the custom fields and pipelines described in schema.yaml against the
+account behind PIPEDRIVE_API_TOKEN (PIPEDRIVE_BASE_URL_V2 overrides the API
+base URL, e.g. for a local stand-in).
+
+flags:
+`
+
+func main() {
+	var (
+		allowDeleteFields    = flag.Bool("allow-delete-fields", false, "allow apply to delete custom fields missing from the schema")
+		allowDeleteOptions   = flag.Bool("allow-delete-options", false, "allow apply to delete field options missing from the schema")
+		allowDeletePipelines = flag.Bool("allow-delete-pipelines", false, "allow apply to delete pipelines missing from the schema")
+		allowDeleteStages    = flag.Bool("allow-delete-stages", false, "allow apply to delete stages missing from the schema")
+		exitCode             = flag.Bool("exit-code", false, "plan: exit with status 2 when there are changes")
+		timeout              = flag.Duration("timeout", 5*time.Minute, "overall timeout")
+	)
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 || (flag.Arg(0) != "plan" && flag.Arg(0) != "apply") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command, specPath := flag.Arg(0), flag.Arg(1)
+
+	data, err := os.ReadFile(specPath)
+	fatalIf(err, "read schema")
+	spec, err := schema.Load(data)
+	fatalIf(err, "load schema")
+
+	token := strings.TrimSpace(os.Getenv("PIPEDRIVE_API_TOKEN"))
+	if token == "" {
+		fatalIf(fmt.Errorf("PIPEDRIVE_API_TOKEN is required"), "configure client")
+	}
+	cfg := pipedrive.Config{Auth: pipedrive.APITokenAuth(token)}
+	if baseURL := strings.TrimSpace(os.Getenv("PIPEDRIVE_BASE_URL_V2")); baseURL != "" {
+		cfg.BaseURL = baseURL
+	}
+	client, err := v2.NewClient(cfg)
+	fatalIf(err, "create client")
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var (
+		fieldPlan    *schema.Plan
+		pipelinePlan *schema.PipelinePlan
+	)
+	if len(spec.Fields) > 0 {
+		fieldPlan, err = schema.PlanFields(ctx, client, spec)
+		fatalIf(err, "plan fields")
+		fmt.Print(fieldPlan.String())
+	}
+	if len(spec.Pipelines) > 0 {
+		pipelinePlan, err = schema.PlanPipelines(ctx, client, spec)
+		fatalIf(err, "plan pipelines")
+		fmt.Print(pipelinePlan.String())
+	}
+	changed := fieldPlan.HasChanges() || pipelinePlan.HasChanges()
+
+	switch command {
+	case "plan":
+		if *exitCode && changed {
+			cancel()
+			os.Exit(2)
+		}
+	case "apply":
+		opts := schema.ApplyOptions{
+			AllowDeleteFields:    *allowDeleteFields,
+			AllowDeleteOptions:   *allowDeleteOptions,
+			AllowDeletePipelines: *allowDeletePipelines,
+			AllowDeleteStages:    *allowDeleteStages,
+		}
+		if fieldPlan.HasChanges() {
+			done, err := schema.Apply(ctx, client, fieldPlan, opts)
+			fmt.Fprintf(os.Stderr, "applied %d of %d field change(s)\n", len(done), len(fieldPlan.Actions))
+			fatalIf(err, "apply fields")
+		}
+		if pipelinePlan.HasChanges() {
+			done, err := schema.ApplyPipelines(ctx, client, pipelinePlan, opts)
+			fmt.Fprintf(os.Stderr, "applied %d of %d pipeline change(s)\n", len(done), len(pipelinePlan.Actions))
+			fatalIf(err, "apply pipelines")
+		}
+	}
+}
+
+func fatalIf(err error, msg string) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %v\n", msg, err)
+	os.Exit(1)
+}
@@ -0,0 +1,469 @@
+package fieldgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+type Entity string
+
+const (
+	EntityDeal         Entity = "deal"
+	EntityPerson       Entity = "person"
+	EntityOrganization Entity = "organization"
+	EntityProduct      Entity = "product"
+	EntityActivity     Entity = "activity"
+	EntityProject      Entity = "project"
+)
+
+var Entities = []Entity{
+	EntityDeal,
+	EntityPerson,
+	EntityOrganization,
+	EntityProduct,
+	EntityActivity,
+	EntityProject,
+}
+
+// Dump holds field definitions per entity in the form written by SaveDump,
+// so code can be regenerated without API access.
+type Dump map[Entity][]v2.Field
+
+type Options struct {
+	Package string
+}
+
+func ParseEntities(csv string) ([]Entity, error) {
+	if strings.TrimSpace(csv) == "" {
+		return Entities, nil
+	}
+	var out []Entity
+	for _, part := range strings.Split(csv, ",") {
+		entity := Entity(strings.TrimSpace(part))
+		if !isKnownEntity(entity) {
+			return nil, fmt.Errorf("unknown entity %q", part)
+		}
+		out = append(out, entity)
+	}
+	return out, nil
+}
+
+func isKnownEntity(entity Entity) bool {
+	for _, known := range Entities {
+		if known == entity {
+			return true
+		}
+	}
+	return false
+}
+
+func LoadDump(data []byte) (Dump, error) {
+	var dump Dump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return nil, fmt.Errorf("decode dump: %w", err)
+	}
+	for entity := range dump {
+		if !isKnownEntity(entity) {
+			return nil, fmt.Errorf("unknown entity %q in dump", entity)
+		}
+	}
+	return dump, nil
+}
+
+func SaveDump(dump Dump) ([]byte, error) {
+	out, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode dump: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+func Fetch(ctx context.Context, client *v2.Client, entities []Entity) (Dump, error) {
+	dump := make(Dump, len(entities))
+	for _, entity := range entities {
+		var pager *pipedrive.CursorPager[v2.Field]
+		switch entity {
+		case EntityDeal:
+			pager = client.DealFields.ListPager()
+		case EntityPerson:
+			pager = client.PersonFields.ListPager()
+		case EntityOrganization:
+			pager = client.OrganizationFields.ListPager()
+		case EntityProduct:
+			pager = client.ProductFields.ListPager()
+		case EntityActivity:
+			pager = client.ActivityFields.ListPager()
+		case EntityProject:
+			pager = client.ProjectFields.ListPager()
+		default:
+			return nil, fmt.Errorf("unknown entity %q", entity)
+		}
+		var fields []v2.Field
+		if err := pager.ForEach(ctx, func(field v2.Field) error {
+			fields = append(fields, field)
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("list %s fields: %w", entity, err)
+		}
+		dump[entity] = fields
+	}
+	return dump, nil
+}
+
+var fieldTypeConsts = map[v2.FieldType]string{
+	v2.FieldTypeInt:              "FieldTypeInt",
+	v2.FieldTypeDouble:           "FieldTypeDouble",
+	v2.FieldTypeBoolean:          "FieldTypeBoolean",
+	v2.FieldTypeVarchar:          "FieldTypeVarchar",
+	v2.FieldTypeText:             "FieldTypeText",
+	v2.FieldTypePhone:            "FieldTypePhone",
+	v2.FieldTypeVarcharOptions:   "FieldTypeVarcharOptions",
+	v2.FieldTypeVarcharAuto:      "FieldTypeVarcharAuto",
+	v2.FieldTypeDate:             "FieldTypeDate",
+	v2.FieldTypeDateRange:        "FieldTypeDateRange",
+	v2.FieldTypeTime:             "FieldTypeTime",
+	v2.FieldTypeTimeRange:        "FieldTypeTimeRange",
+	v2.FieldTypeEnum:             "FieldTypeEnum",
+	v2.FieldTypeSet:              "FieldTypeSet",
+	v2.FieldTypeAddress:          "FieldTypeAddress",
+	v2.FieldTypeMonetary:         "FieldTypeMonetary",
+	v2.FieldTypeDeal:             "FieldTypeDeal",
+	v2.FieldTypeDeals:            "FieldTypeDeals",
+	v2.FieldTypeLead:             "FieldTypeLead",
+	v2.FieldTypeOrg:              "FieldTypeOrg",
+	v2.FieldTypePeople:           "FieldTypePeople",
+	v2.FieldTypeProject:          "FieldTypeProject",
+	v2.FieldTypeStage:            "FieldTypeStage",
+	v2.FieldTypeUser:             "FieldTypeUser",
+	v2.FieldTypeActivity:         "FieldTypeActivity",
+	v2.FieldTypeJSON:             "FieldTypeJSON",
+	v2.FieldTypePicture:          "FieldTypePicture",
+	v2.FieldTypeStatus:           "FieldTypeStatus",
+	v2.FieldTypeVisibleTo:        "FieldTypeVisibleTo",
+	v2.FieldTypePriceList:        "FieldTypePriceList",
+	v2.FieldTypeBillingFrequency: "FieldTypeBillingFrequency",
+	v2.FieldTypeProjectsBoard:    "FieldTypeProjectsBoard",
+	v2.FieldTypeProjectsPhase:    "FieldTypeProjectsPhase",
+}
+
+// goValueTypes are the Go types CustomFieldResolver decodes each field type
+// into; enum and set fields get generated option types instead.
+var goValueTypes = map[v2.FieldType]string{
+	v2.FieldTypeInt:            "int64",
+	v2.FieldTypeDouble:         "float64",
+	v2.FieldTypeBoolean:        "bool",
+	v2.FieldTypeVarchar:        "string",
+	v2.FieldTypeText:           "string",
+	v2.FieldTypePhone:          "string",
+	v2.FieldTypeVarcharOptions: "string",
+	v2.FieldTypeVarcharAuto:    "string",
+	v2.FieldTypeTime:           "string",
+	v2.FieldTypeDate:           "time.Time",
+	v2.FieldTypeMonetary:       "v2.MonetaryValue",
+	v2.FieldTypeDateRange:      "v2.RangeValue",
+	v2.FieldTypeTimeRange:      "v2.RangeValue",
+	v2.FieldTypeAddress:        "v2.OrganizationAddress",
+	v2.FieldTypeUser:           "v2.UserID",
+	v2.FieldTypeOrg:            "v2.OrganizationID",
+	v2.FieldTypePeople:         "v2.PersonID",
+	v2.FieldTypeDeal:           "v2.DealID",
+	v2.FieldTypeLead:           "v2.LeadID",
+	v2.FieldTypeProject:        "v2.ProjectID",
+	v2.FieldTypeStage:          "v2.StageID",
+	v2.FieldTypeActivity:       "v2.ActivityID",
+}
+
+type generatedField struct {
+	field      v2.Field
+	keyConst   string
+	structName string
+	accessor   string
+	setter     string
+	optionType string
+	options    []generatedOption
+	valueType  string
+}
+
+type generatedOption struct {
+	name  string
+	id    int
+	label string
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	names   map[string]struct{}
+	useTime bool
+}
+
+// Generate renders a Go source file with key constants, option types, a
+// struct and accessor functions for the custom fields in dump.
+func Generate(dump Dump, opts Options) ([]byte, error) {
+	pkg := opts.Package
+	if pkg == "" {
+		pkg = "customfields"
+	}
+	if !isIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+
+	if len(dump) == 0 {
+		return nil, fmt.Errorf("no field definitions to generate from")
+	}
+
+	g := &generator{names: make(map[string]struct{})}
+	// Reserve the per-entity declarations so field and option names that
+	// happen to spell them get a suffix instead.
+	for _, entity := range Entities {
+		if _, ok := dump[entity]; ok {
+			structType := exportedName(string(entity)) + "CustomFields"
+			g.unique(structType)
+			g.unique("Decode" + structType)
+		}
+	}
+	var body bytes.Buffer
+	for _, entity := range Entities {
+		fields, ok := dump[entity]
+		if !ok {
+			continue
+		}
+		g.buf.Reset()
+		g.entity(entity, fields)
+		body.Write(g.buf.Bytes())
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by fieldgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	out.WriteString("import (\n")
+	if g.useTime {
+		out.WriteString("\t\"time\"\n\n")
+	}
+	out.WriteString("\tv2 \"github.com/juhokoskela/pipedrive-go/pipedrive/v2\"\n)\n")
+	out.Write(body.Bytes())
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+func (g *generator) entity(entity Entity, fields []v2.Field) {
+	prefix := exportedName(string(entity))
+	var custom []v2.Field
+	for _, field := range fields {
+		if field.IsCustomField && field.FieldCode != "" {
+			custom = append(custom, field)
+		}
+	}
+	sort.SliceStable(custom, func(i, j int) bool {
+		if custom[i].FieldName != custom[j].FieldName {
+			return custom[i].FieldName < custom[j].FieldName
+		}
+		return custom[i].FieldCode < custom[j].FieldCode
+	})
+
+	// Struct fields share a namespace with the generated Map method.
+	structNames := map[string]struct{}{"Map": {}}
+	generated := make([]generatedField, 0, len(custom))
+	for _, field := range custom {
+		ident := exportedName(field.FieldName)
+		gf := generatedField{
+			field:      field,
+			keyConst:   g.unique(prefix + "Field" + ident),
+			structName: uniqueIn(structNames, structFieldName(field.FieldName)),
+			accessor:   g.unique(prefix + ident),
+		}
+		gf.setter = g.unique("Set" + gf.accessor)
+		gf.valueType = goValueTypes[field.FieldType]
+		if field.FieldType == v2.FieldTypeEnum || field.FieldType == v2.FieldTypeSet {
+			gf.optionType = g.unique(gf.accessor + "Option")
+			for _, option := range field.Options {
+				if option.ID == 0 {
+					continue
+				}
+				gf.options = append(gf.options, generatedOption{
+					name:  g.unique(gf.accessor + exportedName(option.Label)),
+					id:    option.ID,
+					label: option.Label,
+				})
+			}
+			gf.valueType = gf.optionType
+			if field.FieldType == v2.FieldTypeSet {
+				gf.valueType = "[]" + gf.optionType
+			}
+		}
+		if gf.valueType == "" {
+			gf.valueType = "interface{}"
+		}
+		if gf.valueType == "time.Time" {
+			g.useTime = true
+		}
+		generated = append(generated, gf)
+	}
+
+	lower := string(entity)
+	resolverVar := lower + "CustomFields"
+	structType := prefix + "CustomFields"
+
+	fmt.Fprintf(&g.buf, "\n// %s custom field keys.\nconst (\n", prefix)
+	for _, gf := range generated {
+		fmt.Fprintf(&g.buf, "\t%s = %q\n", gf.keyConst, gf.field.FieldCode)
+	}
+	g.buf.WriteString(")\n")
+
+	for _, gf := range generated {
+		if gf.optionType == "" {
+			continue
+		}
+		fmt.Fprintf(&g.buf, "\ntype %s int\n\n", gf.optionType)
+		if len(gf.options) > 0 {
+			g.buf.WriteString("const (\n")
+			for _, option := range gf.options {
+				fmt.Fprintf(&g.buf, "\t%s %s = %d\n", option.name, gf.optionType, option.id)
+			}
+			g.buf.WriteString(")\n")
+		}
+		fmt.Fprintf(&g.buf, "\nfunc (o %s) Label() string {\n\tswitch o {\n", gf.optionType)
+		for _, option := range gf.options {
+			fmt.Fprintf(&g.buf, "\tcase %s:\n\t\treturn %q\n", option.name, option.label)
+		}
+		g.buf.WriteString("\t}\n\treturn \"\"\n}\n")
+	}
+
+	fmt.Fprintf(&g.buf, "\nvar %s = v2.NewCustomFieldResolver([]v2.Field{\n", resolverVar)
+	for _, gf := range generated {
+		fmt.Fprintf(&g.buf, "\t{FieldCode: %q, FieldName: %q, FieldType: %s, IsCustomField: true", gf.field.FieldCode, gf.field.FieldName, fieldTypeExpr(gf.field.FieldType))
+		if len(gf.field.Options) > 0 {
+			g.buf.WriteString(", Options: []v2.FieldOption{")
+			for i, option := range gf.field.Options {
+				if i > 0 {
+					g.buf.WriteString(", ")
+				}
+				if option.ID != 0 {
+					fmt.Fprintf(&g.buf, "{ID: %d, Label: %q}", option.ID, option.Label)
+				} else {
+					fmt.Fprintf(&g.buf, "{StringID: %q, Label: %q}", option.StringID, option.Label)
+				}
+			}
+			g.buf.WriteString("}")
+		}
+		g.buf.WriteString("},\n")
+	}
+	g.buf.WriteString("})\n")
+
+	fmt.Fprintf(&g.buf, "\n// %s holds the %s custom fields; nil and empty values are left out of Map.\n", structType, lower)
+	fmt.Fprintf(&g.buf, "type %s struct {\n", structType)
+	for _, gf := range generated {
+		fieldType := gf.valueType
+		if !strings.HasPrefix(fieldType, "[]") && fieldType != "interface{}" {
+			fieldType = "*" + fieldType
+		}
+		fmt.Fprintf(&g.buf, "\t%s %s `pipedrive:%s`\n", gf.structName, fieldType, strconv.Quote(gf.field.FieldCode+",omitempty"))
+	}
+	g.buf.WriteString("}\n")
+
+	fmt.Fprintf(&g.buf, `
+func Decode%[1]s(customFields map[string]interface{}) (%[1]s, error) {
+	var out %[1]s
+	err := %[2]s.UnmarshalCustomFields(customFields, &out)
+	return out, err
+}
+
+func (f %[1]s) Map() (map[string]interface{}, error) {
+	return %[2]s.MarshalCustomFields(f)
+}
+`, structType, resolverVar)
+
+	for _, gf := range generated {
+		fmt.Fprintf(&g.buf, `
+func %[1]s(customFields map[string]interface{}) (%[3]s, bool, error) {
+	var v %[3]s
+	ok, err := %[4]s.GetCustomField(customFields, %[5]s, &v)
+	return v, ok, err
+}
+
+func %[2]s(customFields map[string]interface{}, v %[3]s) error {
+	return %[4]s.SetCustomField(customFields, %[5]s, v)
+}
+`, gf.accessor, gf.setter, gf.valueType, resolverVar, gf.keyConst)
+	}
+}
+
+func (g *generator) unique(name string) string {
+	return uniqueIn(g.names, name)
+}
+
+func uniqueIn(names map[string]struct{}, name string) string {
+	candidate := name
+	for i := 2; ; i++ {
+		if _, taken := names[candidate]; !taken {
+			names[candidate] = struct{}{}
+			return candidate
+		}
+		candidate = name + strconv.Itoa(i)
+	}
+}
+
+func fieldTypeExpr(fieldType v2.FieldType) string {
+	if name, ok := fieldTypeConsts[fieldType]; ok {
+		return "v2." + name
+	}
+	return fmt.Sprintf("v2.FieldType(%q)", fieldType)
+}
+
+// exportedName turns a display name such as "ARR band" or "e-mail (work)"
+// into a Go identifier fragment like "ARRBand" or "EMailWork". Callers put a
+// prefix in front, so a leading digit is fine.
+func exportedName(s string) string {
+	var b strings.Builder
+	upperNext := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "Field"
+	}
+	return b.String()
+}
+
+// structFieldName is exportedName for identifiers that stand on their own and
+// so must start with an upper-case letter.
+func structFieldName(s string) string {
+	name := exportedName(s)
+	if first := []rune(name)[0]; !unicode.IsUpper(first) {
+		name = "X" + name
+	}
+	return name
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,162 @@
+package fieldgen
+
+import (
+	"context"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+const testDump = `{
+	"deal": [
+		{"field_code":"title","field_name":"Title","field_type":"varchar","is_custom_field":false},
+		{"field_code":"h_region","field_name":"Region","field_type":"varchar","is_custom_field":true},
+		{"field_code":"h_arr","field_name":"ARR band","field_type":"enum","is_custom_field":true,"options":[{"id":1,"label":"Small"},{"id":2,"label":"Large"}]},
+		{"field_code":"h_launch","field_name":"Launch date","field_type":"date","is_custom_field":true}
+	],
+	"person": [
+		{"field_code":"p_owner","field_name":"Account manager","field_type":"user","is_custom_field":true}
+	]
+}`
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	dump, err := LoadDump([]byte(testDump))
+	if err != nil {
+		t.Fatalf("LoadDump error: %v", err)
+	}
+	code, err := Generate(dump, Options{Package: "pdfields"})
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	typeCheck(t, code)
+
+	src := string(code)
+	for _, want := range []string{
+		"package pdfields",
+		`DealFieldRegion     = "h_region"`,
+		"type DealARRBandOption int",
+		"DealARRBandLarge DealARRBandOption = 2",
+		"ARRBand    *DealARRBandOption `pipedrive:\"h_arr,omitempty\"`",
+		"LaunchDate *time.Time",
+		"func DecodeDealCustomFields(customFields map[string]interface{}) (DealCustomFields, error)",
+		"func SetDealRegion(customFields map[string]interface{}, v string) error",
+		"func PersonAccountManager(customFields map[string]interface{}) (v2.UserID, bool, error)",
+	} {
+		if !strings.Contains(src, want) {
+			t.Fatalf("generated code missing %q:\n%s", want, src)
+		}
+	}
+	if strings.Contains(src, `"title"`) {
+		t.Fatalf("generated code includes built-in field:\n%s", src)
+	}
+}
+
+func TestGenerate_NameCollisions(t *testing.T) {
+	t.Parallel()
+
+	code, err := Generate(Dump{EntityDeal: {
+		{FieldCode: "a", FieldName: "Stage note", FieldType: v2.FieldTypeText, IsCustomField: true},
+		{FieldCode: "b", FieldName: "stage-note", FieldType: v2.FieldTypeText, IsCustomField: true},
+	}}, Options{})
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	src := string(code)
+	if !strings.Contains(src, "func DealStageNote(") || !strings.Contains(src, "func DealStageNote2(") {
+		t.Fatalf("expected de-duplicated accessors:\n%s", src)
+	}
+}
+
+func TestGenerate_ReservedNames(t *testing.T) {
+	t.Parallel()
+
+	code, err := Generate(Dump{
+		EntityDeal: {
+			{FieldCode: "a", FieldName: "Map", FieldType: v2.FieldTypeText, IsCustomField: true},
+			{FieldCode: "b", FieldName: "Custom fields", FieldType: v2.FieldTypeText, IsCustomField: true},
+		},
+		EntityPerson: {
+			{FieldCode: "c", FieldName: "Deal custom fields", FieldType: v2.FieldTypeEnum, IsCustomField: true, Options: []v2.FieldOption{{ID: 1, Label: "Map"}}},
+		},
+	}, Options{})
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	typeCheck(t, code)
+	src := string(code)
+	for _, want := range []string{"\tMap2 ", "func DealCustomFields2(", "func DecodeDealCustomFields("} {
+		if !strings.Contains(src, want) {
+			t.Fatalf("generated code missing %q:\n%s", want, src)
+		}
+	}
+}
+
+// typeCheck fails the test unless code compiles against this module.
+func typeCheck(t *testing.T, code []byte) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "gen.go", code, parser.AllErrors)
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check(file.Name.Name, fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("generated code does not type-check: %v\n%s", err, code)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/dealFields":
+			_, _ = w.Write([]byte(`{"data":[{"field_code":"h_region","field_name":"Region","field_type":"varchar","is_custom_field":true}],"additional_data":{"next_cursor":null}}`))
+		case "/projectFields":
+			_, _ = w.Write([]byte(`{"data":[],"additional_data":{"next_cursor":null}}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := v2.NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	dump, err := Fetch(context.Background(), client, []Entity{EntityDeal, EntityProject})
+	if err != nil {
+		t.Fatalf("Fetch error: %v", err)
+	}
+	if len(dump[EntityDeal]) != 1 || dump[EntityDeal][0].FieldCode != "h_region" {
+		t.Fatalf("unexpected deal fields: %#v", dump[EntityDeal])
+	}
+	if _, ok := dump[EntityProject]; !ok {
+		t.Fatalf("expected project entry in dump")
+	}
+}
+
+func TestParseEntities(t *testing.T) {
+	t.Parallel()
+
+	entities, err := ParseEntities("deal, person")
+	if err != nil || len(entities) != 2 || entities[1] != EntityPerson {
+		t.Fatalf("unexpected entities: %v, %v", entities, err)
+	}
+	if _, err := ParseEntities("lead"); err == nil {
+		t.Fatalf("expected unknown entity error")
+	}
+}
@@ -0,0 +1,206 @@
+package pipedrive
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout      = "2006-01-02"
+	timeOfDayLayout = "15:04:05"
+)
+
+// FormatError reports a date or time value that does not match the layout
+// Pipedrive expects.
+type FormatError struct {
+	Kind   string
+	Value  string
+	Layout string
+}
+
+func (e *FormatError) Error() string {
+	if e == nil {
+		return "invalid date or time format"
+	}
+	return fmt.Sprintf("invalid %s %q: expected %s", e.Kind, e.Value, e.Layout)
+}
+
+// Date is a calendar date without a time zone, sent as YYYY-MM-DD. The zero
+// value means no date and encodes as JSON null.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, strings.TrimSpace(s))
+	if err != nil {
+		return Date{}, &FormatError{Kind: "date", Value: s, Layout: "YYYY-MM-DD"}
+	}
+	return DateOf(t), nil
+}
+
+// DateOf returns the date of t in t's location.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// IsValid reports whether d names an existing calendar date.
+func (d Date) IsValid() bool {
+	return d.Year > 0 && d.Year <= 9999 && DateOf(d.In(time.UTC)) == d
+}
+
+// In returns midnight at the start of d in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// At returns d at the time of day t in loc.
+func (d Date) At(t TimeOfDay, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, t.Hour, t.Minute, t.Second, 0, loc)
+}
+
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.In(time.UTC).AddDate(0, 0, n))
+}
+
+func (d Date) Compare(o Date) int {
+	return d.In(time.UTC).Compare(o.In(time.UTC))
+}
+
+func (d Date) Before(o Date) bool {
+	return d.Compare(o) < 0
+}
+
+func (d Date) After(o Date) bool {
+	return d.Compare(o) > 0
+}
+
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, int(d.Month), d.Day)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	if !d.IsValid() {
+		return nil, &FormatError{Kind: "date", Value: d.String(), Layout: "YYYY-MM-DD"}
+	}
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON accepts a YYYY-MM-DD string. null and the empty string decode
+// to the zero Date.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*d = Date{}
+		return nil
+	}
+	unquoted, err := strconv.Unquote(s)
+	if err != nil {
+		return &FormatError{Kind: "date", Value: s, Layout: "YYYY-MM-DD"}
+	}
+	return d.UnmarshalText([]byte(unquoted))
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	if !d.IsValid() {
+		return nil, &FormatError{Kind: "date", Value: d.String(), Layout: "YYYY-MM-DD"}
+	}
+	return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = Date{}
+		return nil
+	}
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// TimeOfDay is a wall clock time without a date or time zone, sent as
+// HH:MM:SS.
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+	Second int
+}
+
+// ParseTimeOfDay accepts HH:MM:SS and the shorter HH:MM.
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	trimmed := strings.TrimSpace(s)
+	for _, layout := range []string{timeOfDayLayout, "15:04"} {
+		if t, err := time.Parse(layout, trimmed); err == nil {
+			return TimeOfDayOf(t), nil
+		}
+	}
+	return TimeOfDay{}, &FormatError{Kind: "time", Value: s, Layout: "HH:MM:SS"}
+}
+
+// TimeOfDayOf returns the wall clock time of t in t's location.
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	hour, minute, second := t.Clock()
+	return TimeOfDay{Hour: hour, Minute: minute, Second: second}
+}
+
+func (t TimeOfDay) IsValid() bool {
+	return t.Hour >= 0 && t.Hour < 24 && t.Minute >= 0 && t.Minute < 60 && t.Second >= 0 && t.Second < 60
+}
+
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+}
+
+func (t TimeOfDay) MarshalJSON() ([]byte, error) {
+	text, err := t.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strconv.Quote(string(text))), nil
+}
+
+func (t *TimeOfDay) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	unquoted, err := strconv.Unquote(s)
+	if err != nil {
+		return &FormatError{Kind: "time", Value: s, Layout: "HH:MM:SS"}
+	}
+	return t.UnmarshalText([]byte(unquoted))
+}
+
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	if !t.IsValid() {
+		return nil, &FormatError{Kind: "time", Value: t.String(), Layout: "HH:MM:SS"}
+	}
+	return []byte(t.String()), nil
+}
+
+func (t *TimeOfDay) UnmarshalText(text []byte) error {
+	parsed, err := ParseTimeOfDay(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
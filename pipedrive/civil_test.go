@@ -0,0 +1,81 @@
+package pipedrive
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	d, err := ParseDate("2024-02-29")
+	if err != nil || d != (Date{Year: 2024, Month: time.February, Day: 29}) {
+		t.Fatalf("ParseDate = %v, %v", d, err)
+	}
+	if got := d.AddDays(1).String(); got != "2024-03-01" {
+		t.Fatalf("AddDays = %s", got)
+	}
+	for _, in := range []string{"", "2023-02-29", "2024-1-5", "10/06/2024", "2024-06-10T00:00:00Z"} {
+		var formatErr *FormatError
+		if _, err := ParseDate(in); !errors.As(err, &formatErr) || formatErr.Kind != "date" {
+			t.Fatalf("ParseDate(%q): expected *FormatError, got %v", in, err)
+		}
+	}
+	if (Date{Year: 2024, Month: 13, Day: 1}).IsValid() {
+		t.Fatalf("expected month 13 to be invalid")
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	t.Parallel()
+
+	var v struct {
+		Due  Date  `json:"due"`
+		Open Date  `json:"open"`
+		Ptr  *Date `json:"ptr,omitempty"`
+	}
+	if err := json.Unmarshal([]byte(`{"due":"2024-06-10","open":null}`), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	got, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if string(got) != `{"due":"2024-06-10","open":null}` {
+		t.Fatalf("unexpected JSON: %s", got)
+	}
+	if err := json.Unmarshal([]byte(`{"due":"2024-06-31"}`), &v); err == nil {
+		t.Fatalf("expected error for invalid date")
+	}
+	if _, err := json.Marshal(Date{Year: 2024, Month: 2, Day: 30}); err == nil {
+		t.Fatalf("expected error when marshaling invalid date")
+	}
+}
+
+func TestTimeOfDay(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{"09:30": "09:30:00", "15:00:00": "15:00:00", " 23:59:59 ": "23:59:59"} {
+		got, err := ParseTimeOfDay(in)
+		if err != nil || got.String() != want {
+			t.Fatalf("ParseTimeOfDay(%q) = %v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "24:00", "12:60:00", "noon"} {
+		if _, err := ParseTimeOfDay(in); err == nil {
+			t.Fatalf("ParseTimeOfDay(%q): expected error", in)
+		}
+	}
+
+	helsinki := time.FixedZone("EET", 2*60*60)
+	d := Date{Year: 2024, Month: time.January, Day: 5}
+	at := d.At(TimeOfDay{Hour: 9, Minute: 15}, helsinki)
+	if !at.Equal(time.Date(2024, 1, 5, 7, 15, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected At: %s", at)
+	}
+	if _, err := json.Marshal(TimeOfDay{Hour: 25}); err == nil {
+		t.Fatalf("expected error when marshaling invalid time")
+	}
+}
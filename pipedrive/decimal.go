@@ -0,0 +1,247 @@
+package pipedrive
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalPlaces bounds String for values such as 1/3 that have no finite
+// decimal expansion.
+const maxDecimalPlaces = 20
+
+// Decimal is an exact decimal number. The zero value is 0. Arithmetic is exact;
+// division keeps the exact quotient until the value is rounded or formatted.
+type Decimal struct {
+	r *big.Rat
+}
+
+// maxDecimalExponent bounds the exponent ParseDecimal accepts, so untrusted
+// input such as "1e500000" cannot expand into a huge number. It covers every
+// float64.
+const maxDecimalExponent = 400
+
+// ParseDecimal parses a plain or exponent notation decimal such as "12.50" or
+// "1e-3". Exponents beyond ±400 are rejected.
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if !validDecimal(s) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal{r: r}, nil
+}
+
+// validDecimal reports whether s is an optionally signed decimal with an
+// optional fraction and an optional exponent within maxDecimalExponent.
+func validDecimal(s string) bool {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(s), "e")
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	if whole == "" && fraction == "" || !allDigits(whole) || !allDigits(fraction) {
+		return false
+	}
+	if !hasExponent {
+		return true
+	}
+	if exponent != "" && (exponent[0] == '+' || exponent[0] == '-') {
+		exponent = exponent[1:]
+	}
+	if exponent == "" || !allDigits(exponent) {
+		return false
+	}
+	exponent = strings.TrimLeft(exponent, "0")
+	if len(exponent) > 3 {
+		return false
+	}
+	n, _ := strconv.Atoi(exponent)
+	return n <= maxDecimalExponent
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MustParseDecimal is ParseDecimal for constants; it panics on invalid input.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// DecimalFromFloat converts f through its shortest decimal representation, so
+// 0.1 becomes exactly 0.1 rather than the nearest binary fraction.
+func DecimalFromFloat(f float64) Decimal {
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+	if err != nil {
+		// NaN and infinities have no decimal value.
+		return Decimal{}
+	}
+	return d
+}
+
+func DecimalFromInt(i int64) Decimal {
+	return Decimal{r: new(big.Rat).SetInt64(i)}
+}
+
+func (d Decimal) rat() *big.Rat {
+	if d.r == nil {
+		return new(big.Rat)
+	}
+	return d.r
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Add(d.rat(), o.rat())}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Sub(d.rat(), o.rat())}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Mul(d.rat(), o.rat())}
+}
+
+// Quo returns d / o. It panics when o is zero, like integer division.
+func (d Decimal) Quo(o Decimal) Decimal {
+	return Decimal{r: new(big.Rat).Quo(d.rat(), o.rat())}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{r: new(big.Rat).Neg(d.rat())}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{r: new(big.Rat).Abs(d.rat())}
+}
+
+func (d Decimal) Cmp(o Decimal) int {
+	return d.rat().Cmp(o.rat())
+}
+
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) Sign() int {
+	return d.rat().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Round rounds to places decimal places, with halves rounded away from zero.
+func (d Decimal) Round(places int) Decimal {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	scaled := new(big.Rat).Mul(d.rat(), new(big.Rat).SetInt(scale))
+	num, den := scaled.Num(), scaled.Denom()
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	// Compare twice the remainder with the denominator to detect halves.
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Decimal{r: new(big.Rat).SetFrac(q, scale)}
+}
+
+// Float64 returns the nearest float64 and whether it is exact.
+func (d Decimal) Float64() (float64, bool) {
+	return d.rat().Float64()
+}
+
+// StringFixed formats d with exactly places decimal places after rounding.
+func (d Decimal) StringFixed(places int) string {
+	return d.Round(places).rat().FloatString(places)
+}
+
+// String formats d without trailing zeros. Values without a finite decimal
+// expansion are rounded to 20 places.
+func (d Decimal) String() string {
+	r := d.rat()
+	places := maxDecimalPlaces
+	// A fraction terminates when its reduced denominator has no prime
+	// factors other than 2 and 5.
+	den := new(big.Int).Set(r.Denom())
+	twos, fives := 0, 0
+	for _, p := range []struct {
+		factor int64
+		count  *int
+	}{{2, &twos}, {5, &fives}} {
+		f := big.NewInt(p.factor)
+		m := new(big.Int)
+		for {
+			q, rem := new(big.Int).QuoRem(den, f, m)
+			if rem.Sign() != 0 {
+				break
+			}
+			den = q
+			*p.count++
+		}
+	}
+	if den.Cmp(big.NewInt(1)) == 0 {
+		places = max(twos, fives)
+	}
+	s := d.Round(places).rat().FloatString(places)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// MarshalJSON encodes d as a JSON number with every digit String keeps, rather
+// than the nearest float64.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string. null leaves d
+// unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,48 @@
+package pipedrive
+
+import "testing"
+
+func TestDecimal(t *testing.T) {
+	t.Parallel()
+
+	sum := DecimalFromFloat(0.1).Add(DecimalFromFloat(0.2))
+	if sum.String() != "0.3" || !sum.Equal(MustParseDecimal("0.30")) {
+		t.Fatalf("expected exact 0.3, got %s", sum)
+	}
+	big := MustParseDecimal("12345678901234567.89").Mul(DecimalFromInt(3))
+	if big.String() != "37037036703703703.67" {
+		t.Fatalf("unexpected product: %s", big)
+	}
+	third := DecimalFromInt(1).Quo(DecimalFromInt(3))
+	if third.String() != "0.33333333333333333333" || third.StringFixed(2) != "0.33" {
+		t.Fatalf("unexpected third: %s", third)
+	}
+	if got := MustParseDecimal("1e-3").String(); got != "0.001" {
+		t.Fatalf("unexpected exponent parse: %s", got)
+	}
+	var zero Decimal
+	if !zero.IsZero() || zero.String() != "0" || zero.Add(DecimalFromInt(2)).String() != "2" {
+		t.Fatalf("unexpected zero value behaviour")
+	}
+
+	rounding := map[string]string{"2.345": "2.35", "-2.345": "-2.35", "2.344": "2.34", "-0.001": "0", "0.005": "0.01"}
+	for in, want := range rounding {
+		if got := MustParseDecimal(in).Round(2).String(); got != want {
+			t.Fatalf("Round(%s) = %s, want %s", in, got, want)
+		}
+	}
+
+	for in, want := range map[string]string{"-.5": "-0.5", "+5.": "5", "2E+2": "200"} {
+		if d, err := ParseDecimal(in); err != nil || d.String() != want {
+			t.Fatalf("ParseDecimal(%q) = %s, %v; want %s", in, d, err, want)
+		}
+	}
+	if _, err := ParseDecimal("1e-0400"); err != nil {
+		t.Fatalf("expected exponent at the limit to parse: %v", err)
+	}
+	for _, in := range []string{"", "abc", "1/3", "1_000", "0x10", "0b1", "1e500000", "1e401", "1e", "e5", ".", "-", "1.2.3", "1e+-2", "Inf", "NaN"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Fatalf("ParseDecimal(%q): expected error", in)
+		}
+	}
+}
@@ -0,0 +1,606 @@
+package dedupe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+type Kind string
+
+const (
+	KindPerson       Kind = "person"
+	KindOrganization Kind = "organization"
+)
+
+type Reason string
+
+const (
+	ReasonEmail        Reason = "email"
+	ReasonPhone        Reason = "phone"
+	ReasonName         Reason = "name"
+	ReasonOrganization Reason = "organization"
+	ReasonAddress      Reason = "address"
+	ReasonWebsite      Reason = "website"
+)
+
+// Weights is the evidence each shared attribute contributes to a pair score.
+// Scores are combined as independent signals: 1 - Π(1 - weight).
+type Weights map[Reason]float64
+
+// DefaultPersonWeights treats a shared email as near-certain and a shared name
+// as weak evidence that needs corroboration. Organization only counts for
+// persons whose names also match.
+var DefaultPersonWeights = Weights{
+	ReasonEmail:        0.95,
+	ReasonPhone:        0.85,
+	ReasonName:         0.5,
+	ReasonOrganization: 0.3,
+}
+
+// DefaultOrganizationWeights is enough to cluster organizations on a name
+// match alone, since legal form words are already ignored.
+var DefaultOrganizationWeights = Weights{
+	ReasonName:    0.7,
+	ReasonAddress: 0.5,
+	ReasonWebsite: 0.9,
+}
+
+const defaultThreshold = 0.6
+
+// Record is the normalized view of a person or organization the engine
+// compares. Entity holds the v2 value the record was built from.
+type Record struct {
+	Kind    Kind
+	ID      int64
+	Name    string
+	Emails  []string
+	Phones  []string
+	OrgID   int64
+	Address string
+	Website string
+	AddTime time.Time
+	// Completeness counts the populated contact attributes and is used by
+	// MostComplete to pick a survivor.
+	Completeness int
+	Entity       interface{}
+}
+
+// PersonRecord normalizes emails and phones with v2.NormalizeEmail and
+// v2.NormalizePhone, so records compare the way contacts are stored. National
+// phone numbers are read as numbers of phoneRegion; values that do not
+// normalize are left out.
+func PersonRecord(p v2.Person, phoneRegion string) Record {
+	r := Record{Kind: KindPerson, ID: int64(p.ID), Name: NormalizeName(p.Name), Entity: p}
+	for _, email := range p.Emails {
+		if v, err := v2.NormalizeEmail(email.Value); err == nil {
+			r.Emails = append(r.Emails, v)
+		}
+	}
+	for _, phone := range p.Phones {
+		if v, err := v2.NormalizePhone(phone.Value, phoneRegion); err == nil {
+			r.Phones = append(r.Phones, v)
+		}
+	}
+	if p.OrgID != nil {
+		r.OrgID = int64(*p.OrgID)
+	}
+	if p.AddTime != nil {
+		r.AddTime = *p.AddTime
+	}
+	r.Emails, r.Phones = uniqueStrings(r.Emails), uniqueStrings(r.Phones)
+	r.Completeness = len(r.Emails) + len(r.Phones)
+	for _, ok := range []bool{r.Name != "", r.OrgID != 0, p.PostalAddress != nil, p.JobTitle != ""} {
+		if ok {
+			r.Completeness++
+		}
+	}
+	return r
+}
+
+func OrganizationRecord(o v2.Organization) Record {
+	r := Record{Kind: KindOrganization, ID: int64(o.ID), Name: NormalizeOrganizationName(o.Name), Entity: o}
+	if o.Address != nil {
+		r.Address = NormalizeName(o.Address.Value)
+	}
+	if o.Website != nil {
+		r.Website = NormalizeWebsite(*o.Website)
+	}
+	if o.AddTime != nil {
+		r.AddTime = *o.AddTime
+	}
+	for _, ok := range []bool{r.Name != "", r.Address != "", r.Website != "", o.LinkedIn != nil, o.Industry != nil} {
+		if ok {
+			r.Completeness++
+		}
+	}
+	return r
+}
+
+// NormalizeName lower-cases a name, drops punctuation and sorts its words so
+// "Doe, Jane" and "jane  doe" compare equal.
+func NormalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+var legalSuffixes = map[string]bool{
+	"ab": true, "ag": true, "as": true, "bv": true, "co": true, "company": true, "corp": true,
+	"corporation": true, "gmbh": true, "inc": true, "incorporated": true, "llc": true,
+	"limited": true, "ltd": true, "oy": true, "oyj": true, "plc": true, "sa": true, "sarl": true,
+}
+
+// NormalizeOrganizationName is NormalizeName with legal form words such as
+// "Inc" or "GmbH" removed.
+func NormalizeOrganizationName(name string) string {
+	words := strings.Fields(NormalizeName(name))
+	kept := words[:0]
+	for _, word := range words {
+		if !legalSuffixes[word] {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(kept, " ")
+}
+
+// NormalizeWebsite returns the lower-cased host of a URL without a "www."
+// prefix.
+func NormalizeWebsite(website string) string {
+	website = strings.TrimSpace(strings.ToLower(website))
+	if website == "" {
+		return ""
+	}
+	if !strings.Contains(website, "://") {
+		website = "http://" + website
+	}
+	u, err := url.Parse(website)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+type Option func(*Engine)
+
+// WithThreshold sets the minimum pair score for two records to be clustered.
+func WithThreshold(threshold float64) Option {
+	return func(e *Engine) {
+		e.threshold = threshold
+	}
+}
+
+// WithWeights replaces the weights used to score records of kind.
+func WithWeights(kind Kind, weights Weights) Option {
+	return func(e *Engine) {
+		e.weights[kind] = weights
+	}
+}
+
+// WithSurvivor sets how the record that others are merged into is chosen.
+func WithSurvivor(fn SurvivorFunc) Option {
+	return func(e *Engine) {
+		if fn != nil {
+			e.survivor = fn
+		}
+	}
+}
+
+// WithPhoneRegion sets the region, such as "FI", of national phone numbers
+// read by ScanPersons. Without it only international numbers are compared.
+func WithPhoneRegion(region string) Option {
+	return func(e *Engine) {
+		e.phoneRegion = region
+	}
+}
+
+// WithDryRun makes Merge report the planned merges without calling the API.
+func WithDryRun(enabled bool) Option {
+	return func(e *Engine) {
+		e.dryRun = enabled
+	}
+}
+
+// SurvivorFunc returns the ID of the cluster record to keep.
+type SurvivorFunc func(records []Record) int64
+
+// Oldest keeps the earliest created record, falling back to the lowest ID.
+func Oldest(records []Record) int64 {
+	best := records[0]
+	for _, r := range records[1:] {
+		if older(r, best) {
+			best = r
+		}
+	}
+	return best.ID
+}
+
+// MostComplete keeps the record with the most populated attributes, breaking
+// ties with Oldest.
+func MostComplete(records []Record) int64 {
+	best := records[0]
+	for _, r := range records[1:] {
+		if r.Completeness > best.Completeness || (r.Completeness == best.Completeness && older(r, best)) {
+			best = r
+		}
+	}
+	return best.ID
+}
+
+func older(a, b Record) bool {
+	switch {
+	case a.AddTime.IsZero() != b.AddTime.IsZero():
+		return !a.AddTime.IsZero()
+	case !a.AddTime.Equal(b.AddTime):
+		return a.AddTime.Before(b.AddTime)
+	default:
+		return a.ID < b.ID
+	}
+}
+
+type Engine struct {
+	threshold   float64
+	weights     map[Kind]Weights
+	survivor    SurvivorFunc
+	dryRun      bool
+	phoneRegion string
+}
+
+func New(opts ...Option) *Engine {
+	e := &Engine{
+		threshold: defaultThreshold,
+		weights:   map[Kind]Weights{KindPerson: DefaultPersonWeights, KindOrganization: DefaultOrganizationWeights},
+		survivor:  Oldest,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	return e
+}
+
+// Pair is a scored link between two records of a cluster.
+type Pair struct {
+	A, B    int64
+	Score   float64
+	Reasons []Reason
+}
+
+// Cluster groups records that are likely the same person or organization.
+// Score is the weakest pair score that holds the cluster together. SurvivorID
+// may be changed before calling Merge.
+type Cluster struct {
+	Kind       Kind
+	Records    []Record
+	Pairs      []Pair
+	Score      float64
+	SurvivorID int64
+}
+
+// Score compares two records of the same kind and returns the combined score
+// and the attributes they share.
+func (e *Engine) Score(a, b Record) (float64, []Reason) {
+	if a.Kind != b.Kind || a.ID == b.ID {
+		return 0, nil
+	}
+	var reasons []Reason
+	if intersects(a.Emails, b.Emails) {
+		reasons = append(reasons, ReasonEmail)
+	}
+	if intersects(a.Phones, b.Phones) {
+		reasons = append(reasons, ReasonPhone)
+	}
+	nameMatch := a.Name != "" && a.Name == b.Name
+	if nameMatch {
+		reasons = append(reasons, ReasonName)
+	}
+	if nameMatch && a.OrgID != 0 && a.OrgID == b.OrgID {
+		reasons = append(reasons, ReasonOrganization)
+	}
+	if a.Address != "" && a.Address == b.Address {
+		reasons = append(reasons, ReasonAddress)
+	}
+	if a.Website != "" && a.Website == b.Website {
+		reasons = append(reasons, ReasonWebsite)
+	}
+	weights := e.weights[a.Kind]
+	miss := 1.0
+	for _, reason := range reasons {
+		miss *= 1 - weights[reason]
+	}
+	return 1 - miss, reasons
+}
+
+// Cluster groups records into clusters of likely duplicates. Only records that
+// share a normalized email, phone, name or website are compared. Records that
+// match nothing are left out, and clusters are ordered by descending score.
+func (e *Engine) Cluster(records []Record) []Cluster {
+	blocks := make(map[string][]int)
+	for i, r := range records {
+		keys := make([]string, 0, len(r.Emails)+len(r.Phones)+2)
+		for _, email := range r.Emails {
+			keys = append(keys, "e:"+email)
+		}
+		for _, phone := range r.Phones {
+			keys = append(keys, "p:"+phone)
+		}
+		if r.Name != "" {
+			keys = append(keys, "n:"+r.Name)
+		}
+		if r.Website != "" {
+			keys = append(keys, "w:"+r.Website)
+		}
+		for _, key := range keys {
+			blocks[string(r.Kind)+"|"+key] = append(blocks[string(r.Kind)+"|"+key], i)
+		}
+	}
+
+	parent := make([]int, len(records))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	compared := make(map[[2]int]bool)
+	var pairs [][2]int
+	var scored []Pair
+	for _, members := range blocks {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				i, j := members[x], members[y]
+				if i > j {
+					i, j = j, i
+				}
+				if compared[[2]int{i, j}] {
+					continue
+				}
+				compared[[2]int{i, j}] = true
+				score, reasons := e.Score(records[i], records[j])
+				if score < e.threshold || score == 0 {
+					continue
+				}
+				pairs = append(pairs, [2]int{i, j})
+				scored = append(scored, Pair{A: records[i].ID, B: records[j].ID, Score: score, Reasons: reasons})
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	byRoot := make(map[int]*Cluster)
+	var roots []int
+	for n, pair := range pairs {
+		root := find(pair[0])
+		c, ok := byRoot[root]
+		if !ok {
+			c = &Cluster{Kind: records[pair[0]].Kind, Score: 1}
+			byRoot[root] = c
+			roots = append(roots, root)
+		}
+		c.Pairs = append(c.Pairs, scored[n])
+		if scored[n].Score < c.Score {
+			c.Score = scored[n].Score
+		}
+	}
+	for i, r := range records {
+		if c, ok := byRoot[find(i)]; ok {
+			c.Records = append(c.Records, r)
+		}
+	}
+
+	clusters := make([]Cluster, 0, len(roots))
+	for _, root := range roots {
+		c := byRoot[root]
+		sort.Slice(c.Records, func(i, j int) bool { return c.Records[i].ID < c.Records[j].ID })
+		sort.Slice(c.Pairs, func(i, j int) bool {
+			if c.Pairs[i].A != c.Pairs[j].A {
+				return c.Pairs[i].A < c.Pairs[j].A
+			}
+			return c.Pairs[i].B < c.Pairs[j].B
+		})
+		c.SurvivorID = e.survivor(c.Records)
+		clusters = append(clusters, *c)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Score != clusters[j].Score {
+			return clusters[i].Score > clusters[j].Score
+		}
+		return clusters[i].Records[0].ID < clusters[j].Records[0].ID
+	})
+	return clusters
+}
+
+// ScanPersons lists persons through the v2 API and clusters them.
+func (e *Engine) ScanPersons(ctx context.Context, client *v2.Client, opts ...v2.ListPersonsOption) ([]Cluster, error) {
+	var records []Record
+	err := client.Persons.ForEach(ctx, func(p v2.Person) error {
+		if !p.IsDeleted {
+			records = append(records, PersonRecord(p, e.phoneRegion))
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return e.Cluster(records), nil
+}
+
+// ScanOrganizations lists organizations through the v2 API and clusters them.
+func (e *Engine) ScanOrganizations(ctx context.Context, client *v2.Client, opts ...v2.ListOrganizationsOption) ([]Cluster, error) {
+	var records []Record
+	err := client.Organizations.ForEach(ctx, func(o v2.Organization) error {
+		if !o.IsDeleted {
+			records = append(records, OrganizationRecord(o))
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return e.Cluster(records), nil
+}
+
+// Merge is one planned or executed merge of a duplicate into its survivor.
+type Merge struct {
+	Kind        Kind
+	SurvivorID  int64
+	DuplicateID int64
+	Score       float64
+	Done        bool
+	Err         error
+}
+
+type Report struct {
+	DryRun   bool
+	Clusters []Cluster
+	Merges   []Merge
+}
+
+// Merge merges every record of each cluster into its survivor using the v1
+// merge endpoints. The survivor's data wins on conflicting fields. After a
+// failed merge the rest of that cluster is skipped; the returned error joins
+// every failure. In dry-run mode the report only lists the planned merges.
+func (e *Engine) Merge(ctx context.Context, client *v1.Client, clusters []Cluster) (*Report, error) {
+	report := &Report{DryRun: e.dryRun, Clusters: clusters}
+	var errs []error
+	for _, c := range clusters {
+		if !containsRecord(c.Records, c.SurvivorID) {
+			err := fmt.Errorf("dedupe: survivor %d is not part of the %s cluster", c.SurvivorID, c.Kind)
+			errs = append(errs, err)
+			continue
+		}
+		failed := false
+		for _, r := range c.Records {
+			if r.ID == c.SurvivorID {
+				continue
+			}
+			m := Merge{Kind: c.Kind, SurvivorID: c.SurvivorID, DuplicateID: r.ID, Score: c.bestScore(r.ID, c.SurvivorID)}
+			switch {
+			case e.dryRun:
+			case failed:
+				m.Err = fmt.Errorf("skipped after an earlier merge in the cluster failed")
+			default:
+				m.Err = mergeRecord(ctx, client, c.Kind, r.ID, c.SurvivorID)
+				if m.Err != nil {
+					failed = true
+					errs = append(errs, fmt.Errorf("dedupe: merge %s %d into %d: %w", c.Kind, r.ID, c.SurvivorID, m.Err))
+				} else {
+					m.Done = true
+				}
+			}
+			report.Merges = append(report.Merges, m)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+func mergeRecord(ctx context.Context, client *v1.Client, kind Kind, duplicateID, survivorID int64) error {
+	var err error
+	switch kind {
+	case KindPerson:
+		_, err = client.Persons.Merge(ctx, v1.PersonID(duplicateID), v1.PersonID(survivorID))
+	case KindOrganization:
+		_, err = client.Organizations.Merge(ctx, v1.OrganizationID(duplicateID), v1.OrganizationID(survivorID))
+	default:
+		err = fmt.Errorf("unsupported kind %q", kind)
+	}
+	return err
+}
+
+// bestScore returns the direct pair score between two records, or the cluster
+// score when they are only linked through other records.
+func (c Cluster) bestScore(a, b int64) float64 {
+	for _, p := range c.Pairs {
+		if (p.A == a && p.B == b) || (p.A == b && p.B == a) {
+			return p.Score
+		}
+	}
+	return c.Score
+}
+
+// WriteTo writes a plain text summary of the clusters and merges.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	mode := "applied"
+	if r.DryRun {
+		mode = "dry run"
+	}
+	fmt.Fprintf(&b, "%d duplicate clusters, %d merges (%s)\n", len(r.Clusters), len(r.Merges), mode)
+	for _, c := range r.Clusters {
+		fmt.Fprintf(&b, "\n%s cluster score %.2f, survivor %d\n", c.Kind, c.Score, c.SurvivorID)
+		for _, p := range c.Pairs {
+			reasons := make([]string, len(p.Reasons))
+			for i, reason := range p.Reasons {
+				reasons[i] = string(reason)
+			}
+			fmt.Fprintf(&b, "  %d ~ %d  %.2f  %s\n", p.A, p.B, p.Score, strings.Join(reasons, ", "))
+		}
+		for _, m := range r.Merges {
+			if m.Kind != c.Kind || m.SurvivorID != c.SurvivorID || !containsRecord(c.Records, m.DuplicateID) {
+				continue
+			}
+			status := "planned"
+			switch {
+			case m.Err != nil:
+				status = "failed: " + m.Err.Error()
+			case m.Done:
+				status = "merged"
+			}
+			fmt.Fprintf(&b, "  merge %d -> %d  %s\n", m.DuplicateID, m.SurvivorID, status)
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func containsRecord(records []Record, id int64) bool {
+	for _, r := range records {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
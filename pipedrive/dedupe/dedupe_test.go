@@ -0,0 +1,195 @@
+package dedupe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{NormalizeName, "Doe,  Jane", "doe jane"},
+		{NormalizeOrganizationName, "Acme, Inc.", "acme"},
+		{NormalizeOrganizationName, "Oy Ab", "ab oy"},
+		{NormalizeWebsite, "https://WWW.Acme.com/about", "acme.com"},
+	}
+	for _, tc := range cases {
+		if got := tc.fn(tc.in); got != tc.want {
+			t.Fatalf("normalize %q: got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestPersonRecord(t *testing.T) {
+	t.Parallel()
+
+	p := person(1, "Jane Doe", []string{"  Jane.Doe@Example.COM ", "not-an-email"}, []string{"+358 40 123 4567", "040-123 4567", "call 12"}, 0, "")
+	r := PersonRecord(p, "FI")
+	if len(r.Emails) != 1 || r.Emails[0] != "jane.doe@example.com" {
+		t.Fatalf("unexpected emails: %v", r.Emails)
+	}
+	if len(r.Phones) != 1 || r.Phones[0] != "+358401234567" {
+		t.Fatalf("unexpected phones: %v", r.Phones)
+	}
+	if r := PersonRecord(p, ""); len(r.Phones) != 1 || r.Phones[0] != "+358401234567" {
+		t.Fatalf("expected only the international number without a region: %v", r.Phones)
+	}
+}
+
+func person(id int64, name string, emails, phones []string, orgID int64, added string) v2.Person {
+	p := v2.Person{ID: v2.PersonID(id), Name: name}
+	for _, email := range emails {
+		p.Emails = append(p.Emails, v2.LabeledValue{Value: email})
+	}
+	for _, phone := range phones {
+		p.Phones = append(p.Phones, v2.LabeledValue{Value: phone})
+	}
+	if orgID != 0 {
+		org := v2.OrganizationID(orgID)
+		p.OrgID = &org
+	}
+	if added != "" {
+		at, _ := time.Parse(time.DateOnly, added)
+		p.AddTime = &at
+	}
+	return p
+}
+
+func TestEngine_Cluster(t *testing.T) {
+	t.Parallel()
+
+	records := []Record{
+		PersonRecord(person(1, "Jane Doe", []string{"jane@acme.test"}, nil, 0, "2024-05-01"), "FI"),
+		PersonRecord(person(2, "Doe, Jane", []string{"JANE@acme.test"}, []string{"+358 40 123 4567"}, 0, "2023-01-01"), "FI"),
+		PersonRecord(person(3, "J. Doe", nil, []string{"040 123 4567"}, 0, ""), "FI"),
+		PersonRecord(person(4, "John Smith", nil, nil, 9, ""), "FI"),
+		PersonRecord(person(5, "John Smith", nil, nil, 9, ""), "FI"),
+		PersonRecord(person(6, "John Smith", nil, nil, 0, ""), "FI"),
+		OrganizationRecord(v2.Organization{ID: 4, Name: "Acme Inc"}),
+	}
+	clusters := New().Cluster(records)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d: %#v", len(clusters), clusters)
+	}
+
+	contact := clusters[0]
+	if len(contact.Records) != 3 || contact.Score != 0.85 || contact.SurvivorID != 2 {
+		t.Fatalf("unexpected contact cluster: %#v", contact)
+	}
+	if p := contact.Pairs[0]; p.A != 1 || p.B != 2 || len(p.Reasons) != 2 || p.Reasons[0] != ReasonEmail || p.Reasons[1] != ReasonName {
+		t.Fatalf("unexpected pair: %#v", p)
+	}
+
+	colleagues := clusters[1]
+	if len(colleagues.Records) != 2 || colleagues.Records[1].ID != 5 || colleagues.SurvivorID != 4 {
+		t.Fatalf("unexpected same-organization cluster: %#v", colleagues)
+	}
+
+	if got := New(WithThreshold(0.9)).Cluster(records); len(got) != 1 || len(got[0].Records) != 2 {
+		t.Fatalf("expected only the email pair above 0.9, got %#v", got)
+	}
+	if got := New(WithSurvivor(MostComplete)).Cluster(records[:3]); got[0].SurvivorID != 2 {
+		t.Fatalf("expected most complete survivor 2, got %d", got[0].SurvivorID)
+	}
+
+	orgs := New().Cluster([]Record{
+		OrganizationRecord(v2.Organization{ID: 1, Name: "Acme Inc", Website: ptr("https://acme.com")}),
+		OrganizationRecord(v2.Organization{ID: 2, Name: "ACME", Website: ptr("www.acme.com")}),
+		OrganizationRecord(v2.Organization{ID: 3, Name: "Acme Ltd"}),
+	})
+	if len(orgs) != 1 || orgs[0].Kind != KindOrganization || len(orgs[0].Records) != 3 || orgs[0].Score != 0.7 {
+		t.Fatalf("unexpected organization clusters: %#v", orgs)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestEngine_Merge(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		merges []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method != http.MethodPut || !strings.HasSuffix(r.URL.Path, "/merge") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		merges = append(merges, r.URL.Path+" "+strings.TrimSpace(string(body)))
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/persons/5/merge" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"success":false,"error":"cannot merge"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":1}}`))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := v1.NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	records := []Record{
+		PersonRecord(person(1, "Jane Doe", []string{"jane@acme.test"}, nil, 0, ""), "FI"),
+		PersonRecord(person(2, "Jane Doe", []string{"jane@acme.test"}, nil, 0, ""), "FI"),
+		PersonRecord(person(3, "Jane Doe", []string{"jane@acme.test"}, nil, 0, ""), "FI"),
+		PersonRecord(person(4, "Bob", []string{"bob@acme.test"}, nil, 0, ""), "FI"),
+		PersonRecord(person(5, "Bob", []string{"bob@acme.test"}, nil, 0, ""), "FI"),
+		PersonRecord(person(6, "Bob", []string{"bob@acme.test"}, nil, 0, ""), "FI"),
+	}
+
+	dry, err := New(WithDryRun(true)).Merge(context.Background(), client, New().Cluster(records))
+	if err != nil || len(dry.Merges) != 4 || len(merges) != 0 {
+		t.Fatalf("unexpected dry run: %#v, %v, calls %v", dry, err, merges)
+	}
+	var text strings.Builder
+	if _, err := dry.WriteTo(&text); err != nil || !strings.Contains(text.String(), "merge 2 -> 1  planned") || !strings.Contains(text.String(), "(dry run)") {
+		t.Fatalf("unexpected dry run report:\n%s", text.String())
+	}
+
+	clusters := New().Cluster(records)
+	clusters[0].SurvivorID = 3
+	report, err := New().Merge(context.Background(), client, clusters)
+	var apiErr *pipedrive.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected joined API error, got %v", err)
+	}
+	want := []string{
+		`/persons/1/merge {"merge_with_id":3}`,
+		`/persons/2/merge {"merge_with_id":3}`,
+		`/persons/5/merge {"merge_with_id":4}`,
+	}
+	if strings.Join(merges, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected merge calls:\n%s", strings.Join(merges, "\n"))
+	}
+	if !report.Merges[0].Done || report.Merges[2].Err == nil || report.Merges[3].Err == nil || report.Merges[3].Done {
+		t.Fatalf("unexpected merge results: %#v", report.Merges)
+	}
+
+	clusters[0].SurvivorID = 42
+	if _, err := New(WithDryRun(true)).Merge(context.Background(), client, clusters[:1]); err == nil {
+		t.Fatalf("expected unknown survivor error")
+	}
+}
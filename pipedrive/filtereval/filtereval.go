@@ -0,0 +1,762 @@
+package filtereval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+// Values holds entity values per filter object, keyed by field code. Custom
+// fields are keyed by their hash code next to the standard fields.
+type Values map[v1.FilterObject]map[string]interface{}
+
+type Option func(*config)
+
+type config struct {
+	fields     map[v1.FilterObject]map[string]v2.Field
+	fieldCodes map[v1.FilterObject]map[string]string
+	now        func() time.Time
+}
+
+// WithFields supplies the field definitions of a filter object. Field types
+// select the operator semantics; fields without a definition are compared by
+// the Go type of their value.
+func WithFields(object v1.FilterObject, fields []v2.Field) Option {
+	return func(cfg *config) {
+		byCode := cfg.fields[object]
+		if byCode == nil {
+			byCode = make(map[string]v2.Field, len(fields))
+			cfg.fields[object] = byCode
+		}
+		for _, field := range fields {
+			byCode[field.FieldCode] = field
+		}
+	}
+}
+
+// WithFieldCodes maps the numeric field IDs used in filter conditions to field
+// codes for a filter object. Conditions decoded from filters fetched with
+// v1.WithFilterIncludeFieldCode already carry their field code.
+func WithFieldCodes(object v1.FilterObject, codes map[string]string) Option {
+	return func(cfg *config) {
+		byID := cfg.fieldCodes[object]
+		if byID == nil {
+			byID = make(map[string]string, len(codes))
+			cfg.fieldCodes[object] = byID
+		}
+		for id, code := range codes {
+			byID[id] = code
+		}
+	}
+}
+
+// WithNow sets the clock used for relative dates such as "today" or
+// "last_month". The returned time's location decides calendar boundaries.
+func WithNow(now func() time.Time) Option {
+	return func(cfg *config) {
+		if now != nil {
+			cfg.now = now
+		}
+	}
+}
+
+// Evaluator matches entity values against a filter condition tree without
+// calling the API.
+type Evaluator struct {
+	groups []ruleGroup
+	now    func() time.Time
+}
+
+type ruleGroup struct {
+	glue  v1.FilterGlue
+	rules []rule
+}
+
+type rule struct {
+	condition v1.FilterCondition
+	code      string
+	kind      valueKind
+}
+
+type valueKind int
+
+const (
+	kindInferred valueKind = iota
+	kindNumber
+	kindText
+	kindOption
+	kindSet
+	kindDate
+	kindTime
+	kindReference
+	kindBoolean
+)
+
+// New compiles tree into an Evaluator. Every condition must resolve to a field
+// code, and its operator must be one Pipedrive supports for the field type.
+func New(tree *v1.FilterConditionTree, opts ...Option) (*Evaluator, error) {
+	if err := tree.Validate(nil); err != nil {
+		return nil, err
+	}
+	cfg := config{
+		fields:     map[v1.FilterObject]map[string]v2.Field{},
+		fieldCodes: map[v1.FilterObject]map[string]string{},
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	e := &Evaluator{now: cfg.now}
+	for g, group := range tree.Groups {
+		compiled := ruleGroup{glue: group.Glue}
+		for i, condition := range group.Conditions {
+			r, reason := cfg.compile(condition)
+			if reason != "" {
+				return nil, &v1.FilterConditionError{Group: g, Index: i, Reason: reason}
+			}
+			compiled.rules = append(compiled.rules, r)
+		}
+		e.groups = append(e.groups, compiled)
+	}
+	return e, nil
+}
+
+// ForFilter compiles the conditions of filter. Fetch the filter with
+// v1.WithFilterIncludeFieldCode(true) so conditions carry field codes.
+func ForFilter(filter *v1.Filter, opts ...Option) (*Evaluator, error) {
+	tree, err := filter.ConditionTree()
+	if err != nil {
+		return nil, err
+	}
+	return New(tree, opts...)
+}
+
+// Load fetches the field definitions for every object tree references through
+// the v2 field services, then compiles tree. Lead conditions use deal fields.
+func Load(ctx context.Context, client *v2.Client, tree *v1.FilterConditionTree, opts ...Option) (*Evaluator, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+	if tree == nil {
+		return nil, fmt.Errorf("filter conditions are required")
+	}
+	seen := map[v1.FilterObject]bool{}
+	var loaded []Option
+	for _, group := range tree.Groups {
+		for _, condition := range group.Conditions {
+			if seen[condition.Object] {
+				continue
+			}
+			seen[condition.Object] = true
+			fields, err := fetchFields(ctx, client, condition.Object)
+			if err != nil {
+				return nil, err
+			}
+			loaded = append(loaded, WithFields(condition.Object, fields))
+		}
+	}
+	return New(tree, append(loaded, opts...)...)
+}
+
+func fetchFields(ctx context.Context, client *v2.Client, object v1.FilterObject) ([]v2.Field, error) {
+	var fields []v2.Field
+	collect := func(field v2.Field) error {
+		fields = append(fields, field)
+		return nil
+	}
+	var err error
+	switch object {
+	case v1.FilterObjectDeal, v1.FilterObjectLead:
+		err = client.DealFields.ForEach(ctx, collect)
+	case v1.FilterObjectPerson:
+		err = client.PersonFields.ForEach(ctx, collect)
+	case v1.FilterObjectOrganization:
+		err = client.OrganizationFields.ForEach(ctx, collect)
+	case v1.FilterObjectProduct:
+		err = client.ProductFields.ForEach(ctx, collect)
+	case v1.FilterObjectActivity:
+		err = client.ActivityFields.ForEach(ctx, collect)
+	case v1.FilterObjectProject:
+		err = client.ProjectFields.ForEach(ctx, collect)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list %s fields: %w", object, err)
+	}
+	return fields, nil
+}
+
+func (cfg config) compile(condition v1.FilterCondition) (rule, string) {
+	code := condition.FieldCode
+	if code == "" {
+		code = cfg.fieldCodes[condition.Object][condition.FieldID]
+	}
+	if code == "" {
+		if _, ok := cfg.fields[condition.Object][condition.FieldID]; ok {
+			code = condition.FieldID
+		}
+	}
+	if code == "" {
+		return rule{}, fmt.Sprintf("no field code for %s field %s; fetch the filter with field codes or use WithFieldCodes", condition.Object, condition.FieldID)
+	}
+
+	fieldType := v2.FieldType(condition.FieldType)
+	if fieldType == "" {
+		fieldType = cfg.fields[condition.Object][code].FieldType
+	}
+	r := rule{condition: condition, code: code, kind: kindOf(fieldType)}
+	if !supports(r.kind, condition.Operator) {
+		return rule{}, fmt.Sprintf("operator %q is not supported for %s fields", condition.Operator, fieldType)
+	}
+	if r.kind == kindDate && !isNullOperator(condition.Operator) {
+		if _, _, err := dateBounds(condition.Value, time.Now()); err != nil {
+			return rule{}, err.Error()
+		}
+	}
+	return r, ""
+}
+
+func kindOf(fieldType v2.FieldType) valueKind {
+	switch fieldType {
+	case "":
+		return kindInferred
+	case v2.FieldTypeInt, v2.FieldTypeDouble, v2.FieldTypeMonetary:
+		return kindNumber
+	case v2.FieldTypeEnum, v2.FieldTypeStatus, v2.FieldTypeVisibleTo:
+		return kindOption
+	case v2.FieldTypeSet:
+		return kindSet
+	case v2.FieldTypeDate, v2.FieldTypeDateRange:
+		return kindDate
+	case v2.FieldTypeTime, v2.FieldTypeTimeRange:
+		return kindTime
+	case v2.FieldTypeUser, v2.FieldTypeOrg, v2.FieldTypePeople, v2.FieldTypeStage, v2.FieldTypeDeal,
+		v2.FieldTypeLead, v2.FieldTypeProject, v2.FieldTypeActivity:
+		return kindReference
+	case v2.FieldTypeBoolean:
+		return kindBoolean
+	default:
+		return kindText
+	}
+}
+
+// supports mirrors the operator lists ListHelpers returns per field type.
+func supports(kind valueKind, operator v1.FilterOperator) bool {
+	switch operator {
+	case v1.FilterOperatorIsNull, v1.FilterOperatorIsNotNull, v1.FilterOperatorEqual, v1.FilterOperatorNotEqual:
+		return true
+	case v1.FilterOperatorLess, v1.FilterOperatorGreater, v1.FilterOperatorLessOrEqual, v1.FilterOperatorGreaterOrEqual:
+		switch kind {
+		case kindInferred, kindNumber, kindDate, kindTime:
+			return true
+		}
+	case v1.FilterOperatorStartsWith, v1.FilterOperatorContains, v1.FilterOperatorNotStartsWith,
+		v1.FilterOperatorNotContains, v1.FilterOperatorEndsWith, v1.FilterOperatorNotEndsWith:
+		return kind == kindInferred || kind == kindText
+	}
+	return false
+}
+
+func isNullOperator(operator v1.FilterOperator) bool {
+	return operator == v1.FilterOperatorIsNull || operator == v1.FilterOperatorIsNotNull
+}
+
+// Match reports whether values satisfy the filter: every condition of the
+// "and" group and at least one condition of a non-empty "or" group.
+func (e *Evaluator) Match(values Values) (bool, error) {
+	if e == nil {
+		return false, fmt.Errorf("evaluator is nil")
+	}
+	now := e.now()
+	for g, group := range e.groups {
+		if len(group.rules) == 0 {
+			continue
+		}
+		matchedAny := false
+		for i, r := range group.rules {
+			object, ok := values[r.condition.Object]
+			if !ok {
+				return false, &v1.FilterConditionError{Group: g, Index: i, Reason: fmt.Sprintf("no %s values", r.condition.Object)}
+			}
+			matched, err := r.match(object[r.code], now)
+			if err != nil {
+				return false, &v1.FilterConditionError{Group: g, Index: i, Reason: err.Error()}
+			}
+			if group.glue == v1.FilterGlueAnd && !matched {
+				return false, nil
+			}
+			if matched {
+				matchedAny = true
+			}
+		}
+		if group.glue == v1.FilterGlueOr && !matchedAny {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// MatchEntity is Match for a single object whose values are read from a v1 or
+// v2 entity (or a map) with EntityValues.
+func (e *Evaluator) MatchEntity(object v1.FilterObject, entity interface{}) (bool, error) {
+	fields, err := EntityValues(entity)
+	if err != nil {
+		return false, err
+	}
+	return e.Match(Values{object: fields})
+}
+
+// EntityValues converts an entity to field-code keyed values by encoding it as
+// JSON. Values nested under "custom_fields", as in v2 entities, are lifted to
+// the top level.
+func EntityValues(entity interface{}) (map[string]interface{}, error) {
+	if values, ok := entity.(map[string]interface{}); ok {
+		return liftCustomFields(values), nil
+	}
+	raw, err := json.Marshal(entity)
+	if err != nil {
+		return nil, fmt.Errorf("encode entity: %w", err)
+	}
+	var values map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("entity is not an object: %w", err)
+	}
+	return liftCustomFields(values), nil
+}
+
+func liftCustomFields(values map[string]interface{}) map[string]interface{} {
+	custom, ok := values["custom_fields"].(map[string]interface{})
+	if !ok {
+		return values
+	}
+	out := make(map[string]interface{}, len(values)+len(custom))
+	for key, value := range custom {
+		out[key] = value
+	}
+	for key, value := range values {
+		out[key] = value
+	}
+	return out
+}
+
+func (r rule) match(value interface{}, now time.Time) (bool, error) {
+	value = scalar(value)
+	operator := r.condition.Operator
+	switch operator {
+	case v1.FilterOperatorIsNull:
+		return isEmpty(value), nil
+	case v1.FilterOperatorIsNotNull:
+		return !isEmpty(value), nil
+	}
+
+	kind := r.kind
+	if kind == kindInferred {
+		kind = inferKind(value)
+	}
+	want := scalar(r.condition.Value)
+
+	switch kind {
+	case kindNumber:
+		if isEmpty(value) {
+			return operator == v1.FilterOperatorNotEqual, nil
+		}
+		got, ok := toFloat(value)
+		if !ok {
+			return false, fmt.Errorf("value %v is not a number", value)
+		}
+		target, ok := toFloat(want)
+		if !ok {
+			return false, fmt.Errorf("condition value %v is not a number", want)
+		}
+		return compare(operator, cmpFloat(got, target)), nil
+	case kindDate:
+		if isEmpty(value) {
+			return operator == v1.FilterOperatorNotEqual, nil
+		}
+		got, err := toDate(value, now.Location())
+		if err != nil {
+			return false, err
+		}
+		start, end, err := dateBounds(r.condition.Value, now)
+		if err != nil {
+			return false, err
+		}
+		return compareRange(operator, got, start, end), nil
+	case kindTime:
+		if isEmpty(value) {
+			return operator == v1.FilterOperatorNotEqual, nil
+		}
+		got, target := normalizeTime(toString(value)), normalizeTime(toString(want))
+		return compare(operator, strings.Compare(got, target)), nil
+	case kindSet:
+		contains := false
+		for _, item := range toList(value) {
+			if idString(item) == idString(want) {
+				contains = true
+				break
+			}
+		}
+		return contains == (operator == v1.FilterOperatorEqual), nil
+	case kindOption, kindReference:
+		equal := !isEmpty(value) && idString(value) == idString(want)
+		return equal == (operator == v1.FilterOperatorEqual), nil
+	case kindBoolean:
+		equal := truthy(value) == truthy(want)
+		return equal == (operator == v1.FilterOperatorEqual), nil
+	default:
+		return matchText(operator, toString(value), toString(want)), nil
+	}
+}
+
+func inferKind(value interface{}) valueKind {
+	switch value.(type) {
+	case json.Number, float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return kindNumber
+	case bool:
+		return kindBoolean
+	case time.Time:
+		return kindDate
+	case []interface{}:
+		return kindSet
+	default:
+		return kindText
+	}
+}
+
+func matchText(operator v1.FilterOperator, value, want string) bool {
+	value, want = strings.ToLower(value), strings.ToLower(want)
+	switch operator {
+	case v1.FilterOperatorEqual:
+		return value == want
+	case v1.FilterOperatorNotEqual:
+		return value != want
+	case v1.FilterOperatorStartsWith:
+		return strings.HasPrefix(value, want)
+	case v1.FilterOperatorNotStartsWith:
+		return !strings.HasPrefix(value, want)
+	case v1.FilterOperatorContains:
+		return strings.Contains(value, want)
+	case v1.FilterOperatorNotContains:
+		return !strings.Contains(value, want)
+	case v1.FilterOperatorEndsWith:
+		return strings.HasSuffix(value, want)
+	case v1.FilterOperatorNotEndsWith:
+		return !strings.HasSuffix(value, want)
+	case v1.FilterOperatorLess:
+		return value < want
+	case v1.FilterOperatorGreater:
+		return value > want
+	case v1.FilterOperatorLessOrEqual:
+		return value <= want
+	case v1.FilterOperatorGreaterOrEqual:
+		return value >= want
+	}
+	return false
+}
+
+func compare(operator v1.FilterOperator, cmp int) bool {
+	switch operator {
+	case v1.FilterOperatorEqual:
+		return cmp == 0
+	case v1.FilterOperatorNotEqual:
+		return cmp != 0
+	case v1.FilterOperatorLess:
+		return cmp < 0
+	case v1.FilterOperatorGreater:
+		return cmp > 0
+	case v1.FilterOperatorLessOrEqual:
+		return cmp <= 0
+	case v1.FilterOperatorGreaterOrEqual:
+		return cmp >= 0
+	}
+	return false
+}
+
+// compareRange compares a date against the half-open interval [start, end)
+// that a condition value covers, so "=" on "this_month" means any day of it.
+func compareRange(operator v1.FilterOperator, got, start, end time.Time) bool {
+	switch operator {
+	case v1.FilterOperatorEqual:
+		return !got.Before(start) && got.Before(end)
+	case v1.FilterOperatorNotEqual:
+		return got.Before(start) || !got.Before(end)
+	case v1.FilterOperatorLess:
+		return got.Before(start)
+	case v1.FilterOperatorGreater:
+		return !got.Before(end)
+	case v1.FilterOperatorLessOrEqual:
+		return got.Before(end)
+	case v1.FilterOperatorGreaterOrEqual:
+		return !got.Before(start)
+	}
+	return false
+}
+
+func cmpFloat(a, b float64) int {
+	const epsilon = 1e-9
+	switch {
+	case math.Abs(a-b) < epsilon:
+		return 0
+	case a < b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+var relativeOffsetPattern = regexp.MustCompile(`^(\d+)_(days?|weeks?|months?|quarters?|years?)_(ago|later|from_now)$`)
+
+// dateBounds returns the day interval an exact (YYYY-MM-DD) or relative date
+// condition value covers, in now's location.
+func dateBounds(value interface{}, now time.Time) (time.Time, time.Time, error) {
+	text := strings.TrimSpace(toString(scalar(value)))
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	day := func(t time.Time) (time.Time, time.Time, error) { return t, t.AddDate(0, 0, 1), nil }
+
+	if parsed, err := time.ParseInLocation("2006-01-02", text, loc); err == nil {
+		return day(parsed)
+	}
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc)
+	quarterStart := time.Date(today.Year(), today.Month()-(today.Month()-1)%3, 1, 0, 0, 0, 0, loc)
+	yearStart := time.Date(today.Year(), 1, 1, 0, 0, 0, 0, loc)
+	span := func(start time.Time, years, months, days int) (time.Time, time.Time, error) {
+		return start, start.AddDate(years, months, days), nil
+	}
+
+	switch text {
+	case "today":
+		return day(today)
+	case "yesterday":
+		return day(today.AddDate(0, 0, -1))
+	case "tomorrow":
+		return day(today.AddDate(0, 0, 1))
+	case "this_week":
+		return span(weekStart, 0, 0, 7)
+	case "last_week":
+		return span(weekStart.AddDate(0, 0, -7), 0, 0, 7)
+	case "next_week":
+		return span(weekStart.AddDate(0, 0, 7), 0, 0, 7)
+	case "this_month":
+		return span(monthStart, 0, 1, 0)
+	case "last_month":
+		return span(monthStart.AddDate(0, -1, 0), 0, 1, 0)
+	case "next_month":
+		return span(monthStart.AddDate(0, 1, 0), 0, 1, 0)
+	case "this_quarter":
+		return span(quarterStart, 0, 3, 0)
+	case "last_quarter":
+		return span(quarterStart.AddDate(0, -3, 0), 0, 3, 0)
+	case "next_quarter":
+		return span(quarterStart.AddDate(0, 3, 0), 0, 3, 0)
+	case "this_year":
+		return span(yearStart, 1, 0, 0)
+	case "last_year":
+		return span(yearStart.AddDate(-1, 0, 0), 1, 0, 0)
+	case "next_year":
+		return span(yearStart.AddDate(1, 0, 0), 1, 0, 0)
+	}
+
+	if m := relativeOffsetPattern.FindStringSubmatch(text); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if m[3] == "ago" {
+			n = -n
+		}
+		switch strings.TrimSuffix(m[2], "s") {
+		case "day":
+			return day(today.AddDate(0, 0, n))
+		case "week":
+			return day(today.AddDate(0, 0, 7*n))
+		case "month":
+			return day(today.AddDate(0, n, 0))
+		case "quarter":
+			return day(today.AddDate(0, 3*n, 0))
+		default:
+			return day(today.AddDate(n, 0, 0))
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unsupported date value %q", text)
+}
+
+func toDate(value interface{}, loc *time.Location) (time.Time, error) {
+	t, ok := value.(time.Time)
+	if !ok {
+		text := strings.TrimSpace(toString(value))
+		var err error
+		if t, err = time.ParseInLocation("2006-01-02", text, loc); err != nil {
+			if t, err = time.Parse(time.RFC3339Nano, text); err != nil {
+				// v1 date-times are UTC.
+				if t, err = time.ParseInLocation("2006-01-02 15:04:05", text, time.UTC); err != nil {
+					return time.Time{}, fmt.Errorf("value %q is not a date", text)
+				}
+			}
+		}
+	}
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), nil
+}
+
+func normalizeTime(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) == len("15:04") {
+		return value + ":00"
+	}
+	return value
+}
+
+// scalar unwraps the object forms Pipedrive uses for monetary, address and
+// reference values ({"value": ...} or {"id": ...}).
+func scalar(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if inner, ok := v["value"]; ok {
+			return scalar(inner)
+		}
+		if inner, ok := v["id"]; ok {
+			return scalar(inner)
+		}
+	case v2.FieldOption:
+		if v.StringID != "" {
+			return v.StringID
+		}
+		return v.ID
+	case v2.MonetaryValue:
+		return v.Amount
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return value
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	case bool:
+		return 0, false
+	}
+	rv := reflect.ValueOf(value)
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int()), true
+	case rv.CanUint():
+		return float64(rv.Uint()), true
+	case rv.CanFloat():
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format("2006-01-02")
+	}
+	if f, ok := toFloat(value); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// idString normalises option and entity IDs so 5, 5.0 and "5" compare equal.
+func idString(value interface{}) string {
+	return strings.TrimSpace(toString(scalar(value)))
+}
+
+func toList(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	case string:
+		var out []interface{}
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+		return out
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice {
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = rv.Index(i).Interface()
+		}
+		return out
+	}
+	return []interface{}{value}
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return err == nil && b
+	}
+	if f, ok := toFloat(value); ok {
+		return f != 0
+	}
+	return false
+}
@@ -0,0 +1,251 @@
+package filtereval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+const testDealFields = `[
+	{"field_code":"title","field_name":"Title","field_type":"varchar"},
+	{"field_code":"value","field_name":"Value","field_type":"monetary"},
+	{"field_code":"stage_id","field_name":"Stage","field_type":"stage"},
+	{"field_code":"expected_close_date","field_name":"Expected close date","field_type":"date"},
+	{"field_code":"h_region","field_name":"Region","field_type":"enum","is_custom_field":true,"options":[{"id":1,"label":"EMEA"},{"id":2,"label":"APAC"}]},
+	{"field_code":"h_tags","field_name":"Tags","field_type":"set","is_custom_field":true,"options":[{"id":7,"label":"Hot"},{"id":8,"label":"Cold"}]}
+]`
+
+func testFields(t *testing.T) []v2.Field {
+	t.Helper()
+
+	var fields []v2.Field
+	if err := json.Unmarshal([]byte(testDealFields), &fields); err != nil {
+		t.Fatalf("unmarshal fields: %v", err)
+	}
+	return fields
+}
+
+func testNow() time.Time {
+	return time.Date(2026, time.March, 18, 10, 0, 0, 0, time.UTC)
+}
+
+func condition(object v1.FilterObject, code string, operator v1.FilterOperator, value interface{}) v1.FilterCondition {
+	return v1.FilterCondition{Object: object, FieldID: "1", FieldCode: code, Operator: operator, Value: value}
+}
+
+func TestEvaluator_Match(t *testing.T) {
+	t.Parallel()
+
+	tree := v1.NewFilterConditionTree().
+		And(
+			condition(v1.FilterObjectDeal, "value", v1.FilterOperatorGreaterOrEqual, "1000"),
+			condition(v1.FilterObjectDeal, "h_region", v1.FilterOperatorEqual, "1"),
+			condition(v1.FilterObjectDeal, "expected_close_date", v1.FilterOperatorEqual, "this_month"),
+			condition(v1.FilterObjectPerson, "email", v1.FilterOperatorNotContains, "@example.com"),
+		).
+		Or(
+			condition(v1.FilterObjectDeal, "title", v1.FilterOperatorStartsWith, "acme"),
+			condition(v1.FilterObjectDeal, "h_tags", v1.FilterOperatorEqual, "7"),
+		)
+	e, err := New(tree, WithFields(v1.FilterObjectDeal, testFields(t)), WithNow(testNow))
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+
+	deal := map[string]interface{}{
+		"title":               "Globex renewal",
+		"value":               1500,
+		"expected_close_date": "2026-03-31",
+		"custom_fields": map[string]interface{}{
+			"h_region": 1,
+			"h_tags":   []interface{}{float64(7), float64(8)},
+		},
+	}
+	person := map[string]interface{}{"email": "jane@acme.test"}
+	values := func(deal map[string]interface{}) Values {
+		fields, err := EntityValues(deal)
+		if err != nil {
+			t.Fatalf("EntityValues error: %v", err)
+		}
+		return Values{v1.FilterObjectDeal: fields, v1.FilterObjectPerson: person}
+	}
+
+	if ok, err := e.Match(values(deal)); err != nil || !ok {
+		t.Fatalf("expected match, got %v, %v", ok, err)
+	}
+
+	for name, change := range map[string]func(map[string]interface{}){
+		"value below":  func(d map[string]interface{}) { d["value"] = 999.5 },
+		"other region": func(d map[string]interface{}) { d["custom_fields"].(map[string]interface{})["h_region"] = 2 },
+		"next month":   func(d map[string]interface{}) { d["expected_close_date"] = "2026-04-01" },
+		"no or matching": func(d map[string]interface{}) {
+			d["custom_fields"].(map[string]interface{})["h_tags"] = []interface{}{8}
+		},
+	} {
+		changed := map[string]interface{}{}
+		for key, value := range deal {
+			changed[key] = value
+		}
+		changed["custom_fields"] = map[string]interface{}{"h_region": 1, "h_tags": []interface{}{7}}
+		change(changed)
+		if ok, err := e.Match(values(changed)); err != nil || ok {
+			t.Fatalf("%s: expected no match, got %v, %v", name, ok, err)
+		}
+	}
+
+	withoutPerson := values(deal)
+	delete(withoutPerson, v1.FilterObjectPerson)
+	_, err = e.Match(withoutPerson)
+	var condErr *v1.FilterConditionError
+	if !errors.As(err, &condErr) || condErr.Index != 3 {
+		t.Fatalf("expected missing person values error, got %v", err)
+	}
+}
+
+func TestEvaluator_Operators(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		operator v1.FilterOperator
+		value    interface{}
+		entity   interface{}
+		want     bool
+	}{
+		{"null", v1.FilterOperatorIsNull, nil, nil, true},
+		{"empty string is null", v1.FilterOperatorIsNull, nil, "", true},
+		{"not null", v1.FilterOperatorIsNotNull, nil, "x", true},
+		{"text equal ignores case", v1.FilterOperatorEqual, "ACME", "acme", true},
+		{"ends with", v1.FilterOperatorEndsWith, "corp", "Acme Corp", true},
+		{"not starts with", v1.FilterOperatorNotStartsWith, "acme", "Acme Corp", false},
+		{"number from string", v1.FilterOperatorLess, "10", json.Number("9.5"), true},
+		{"monetary object", v1.FilterOperatorGreater, 100, map[string]interface{}{"value": 150, "currency": "EUR"}, true},
+		{"reference object", v1.FilterOperatorEqual, "5", map[string]interface{}{"id": 5, "name": "Jane"}, true},
+	}
+	for _, tc := range cases {
+		tree := v1.NewFilterConditionTree().And(condition(v1.FilterObjectDeal, "f", tc.operator, tc.value))
+		e, err := New(tree, WithNow(testNow))
+		if err != nil {
+			t.Fatalf("%s: New error: %v", tc.name, err)
+		}
+		got, err := e.Match(Values{v1.FilterObjectDeal: {"f": tc.entity}})
+		if err != nil {
+			t.Fatalf("%s: Match error: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDateBounds(t *testing.T) {
+	t.Parallel()
+
+	now := testNow() // Wednesday
+	cases := map[string][2]string{
+		"2026-01-05":    {"2026-01-05", "2026-01-06"},
+		"yesterday":     {"2026-03-17", "2026-03-18"},
+		"this_week":     {"2026-03-16", "2026-03-23"},
+		"last_month":    {"2026-02-01", "2026-03-01"},
+		"this_quarter":  {"2026-01-01", "2026-04-01"},
+		"6_months_ago":  {"2025-09-18", "2025-09-19"},
+		"2_weeks_later": {"2026-04-01", "2026-04-02"},
+	}
+	for value, want := range cases {
+		start, end, err := dateBounds(value, now)
+		if err != nil {
+			t.Fatalf("%s: %v", value, err)
+		}
+		if got := [2]string{start.Format("2006-01-02"), end.Format("2006-01-02")}; got != want {
+			t.Fatalf("%s: got %v, want %v", value, got, want)
+		}
+	}
+	if _, _, err := dateBounds("someday", now); err == nil {
+		t.Fatalf("expected unsupported date error")
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	t.Parallel()
+
+	fields := WithFields(v1.FilterObjectDeal, testFields(t))
+	for name, tree := range map[string]*v1.FilterConditionTree{
+		"no field code": v1.NewFilterConditionTree().And(v1.FilterCondition{
+			Object: v1.FilterObjectDeal, FieldID: "12", Operator: v1.FilterOperatorEqual,
+		}),
+		"like on enum": v1.NewFilterConditionTree().And(condition(v1.FilterObjectDeal, "h_region", v1.FilterOperatorContains, "1")),
+		"bad date":     v1.NewFilterConditionTree().And(condition(v1.FilterObjectDeal, "expected_close_date", v1.FilterOperatorEqual, "soon")),
+	} {
+		if _, err := New(tree, fields); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+
+	tree := v1.NewFilterConditionTree().And(v1.FilterCondition{
+		Object: v1.FilterObjectDeal, FieldID: "12", Operator: v1.FilterOperatorEqual, Value: "1",
+	})
+	e, err := New(tree, fields, WithFieldCodes(v1.FilterObjectDeal, map[string]string{"12": "h_region"}))
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	if ok, err := e.MatchEntity(v1.FilterObjectDeal, v2.Deal{CustomFields: map[string]interface{}{"h_region": 1}}); err != nil || !ok {
+		t.Fatalf("expected match, got %v, %v", ok, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dealFields" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":` + testDealFields + `,"additional_data":{"next_cursor":null}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := v2.NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	filter := &v1.Filter{Conditions: v1.FilterConditions{
+		"glue": "and",
+		"conditions": []interface{}{
+			map[string]interface{}{"glue": "and", "conditions": []interface{}{
+				map[string]interface{}{"object": "deal", "field_id": "9", "field_code": "h_tags", "operator": "!=", "value": "8"},
+				map[string]interface{}{"object": "lead", "field_id": "3", "field_code": "value", "operator": ">", "value": "10"},
+			}},
+			map[string]interface{}{"glue": "or", "conditions": []interface{}{}},
+		},
+	}}
+	tree, err := filter.ConditionTree()
+	if err != nil {
+		t.Fatalf("ConditionTree error: %v", err)
+	}
+	e, err := Load(context.Background(), client, tree)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected deal fields for deal and lead conditions, got %d calls", calls.Load())
+	}
+	ok, err := e.Match(Values{
+		v1.FilterObjectDeal: {"h_tags": "7,9"},
+		v1.FilterObjectLead: {"value": map[string]interface{}{"amount": 20, "value": 20}},
+	})
+	if err != nil || !ok {
+		t.Fatalf("expected match, got %v, %v", ok, err)
+	}
+}
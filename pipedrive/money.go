@@ -0,0 +1,166 @@
+package pipedrive
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// currencyDecimals lists ISO 4217 currencies whose minor unit is not 2.
+var currencyDecimals = map[string]int{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "OMR": 3, "PYG": 0, "TND": 3, "UGX": 0, "VND": 0,
+}
+
+// CurrencyDecimals returns the number of minor unit digits of an ISO 4217
+// currency code, defaulting to 2.
+func CurrencyDecimals(currency string) int {
+	if decimals, ok := currencyDecimals[strings.ToUpper(currency)]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// Money is an exact amount in a currency. Currency is an ISO 4217 code and may
+// be empty for amounts whose currency is implied.
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+func NewMoney(amount Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney parses an amount such as "1234.50" in the given currency.
+func ParseMoney(amount, currency string) (Money, error) {
+	d, err := ParseDecimal(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(d, currency), nil
+}
+
+// CurrencyMismatchError is returned by Money arithmetic on amounts in
+// different currencies.
+type CurrencyMismatchError struct {
+	Left  string
+	Right string
+}
+
+func (e *CurrencyMismatchError) Error() string {
+	if e == nil {
+		return "currency mismatch"
+	}
+	return fmt.Sprintf("currency mismatch: %s and %s", e.Left, e.Right)
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if !strings.EqualFold(m.Currency, o.Currency) {
+		return &CurrencyMismatchError{Left: m.Currency, Right: o.Currency}
+	}
+	return nil
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Add(o.Amount), Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Sub(o.Amount), Currency: m.Currency}, nil
+}
+
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	return m.Amount.Cmp(o.Amount), nil
+}
+
+// Mul scales m, for example by a quantity or a tax rate.
+func (m Money) Mul(factor Decimal) Money {
+	return Money{Amount: m.Amount.Mul(factor), Currency: m.Currency}
+}
+
+// Percent returns percent per cent of m without rounding.
+func (m Money) Percent(percent Decimal) Money {
+	return m.Mul(percent.Quo(DecimalFromInt(100)))
+}
+
+func (m Money) Neg() Money {
+	return Money{Amount: m.Amount.Neg(), Currency: m.Currency}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+// Round rounds m to the minor unit of its currency.
+func (m Money) Round() Money {
+	return Money{Amount: m.Amount.Round(CurrencyDecimals(m.Currency)), Currency: m.Currency}
+}
+
+// Split divides m into n parts in minor units that add up to m rounded to its
+// currency; the first parts absorb the remainder. It is intended for
+// installments.
+func (m Money) Split(n int) []Money {
+	if n <= 0 {
+		return nil
+	}
+	unit := MustParseDecimal(fmt.Sprintf("1e-%d", CurrencyDecimals(m.Currency)))
+	units := m.Round().Amount.Quo(unit).rat().Num()
+	part, remainder := new(big.Int).QuoRem(units, big.NewInt(int64(n)), new(big.Int))
+	parts := make([]Money, n)
+	step := int64(remainder.Sign())
+	left := new(big.Int).Abs(remainder).Int64()
+	for i := range parts {
+		count := new(big.Int).Set(part)
+		if int64(i) < left {
+			count.Add(count, big.NewInt(step))
+		}
+		parts[i] = Money{Amount: Decimal{r: new(big.Rat).SetInt(count)}.Mul(unit), Currency: m.Currency}
+	}
+	return parts
+}
+
+// String formats m with the minor unit digits of its currency followed by the
+// currency code, for example "1234.50 EUR".
+func (m Money) String() string {
+	amount := m.Amount.StringFixed(CurrencyDecimals(m.Currency))
+	if m.Currency == "" {
+		return amount
+	}
+	return amount + " " + m.Currency
+}
+
+// Format formats m like String with the integer digits grouped in thousands
+// by sep, for example "1,234,567.50 EUR".
+func (m Money) Format(sep string) string {
+	amount := m.Amount.StringFixed(CurrencyDecimals(m.Currency))
+	sign := ""
+	if strings.HasPrefix(amount, "-") {
+		sign, amount = "-", amount[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(amount, ".")
+	var b strings.Builder
+	for i, r := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(r)
+	}
+	out := sign + b.String()
+	if hasFraction {
+		out += "." + fraction
+	}
+	if m.Currency != "" {
+		out += " " + m.Currency
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package pipedrive
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMoney(t *testing.T) {
+	t.Parallel()
+
+	price, err := ParseMoney("1234567.505", "eur")
+	if err != nil {
+		t.Fatalf("ParseMoney error: %v", err)
+	}
+	if price.String() != "1234567.51 EUR" || price.Format(",") != "1,234,567.51 EUR" {
+		t.Fatalf("unexpected formatting: %s, %s", price, price.Format(","))
+	}
+	if yen := NewMoney(MustParseDecimal("-1234.5"), "JPY"); yen.Format(" ") != "-1 235 JPY" {
+		t.Fatalf("unexpected yen formatting: %s", yen.Format(" "))
+	}
+
+	total, err := price.Add(NewMoney(DecimalFromFloat(0.495), "EUR"))
+	if err != nil || total.Amount.String() != "1234568" {
+		t.Fatalf("unexpected sum: %v, %v", total, err)
+	}
+	if got := price.Mul(DecimalFromInt(3)).Percent(DecimalFromInt(10)).Round().String(); got != "370370.25 EUR" {
+		t.Fatalf("unexpected scaled amount: %s", got)
+	}
+	var mismatch *CurrencyMismatchError
+	if _, err := price.Sub(NewMoney(DecimalFromInt(1), "USD")); !errors.As(err, &mismatch) || mismatch.Right != "USD" {
+		t.Fatalf("expected currency mismatch, got %v", err)
+	}
+
+	parts := NewMoney(MustParseDecimal("100"), "EUR").Split(3)
+	if len(parts) != 3 || parts[0].Amount.String() != "33.34" || parts[2].Amount.String() != "33.33" {
+		t.Fatalf("unexpected split: %v", parts)
+	}
+	if parts := NewMoney(MustParseDecimal("-0.05"), "EUR").Split(2); parts[0].Amount.String() != "-0.03" || parts[1].Amount.String() != "-0.02" {
+		t.Fatalf("unexpected negative split: %v", parts)
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	t.Parallel()
+
+	var v struct {
+		A Decimal  `json:"a"`
+		B Decimal  `json:"b"`
+		C *Decimal `json:"c"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":12345678901234567.89,"b":"0.10","c":null}`), &v); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if v.A.String() != "12345678901234567.89" || v.B.String() != "0.1" || v.C != nil {
+		t.Fatalf("unexpected decimals: %s, %s, %v", v.A, v.B, v.C)
+	}
+	out, err := json.Marshal(Money{Amount: v.A, Currency: "USD"})
+	if err != nil || string(out) != `{"amount":12345678901234567.89,"currency":"USD"}` {
+		t.Fatalf("unexpected money JSON: %s, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"a":"x"}`), &v); err == nil {
+		t.Fatalf("expected invalid decimal error")
+	}
+}
@@ -0,0 +1,71 @@
+package pipedrive
+
+import (
+	"context"
+	"time"
+)
+
+// PollPolicy controls the delays PollUntil waits between attempts. The delay
+// starts at InitialDelay and is multiplied by Multiplier after every attempt,
+// up to MaxDelay. A zero InitialDelay means the DefaultPollPolicy one.
+type PollPolicy struct {
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+
+	Jitter func(time.Duration) time.Duration
+}
+
+func DefaultPollPolicy() PollPolicy {
+	return PollPolicy{
+		InitialDelay: 500 * time.Millisecond,
+		MaxDelay:     10 * time.Second,
+		Multiplier:   2,
+		Jitter:       equalJitter,
+	}
+}
+
+// PollUntil calls check until it reports done or fails, waiting between
+// attempts according to policy. It returns the value of the last call. The
+// wait ends early with the context error when ctx is done, so callers bound
+// the total wait with a context deadline.
+func PollUntil[T any](ctx context.Context, policy PollPolicy, check func(context.Context) (T, bool, error)) (T, error) {
+	policy = sanitizePollPolicy(policy)
+	delay := policy.InitialDelay
+	for {
+		value, done, err := check(ctx)
+		if err != nil || done {
+			return value, err
+		}
+		if err := sleepWithContext(ctx, policy.Jitter(delay)); err != nil {
+			return value, err
+		}
+		delay = min(time.Duration(float64(delay)*policy.Multiplier), policy.MaxDelay)
+	}
+}
+
+// sanitizePollPolicy fills in the default initial delay when none is set, as
+// a zero delay would stay zero and poll in a tight loop.
+func sanitizePollPolicy(policy PollPolicy) PollPolicy {
+	if policy.InitialDelay <= 0 {
+		policy.InitialDelay = DefaultPollPolicy().InitialDelay
+	}
+	if policy.MaxDelay < policy.InitialDelay {
+		policy.MaxDelay = policy.InitialDelay
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 1
+	}
+	if policy.Jitter == nil {
+		policy.Jitter = func(d time.Duration) time.Duration { return d }
+	}
+	return policy
+}
+
+// equalJitter keeps at least half of d so polling never spins.
+func equalJitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + fullJitter(d/2)
+}
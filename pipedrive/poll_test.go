@@ -0,0 +1,48 @@
+package pipedrive
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPollUntil(t *testing.T) {
+	t.Parallel()
+
+	policy := PollPolicy{InitialDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond, Multiplier: 2}
+	calls := 0
+	got, err := PollUntil(context.Background(), policy, func(context.Context) (int, bool, error) {
+		calls++
+		return calls, calls == 3, nil
+	})
+	if err != nil || got != 3 || calls != 3 {
+		t.Fatalf("PollUntil = %d, %v after %d calls", got, err, calls)
+	}
+
+	boom := errors.New("boom")
+	if _, err := PollUntil(context.Background(), policy, func(context.Context) (int, bool, error) {
+		return 0, false, boom
+	}); !errors.Is(err, boom) {
+		t.Fatalf("expected check error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := PollUntil(ctx, policy, func(context.Context) (int, bool, error) {
+		return 0, false, nil
+	}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+
+	// A policy without an initial delay must still wait between attempts.
+	zeroCtx, zeroCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer zeroCancel()
+	calls = 0
+	if _, err := PollUntil(zeroCtx, PollPolicy{MaxDelay: time.Second}, func(context.Context) (int, bool, error) {
+		calls++
+		return 0, false, nil
+	}); !errors.Is(err, context.DeadlineExceeded) || calls != 1 {
+		t.Fatalf("expected one call before the deadline, got %d calls, %v", calls, err)
+	}
+}
@@ -0,0 +1,654 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+type ActionKind string
+
+const (
+	ActionCreateField   ActionKind = "create_field"
+	ActionUpdateField   ActionKind = "update_field"
+	ActionDeleteField   ActionKind = "delete_field"
+	ActionAddOptions    ActionKind = "add_options"
+	ActionUpdateOptions ActionKind = "update_options"
+	ActionDeleteOptions ActionKind = "delete_options"
+)
+
+// Action is a single API change. FieldCode is empty for fields that do not
+// exist yet.
+type Action struct {
+	Kind      ActionKind
+	Entity    Entity
+	FieldCode string
+	FieldName string
+
+	Field         *FieldSpec
+	Rename        string
+	Description   *string
+	AddLabels     []string
+	UpdateOptions []v2.FieldOptionUpdate
+	DeleteOptions []v2.FieldOption
+}
+
+func (a Action) Destructive() bool {
+	return a.Kind == ActionDeleteField || a.Kind == ActionDeleteOptions
+}
+
+func (a Action) String() string {
+	ref := fmt.Sprintf("%q", a.FieldName)
+	if a.FieldCode != "" {
+		ref += " [" + a.FieldCode + "]"
+	}
+	switch a.Kind {
+	case ActionCreateField:
+		s := fmt.Sprintf("+ create %s field %s (%s)", a.Entity, ref, a.Field.Type)
+		if len(a.Field.Options) > 0 {
+			labels := make([]string, 0, len(a.Field.Options))
+			for _, option := range a.Field.Options {
+				labels = append(labels, option.Label)
+			}
+			s += " with options " + quoteList(labels)
+		}
+		return s
+	case ActionUpdateField:
+		var changes []string
+		if a.Rename != "" {
+			changes = append(changes, fmt.Sprintf("name %q -> %q", a.FieldName, a.Rename))
+		}
+		if a.Description != nil {
+			changes = append(changes, fmt.Sprintf("description -> %q", *a.Description))
+		}
+		return fmt.Sprintf("~ update %s field %s: %s", a.Entity, ref, strings.Join(changes, ", "))
+	case ActionAddOptions:
+		return fmt.Sprintf("+ add options to %s field %s: %s", a.Entity, ref, quoteList(a.AddLabels))
+	case ActionUpdateOptions:
+		changes := make([]string, 0, len(a.UpdateOptions))
+		for _, update := range a.UpdateOptions {
+			changes = append(changes, fmt.Sprintf("%d -> %q", update.ID, update.Label))
+		}
+		return fmt.Sprintf("~ update options of %s field %s: %s", a.Entity, ref, strings.Join(changes, ", "))
+	case ActionDeleteOptions:
+		labels := make([]string, 0, len(a.DeleteOptions))
+		for _, option := range a.DeleteOptions {
+			labels = append(labels, fmt.Sprintf("%d %q", option.ID, option.Label))
+		}
+		return fmt.Sprintf("- delete options from %s field %s: %s (destructive)", a.Entity, ref, strings.Join(labels, ", "))
+	case ActionDeleteField:
+		return fmt.Sprintf("- delete %s field %s (destructive)", a.Entity, ref)
+	}
+	return string(a.Kind)
+}
+
+type Plan struct {
+	Actions []Action
+}
+
+func (p *Plan) HasChanges() bool {
+	return p != nil && len(p.Actions) > 0
+}
+
+func (p *Plan) Destructive() []Action {
+	if p == nil {
+		return nil
+	}
+	var out []Action
+	for _, action := range p.Actions {
+		if action.Destructive() {
+			out = append(out, action)
+		}
+	}
+	return out
+}
+
+func (p *Plan) String() string {
+	if !p.HasChanges() {
+		return "No changes.\n"
+	}
+	var b strings.Builder
+	for _, action := range p.Actions {
+		b.WriteString(action.String())
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "%d change(s), %d destructive.\n", len(p.Actions), len(p.Destructive()))
+	return b.String()
+}
+
+// ApplyOptions gates destructive actions; Apply refuses a plan containing
+// deletions that are not explicitly allowed.
+type ApplyOptions struct {
+	AllowDeleteFields    bool
+	AllowDeleteOptions   bool
+	AllowDeletePipelines bool
+	AllowDeleteStages    bool
+}
+
+// PlanFields lists the current fields of every entity in spec and diffs them
+// against it.
+func PlanFields(ctx context.Context, client *v2.Client, spec *Spec) (*Plan, error) {
+	current := make(map[Entity][]v2.Field, len(spec.Fields))
+	for _, entity := range fieldEntities {
+		if _, ok := spec.Fields[entity]; !ok {
+			continue
+		}
+		fields, err := collect(ctx, newFieldService(client, entity).listPager())
+		if err != nil {
+			return nil, fmt.Errorf("list %s fields: %w", entity, err)
+		}
+		current[entity] = fields
+	}
+	return DiffFields(spec, current)
+}
+
+// DiffFields computes the plan turning current into the state described by
+// spec. Built-in fields are ignored. Creations and updates come before
+// deletions so a failed apply never leaves data without a home.
+func DiffFields(spec *Spec, current map[Entity][]v2.Field) (*Plan, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	plan := &Plan{}
+	var deletions []Action
+	for _, entity := range fieldEntities {
+		desired, ok := spec.Fields[entity]
+		if !ok {
+			continue
+		}
+		actions, deletes, err := diffEntityFields(entity, desired, current[entity])
+		if err != nil {
+			return nil, err
+		}
+		plan.Actions = append(plan.Actions, actions...)
+		deletions = append(deletions, deletes...)
+	}
+	plan.Actions = append(plan.Actions, deletions...)
+	return plan, nil
+}
+
+func diffEntityFields(entity Entity, desired []FieldSpec, current []v2.Field) ([]Action, []Action, error) {
+	byKey := make(map[string]*v2.Field)
+	byName := make(map[string]*v2.Field)
+	for i := range current {
+		field := &current[i]
+		if !field.IsCustomField || field.FieldCode == "" {
+			continue
+		}
+		byKey[field.FieldCode] = field
+		name := normalizeName(field.FieldName)
+		if _, dup := byName[name]; dup {
+			// Duplicate names can only be managed through pinned keys.
+			byName[name] = nil
+			continue
+		}
+		byName[name] = field
+	}
+
+	matched := make(map[string]struct{})
+	var actions, deletions []Action
+	for i := range desired {
+		spec := &desired[i]
+		var existing *v2.Field
+		if spec.Key != "" {
+			field, ok := byKey[spec.Key]
+			if !ok {
+				return nil, nil, fmt.Errorf("%s field %q: key %q not found", entity, spec.Name, spec.Key)
+			}
+			existing = field
+		} else if field, ok := byName[normalizeName(spec.Name)]; ok {
+			if field == nil {
+				return nil, nil, fmt.Errorf("%s field %q matches more than one field; pin it with key", entity, spec.Name)
+			}
+			existing = field
+		}
+		if existing == nil {
+			actions = append(actions, Action{Kind: ActionCreateField, Entity: entity, FieldName: spec.Name, Field: spec})
+			continue
+		}
+		if _, dup := matched[existing.FieldCode]; dup {
+			return nil, nil, fmt.Errorf("%s field %q matches field %s already claimed by another spec", entity, spec.Name, existing.FieldCode)
+		}
+		matched[existing.FieldCode] = struct{}{}
+		if existing.FieldType != spec.Type {
+			return nil, nil, fmt.Errorf("%s field %q [%s]: type %s cannot be changed to %s", entity, existing.FieldName, existing.FieldCode, existing.FieldType, spec.Type)
+		}
+
+		update := Action{Kind: ActionUpdateField, Entity: entity, FieldCode: existing.FieldCode, FieldName: existing.FieldName}
+		if existing.FieldName != spec.Name {
+			update.Rename = spec.Name
+		}
+		if entity != EntityProject && spec.Description != "" && spec.Description != existing.Description {
+			description := spec.Description
+			update.Description = &description
+		}
+		if update.Rename != "" || update.Description != nil {
+			actions = append(actions, update)
+		}
+
+		if hasOptions(spec.Type) {
+			optionActions, optionDeletes, err := diffOptions(entity, spec, existing)
+			if err != nil {
+				return nil, nil, err
+			}
+			actions = append(actions, optionActions...)
+			deletions = append(deletions, optionDeletes...)
+		}
+	}
+
+	var unmanaged []*v2.Field
+	for code, field := range byKey {
+		if _, ok := matched[code]; !ok {
+			unmanaged = append(unmanaged, field)
+		}
+	}
+	sort.Slice(unmanaged, func(i, j int) bool { return unmanaged[i].FieldCode < unmanaged[j].FieldCode })
+	for _, field := range unmanaged {
+		deletions = append(deletions, Action{Kind: ActionDeleteField, Entity: entity, FieldCode: field.FieldCode, FieldName: field.FieldName})
+	}
+	return actions, deletions, nil
+}
+
+func diffOptions(entity Entity, spec *FieldSpec, existing *v2.Field) ([]Action, []Action, error) {
+	byID := make(map[int]v2.FieldOption, len(existing.Options))
+	byLabel := make(map[string]v2.FieldOption, len(existing.Options))
+	for _, option := range existing.Options {
+		byID[option.ID] = option
+		byLabel[normalizeName(option.Label)] = option
+	}
+
+	matched := make(map[int]struct{})
+	var add []string
+	var updates []v2.FieldOptionUpdate
+	for _, want := range spec.Options {
+		var option v2.FieldOption
+		var ok bool
+		if want.ID != 0 {
+			option, ok = byID[want.ID]
+			if !ok {
+				return nil, nil, fmt.Errorf("%s field %q: option id %d not found", entity, spec.Name, want.ID)
+			}
+		} else {
+			option, ok = byLabel[normalizeName(want.Label)]
+		}
+		if !ok {
+			add = append(add, want.Label)
+			continue
+		}
+		if _, dup := matched[option.ID]; dup {
+			return nil, nil, fmt.Errorf("%s field %q: option %d matched more than once", entity, spec.Name, option.ID)
+		}
+		matched[option.ID] = struct{}{}
+		if option.Label != want.Label {
+			updates = append(updates, v2.FieldOptionUpdate{ID: option.ID, Label: want.Label})
+		}
+	}
+
+	var deletes []v2.FieldOption
+	for _, option := range existing.Options {
+		if _, ok := matched[option.ID]; !ok {
+			deletes = append(deletes, option)
+		}
+	}
+
+	base := Action{Entity: entity, FieldCode: existing.FieldCode, FieldName: existing.FieldName}
+	var actions, deletions []Action
+	if len(updates) > 0 {
+		action := base
+		action.Kind = ActionUpdateOptions
+		action.UpdateOptions = updates
+		actions = append(actions, action)
+	}
+	if len(add) > 0 {
+		action := base
+		action.Kind = ActionAddOptions
+		action.AddLabels = add
+		actions = append(actions, action)
+	}
+	if len(deletes) > 0 {
+		action := base
+		action.Kind = ActionDeleteOptions
+		action.DeleteOptions = deletes
+		deletions = append(deletions, action)
+	}
+	return actions, deletions, nil
+}
+
+// Apply executes plan in order. It returns the actions that completed, which
+// is a prefix of plan.Actions when an error stops it midway.
+func Apply(ctx context.Context, client *v2.Client, plan *Plan, opts ApplyOptions) ([]Action, error) {
+	for _, action := range plan.Destructive() {
+		if action.Kind == ActionDeleteField && !opts.AllowDeleteFields {
+			return nil, fmt.Errorf("plan deletes fields; deleting fields must be allowed explicitly")
+		}
+		if action.Kind == ActionDeleteOptions && !opts.AllowDeleteOptions {
+			return nil, fmt.Errorf("plan deletes field options; deleting options must be allowed explicitly")
+		}
+	}
+
+	done := make([]Action, 0, len(plan.Actions))
+	for _, action := range plan.Actions {
+		if err := applyAction(ctx, newFieldService(client, action.Entity), action); err != nil {
+			return done, fmt.Errorf("%s: %w", action.String(), err)
+		}
+		done = append(done, action)
+	}
+	return done, nil
+}
+
+func applyAction(ctx context.Context, svc fieldService, action Action) error {
+	switch action.Kind {
+	case ActionCreateField:
+		return svc.create(ctx, *action.Field)
+	case ActionUpdateField:
+		return svc.update(ctx, action.FieldCode, action.Rename, action.Description)
+	case ActionAddOptions:
+		return svc.addOptions(ctx, action.FieldCode, action.AddLabels)
+	case ActionUpdateOptions:
+		return svc.updateOptions(ctx, action.FieldCode, action.UpdateOptions)
+	case ActionDeleteOptions:
+		ids := make([]int, 0, len(action.DeleteOptions))
+		for _, option := range action.DeleteOptions {
+			ids = append(ids, option.ID)
+		}
+		return svc.deleteOptions(ctx, action.FieldCode, ids)
+	case ActionDeleteField:
+		return svc.delete(ctx, action.FieldCode)
+	}
+	return fmt.Errorf("unknown action %q", action.Kind)
+}
+
+// fieldService hides the per-entity option types of the v2 field services.
+type fieldService interface {
+	listPager() *pipedrive.CursorPager[v2.Field]
+	create(ctx context.Context, spec FieldSpec) error
+	update(ctx context.Context, code, name string, description *string) error
+	addOptions(ctx context.Context, code string, labels []string) error
+	updateOptions(ctx context.Context, code string, updates []v2.FieldOptionUpdate) error
+	deleteOptions(ctx context.Context, code string, ids []int) error
+	delete(ctx context.Context, code string) error
+}
+
+func newFieldService(client *v2.Client, entity Entity) fieldService {
+	switch entity {
+	case EntityDeal:
+		return dealFieldService{client.DealFields}
+	case EntityPerson:
+		return personFieldService{client.PersonFields}
+	case EntityOrganization:
+		return organizationFieldService{client.OrganizationFields}
+	case EntityProduct:
+		return productFieldService{client.ProductFields}
+	case EntityProject:
+		return projectFieldService{client.ProjectFields}
+	}
+	panic(fmt.Sprintf("schema: unknown entity %q", entity))
+}
+
+func optionLabels(spec FieldSpec) []string {
+	labels := make([]string, 0, len(spec.Options))
+	for _, option := range spec.Options {
+		labels = append(labels, option.Label)
+	}
+	return labels
+}
+
+type dealFieldService struct{ svc *v2.DealFieldsService }
+
+func (s dealFieldService) listPager() *pipedrive.CursorPager[v2.Field] { return s.svc.ListPager() }
+
+func (s dealFieldService) create(ctx context.Context, spec FieldSpec) error {
+	opts := []v2.CreateDealFieldOption{v2.WithDealFieldName(spec.Name), v2.WithDealFieldType(spec.Type)}
+	if spec.Description != "" {
+		opts = append(opts, v2.WithDealFieldDescription(spec.Description))
+	}
+	if len(spec.Options) > 0 {
+		opts = append(opts, v2.WithDealFieldOptions(optionLabels(spec)...))
+	}
+	_, err := s.svc.Create(ctx, opts...)
+	return err
+}
+
+func (s dealFieldService) update(ctx context.Context, code, name string, description *string) error {
+	var opts []v2.UpdateDealFieldOption
+	if name != "" {
+		opts = append(opts, v2.WithDealFieldName(name))
+	}
+	if description != nil {
+		opts = append(opts, v2.WithDealFieldDescription(*description))
+	}
+	_, err := s.svc.Update(ctx, code, opts...)
+	return err
+}
+
+func (s dealFieldService) addOptions(ctx context.Context, code string, labels []string) error {
+	_, err := s.svc.AddOptions(ctx, code, labels)
+	return err
+}
+
+func (s dealFieldService) updateOptions(ctx context.Context, code string, updates []v2.FieldOptionUpdate) error {
+	_, err := s.svc.UpdateOptions(ctx, code, updates)
+	return err
+}
+
+func (s dealFieldService) deleteOptions(ctx context.Context, code string, ids []int) error {
+	_, err := s.svc.DeleteOptions(ctx, code, ids)
+	return err
+}
+
+func (s dealFieldService) delete(ctx context.Context, code string) error {
+	_, err := s.svc.Delete(ctx, code)
+	return err
+}
+
+type personFieldService struct{ svc *v2.PersonFieldsService }
+
+func (s personFieldService) listPager() *pipedrive.CursorPager[v2.Field] { return s.svc.ListPager() }
+
+func (s personFieldService) create(ctx context.Context, spec FieldSpec) error {
+	opts := []v2.CreatePersonFieldOption{v2.WithPersonFieldName(spec.Name), v2.WithPersonFieldType(spec.Type)}
+	if spec.Description != "" {
+		opts = append(opts, v2.WithPersonFieldDescription(spec.Description))
+	}
+	if len(spec.Options) > 0 {
+		opts = append(opts, v2.WithPersonFieldOptions(optionLabels(spec)...))
+	}
+	_, err := s.svc.Create(ctx, opts...)
+	return err
+}
+
+func (s personFieldService) update(ctx context.Context, code, name string, description *string) error {
+	var opts []v2.UpdatePersonFieldOption
+	if name != "" {
+		opts = append(opts, v2.WithPersonFieldName(name))
+	}
+	if description != nil {
+		opts = append(opts, v2.WithPersonFieldDescription(*description))
+	}
+	_, err := s.svc.Update(ctx, code, opts...)
+	return err
+}
+
+func (s personFieldService) addOptions(ctx context.Context, code string, labels []string) error {
+	_, err := s.svc.AddOptions(ctx, code, labels)
+	return err
+}
+
+func (s personFieldService) updateOptions(ctx context.Context, code string, updates []v2.FieldOptionUpdate) error {
+	_, err := s.svc.UpdateOptions(ctx, code, updates)
+	return err
+}
+
+func (s personFieldService) deleteOptions(ctx context.Context, code string, ids []int) error {
+	_, err := s.svc.DeleteOptions(ctx, code, ids)
+	return err
+}
+
+func (s personFieldService) delete(ctx context.Context, code string) error {
+	_, err := s.svc.Delete(ctx, code)
+	return err
+}
+
+type organizationFieldService struct {
+	svc *v2.OrganizationFieldsService
+}
+
+func (s organizationFieldService) listPager() *pipedrive.CursorPager[v2.Field] {
+	return s.svc.ListPager()
+}
+
+func (s organizationFieldService) create(ctx context.Context, spec FieldSpec) error {
+	opts := []v2.CreateOrganizationFieldOption{v2.WithOrganizationFieldName(spec.Name), v2.WithOrganizationFieldType(spec.Type)}
+	if spec.Description != "" {
+		opts = append(opts, v2.WithOrganizationFieldDescription(spec.Description))
+	}
+	if len(spec.Options) > 0 {
+		opts = append(opts, v2.WithOrganizationFieldOptions(optionLabels(spec)...))
+	}
+	_, err := s.svc.Create(ctx, opts...)
+	return err
+}
+
+func (s organizationFieldService) update(ctx context.Context, code, name string, description *string) error {
+	var opts []v2.UpdateOrganizationFieldOption
+	if name != "" {
+		opts = append(opts, v2.WithOrganizationFieldName(name))
+	}
+	if description != nil {
+		opts = append(opts, v2.WithOrganizationFieldDescription(*description))
+	}
+	_, err := s.svc.Update(ctx, code, opts...)
+	return err
+}
+
+func (s organizationFieldService) addOptions(ctx context.Context, code string, labels []string) error {
+	_, err := s.svc.AddOptions(ctx, code, labels)
+	return err
+}
+
+func (s organizationFieldService) updateOptions(ctx context.Context, code string, updates []v2.FieldOptionUpdate) error {
+	_, err := s.svc.UpdateOptions(ctx, code, updates)
+	return err
+}
+
+func (s organizationFieldService) deleteOptions(ctx context.Context, code string, ids []int) error {
+	_, err := s.svc.DeleteOptions(ctx, code, ids)
+	return err
+}
+
+func (s organizationFieldService) delete(ctx context.Context, code string) error {
+	_, err := s.svc.Delete(ctx, code)
+	return err
+}
+
+type productFieldService struct{ svc *v2.ProductFieldsService }
+
+func (s productFieldService) listPager() *pipedrive.CursorPager[v2.Field] { return s.svc.ListPager() }
+
+func (s productFieldService) create(ctx context.Context, spec FieldSpec) error {
+	opts := []v2.CreateProductFieldOption{v2.WithProductFieldName(spec.Name), v2.WithProductFieldType(spec.Type)}
+	if spec.Description != "" {
+		opts = append(opts, v2.WithProductFieldDescription(spec.Description))
+	}
+	if len(spec.Options) > 0 {
+		opts = append(opts, v2.WithProductFieldOptions(optionLabels(spec)...))
+	}
+	_, err := s.svc.Create(ctx, opts...)
+	return err
+}
+
+func (s productFieldService) update(ctx context.Context, code, name string, description *string) error {
+	var opts []v2.UpdateProductFieldOption
+	if name != "" {
+		opts = append(opts, v2.WithProductFieldName(name))
+	}
+	if description != nil {
+		opts = append(opts, v2.WithProductFieldDescription(*description))
+	}
+	_, err := s.svc.Update(ctx, code, opts...)
+	return err
+}
+
+func (s productFieldService) addOptions(ctx context.Context, code string, labels []string) error {
+	_, err := s.svc.AddOptions(ctx, code, labels)
+	return err
+}
+
+func (s productFieldService) updateOptions(ctx context.Context, code string, updates []v2.FieldOptionUpdate) error {
+	_, err := s.svc.UpdateOptions(ctx, code, updates)
+	return err
+}
+
+func (s productFieldService) deleteOptions(ctx context.Context, code string, ids []int) error {
+	_, err := s.svc.DeleteOptions(ctx, code, ids)
+	return err
+}
+
+func (s productFieldService) delete(ctx context.Context, code string) error {
+	_, err := s.svc.Delete(ctx, code)
+	return err
+}
+
+// Project fields have no description.
+type projectFieldService struct{ svc *v2.ProjectFieldsService }
+
+func (s projectFieldService) listPager() *pipedrive.CursorPager[v2.Field] { return s.svc.ListPager() }
+
+func (s projectFieldService) create(ctx context.Context, spec FieldSpec) error {
+	opts := []v2.CreateProjectFieldOption{v2.WithProjectFieldName(spec.Name), v2.WithProjectFieldType(spec.Type)}
+	if len(spec.Options) > 0 {
+		opts = append(opts, v2.WithProjectFieldOptions(optionLabels(spec)...))
+	}
+	_, err := s.svc.Create(ctx, opts...)
+	return err
+}
+
+func (s projectFieldService) update(ctx context.Context, code, name string, _ *string) error {
+	var opts []v2.UpdateProjectFieldOption
+	if name != "" {
+		opts = append(opts, v2.WithProjectFieldName(name))
+	}
+	_, err := s.svc.Update(ctx, code, opts...)
+	return err
+}
+
+func (s projectFieldService) addOptions(ctx context.Context, code string, labels []string) error {
+	_, err := s.svc.AddOptions(ctx, code, labels)
+	return err
+}
+
+func (s projectFieldService) updateOptions(ctx context.Context, code string, updates []v2.FieldOptionUpdate) error {
+	_, err := s.svc.UpdateOptions(ctx, code, updates)
+	return err
+}
+
+func (s projectFieldService) deleteOptions(ctx context.Context, code string, ids []int) error {
+	_, err := s.svc.DeleteOptions(ctx, code, ids)
+	return err
+}
+
+func (s projectFieldService) delete(ctx context.Context, code string) error {
+	_, err := s.svc.Delete(ctx, code)
+	return err
+}
+
+func collect[T any](ctx context.Context, pager *pipedrive.CursorPager[T]) ([]T, error) {
+	var out []T
+	err := pager.ForEach(ctx, func(item T) error {
+		out = append(out, item)
+		return nil
+	})
+	return out, err
+}
+
+func quoteList(items []string) string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		quoted = append(quoted, fmt.Sprintf("%q", item))
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,217 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+const testSpec = `
+fields:
+  deal:
+    - name: Region
+      type: varchar
+      description: Sales region
+    - name: Segment
+      key: h_seg
+      type: enum
+      options:
+        - SMB
+        - {id: 2, label: Enterprise}
+        - Mid-market
+`
+
+const testDealFields = `[
+	{"field_code":"title","field_name":"Title","field_type":"varchar","is_custom_field":false},
+	{"field_code":"h_seg","field_name":"Seg","field_type":"enum","is_custom_field":true,"options":[{"id":1,"label":"SMB"},{"id":2,"label":"Large"},{"id":3,"label":"Legacy"}]},
+	{"field_code":"h_old","field_name":"Old","field_type":"text","is_custom_field":true}
+]`
+
+func testCurrentFields(t *testing.T) map[Entity][]v2.Field {
+	t.Helper()
+
+	var fields []v2.Field
+	if err := json.Unmarshal([]byte(testDealFields), &fields); err != nil {
+		t.Fatalf("unmarshal fields: %v", err)
+	}
+	return map[Entity][]v2.Field{EntityDeal: fields}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	spec, err := Load([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	segment := spec.Fields[EntityDeal][1]
+	if len(segment.Options) != 3 || segment.Options[1].ID != 2 || segment.Options[1].Label != "Enterprise" || segment.Options[0].Label != "SMB" {
+		t.Fatalf("unexpected options: %#v", segment.Options)
+	}
+
+	for _, bad := range []string{
+		"fields:\n  lead:\n    - {name: X, type: varchar}\n",
+		"fields:\n  deal:\n    - {name: X, type: varchar, options: [A]}\n",
+		"fields:\n  deal:\n    - {name: X, type: varchar}\n    - {name: x, type: text}\n",
+		"fields:\n  deal:\n    - {name: X, typ: varchar}\n",
+	} {
+		if _, err := Load([]byte(bad)); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	t.Parallel()
+
+	spec, err := Load([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	plan, err := DiffFields(spec, testCurrentFields(t))
+	if err != nil {
+		t.Fatalf("DiffFields error: %v", err)
+	}
+
+	var kinds []ActionKind
+	for _, action := range plan.Actions {
+		kinds = append(kinds, action.Kind)
+	}
+	want := []ActionKind{ActionCreateField, ActionUpdateField, ActionUpdateOptions, ActionAddOptions, ActionDeleteOptions, ActionDeleteField}
+	if len(kinds) != len(want) {
+		t.Fatalf("unexpected actions:\n%s", plan)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("unexpected actions:\n%s", plan)
+		}
+	}
+	if plan.Actions[1].Rename != "Segment" {
+		t.Fatalf("unexpected rename: %#v", plan.Actions[1])
+	}
+	if u := plan.Actions[2].UpdateOptions; len(u) != 1 || u[0].ID != 2 || u[0].Label != "Enterprise" {
+		t.Fatalf("unexpected option updates: %#v", u)
+	}
+	if d := plan.Actions[4].DeleteOptions; len(d) != 1 || d[0].ID != 3 {
+		t.Fatalf("unexpected option deletes: %#v", d)
+	}
+	if plan.Actions[5].FieldCode != "h_old" {
+		t.Fatalf("unexpected field delete: %#v", plan.Actions[5])
+	}
+	if got := len(plan.Destructive()); got != 2 {
+		t.Fatalf("expected 2 destructive actions, got %d", got)
+	}
+	if !strings.Contains(plan.String(), `- delete deal field "Old" [h_old] (destructive)`) {
+		t.Fatalf("unexpected plan output:\n%s", plan)
+	}
+}
+
+func TestDiffFields_NoChanges(t *testing.T) {
+	t.Parallel()
+
+	spec, err := Load([]byte(`
+fields:
+  deal:
+    - {name: Seg, type: enum, options: [SMB, Large, Legacy]}
+    - {name: Old, type: text}
+  person: []
+`))
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	plan, err := DiffFields(spec, testCurrentFields(t))
+	if err != nil {
+		t.Fatalf("DiffFields error: %v", err)
+	}
+	if plan.HasChanges() {
+		t.Fatalf("expected no changes:\n%s", plan)
+	}
+}
+
+func TestDiffFields_TypeChange(t *testing.T) {
+	t.Parallel()
+
+	spec := &Spec{Fields: map[Entity][]FieldSpec{EntityDeal: {{Name: "Old", Type: v2.FieldTypeInt}}}}
+	if _, err := DiffFields(spec, testCurrentFields(t)); err == nil {
+		t.Fatalf("expected type change error")
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+strings.TrimSpace(string(body)))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/dealFields":
+			_, _ = w.Write([]byte(`{"data":` + testDealFields + `,"additional_data":{"next_cursor":null}}`))
+		case strings.HasSuffix(r.URL.Path, "/options"):
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"field_code":"h_new","field_name":"Region"}}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := v2.NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	spec, err := Load([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	plan, err := PlanFields(context.Background(), client, spec)
+	if err != nil {
+		t.Fatalf("PlanFields error: %v", err)
+	}
+
+	if _, err := Apply(context.Background(), client, plan, ApplyOptions{AllowDeleteOptions: true}); err == nil {
+		t.Fatalf("expected field deletion to be refused")
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected no writes before refusing, got %v", calls)
+	}
+
+	done, err := Apply(context.Background(), client, plan, ApplyOptions{AllowDeleteFields: true, AllowDeleteOptions: true})
+	if err != nil {
+		t.Fatalf("Apply error: %v", err)
+	}
+	if len(done) != len(plan.Actions) {
+		t.Fatalf("expected all actions applied, got %d", len(done))
+	}
+
+	want := []string{
+		`POST /dealFields {"description":"Sales region","field_name":"Region","field_type":"varchar"}`,
+		`PATCH /dealFields/h_seg {"field_name":"Segment"}`,
+		`PATCH /dealFields/h_seg/options [{"id":2,"label":"Enterprise"}]`,
+		`POST /dealFields/h_seg/options [{"label":"Mid-market"}]`,
+		`DELETE /dealFields/h_seg/options [{"id":3}]`,
+		`DELETE /dealFields/h_old `,
+	}
+	got := calls[1:]
+	if len(got) != len(want) {
+		t.Fatalf("unexpected calls:\n%s", strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if strings.TrimSpace(got[i]) != strings.TrimSpace(want[i]) {
+			t.Fatalf("call %d:\n got %s\nwant %s", i, got[i], want[i])
+		}
+	}
+}
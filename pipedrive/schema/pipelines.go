@@ -0,0 +1,528 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+type PipelineSpec struct {
+	Name string `yaml:"name"`
+	// ID pins the spec to an existing pipeline so it can be renamed; without
+	// it pipelines are matched by name.
+	ID              v2.PipelineID `yaml:"id,omitempty"`
+	DealProbability *bool         `yaml:"deal_probability,omitempty"`
+	Stages          []StageSpec   `yaml:"stages,omitempty"`
+}
+
+// StageSpec describes a stage; stages are listed in pipeline order. Nil
+// settings are left as they are. RottenDays of 0 disables deal rotting.
+type StageSpec struct {
+	Name        string     `yaml:"name"`
+	ID          v2.StageID `yaml:"id,omitempty"`
+	Probability *int       `yaml:"probability,omitempty"`
+	RottenDays  *int       `yaml:"rotten_days,omitempty"`
+}
+
+type PipelineActionKind string
+
+const (
+	ActionCreatePipeline PipelineActionKind = "create_pipeline"
+	ActionUpdatePipeline PipelineActionKind = "update_pipeline"
+	ActionDeletePipeline PipelineActionKind = "delete_pipeline"
+	ActionCreateStage    PipelineActionKind = "create_stage"
+	ActionUpdateStage    PipelineActionKind = "update_stage"
+	ActionDeleteStage    PipelineActionKind = "delete_stage"
+)
+
+// PipelineAction is a single pipeline or stage change. PipelineID is zero
+// for pipelines created earlier in the same plan; those are referenced by
+// PipelineName.
+type PipelineAction struct {
+	Kind         PipelineActionKind
+	PipelineID   v2.PipelineID
+	PipelineName string
+	StageID      v2.StageID
+	StageName    string
+
+	Pipeline *PipelineSpec
+	Stage    *StageSpec
+	Rename   string
+	// MoveTo is set when a pinned stage lives in another pipeline.
+	MoveTo  string
+	Changes []string
+	// AffectedDeals counts deals in a stage or pipeline planned for deletion.
+	AffectedDeals int
+}
+
+func (a PipelineAction) Destructive() bool {
+	return a.Kind == ActionDeletePipeline || a.Kind == ActionDeleteStage
+}
+
+func (a PipelineAction) String() string {
+	pipeline := fmt.Sprintf("%q", a.PipelineName)
+	if a.PipelineID != 0 {
+		pipeline += fmt.Sprintf(" [%d]", a.PipelineID)
+	}
+	stage := fmt.Sprintf("%q", a.StageName)
+	if a.StageID != 0 {
+		stage += fmt.Sprintf(" [%d]", a.StageID)
+	}
+	changes := ""
+	if len(a.Changes) > 0 {
+		changes = ": " + strings.Join(a.Changes, ", ")
+	}
+	switch a.Kind {
+	case ActionCreatePipeline:
+		return fmt.Sprintf("+ create pipeline %s%s", pipeline, changes)
+	case ActionUpdatePipeline:
+		return fmt.Sprintf("~ update pipeline %s%s", pipeline, changes)
+	case ActionDeletePipeline:
+		return fmt.Sprintf("- delete pipeline %s (destructive, %d deal(s) affected)", pipeline, a.AffectedDeals)
+	case ActionCreateStage:
+		return fmt.Sprintf("+ create stage %s in pipeline %s%s", stage, pipeline, changes)
+	case ActionUpdateStage:
+		return fmt.Sprintf("~ update stage %s in pipeline %s%s", stage, pipeline, changes)
+	case ActionDeleteStage:
+		return fmt.Sprintf("- delete stage %s in pipeline %s (destructive, %d deal(s) affected)", stage, pipeline, a.AffectedDeals)
+	}
+	return string(a.Kind)
+}
+
+type PipelinePlan struct {
+	Actions []PipelineAction
+	// Warnings report drift the v2 API cannot correct, such as stage order.
+	Warnings []string
+}
+
+func (p *PipelinePlan) HasChanges() bool {
+	return p != nil && len(p.Actions) > 0
+}
+
+func (p *PipelinePlan) Destructive() []PipelineAction {
+	if p == nil {
+		return nil
+	}
+	var out []PipelineAction
+	for _, action := range p.Actions {
+		if action.Destructive() {
+			out = append(out, action)
+		}
+	}
+	return out
+}
+
+func (p *PipelinePlan) String() string {
+	var b strings.Builder
+	if !p.HasChanges() {
+		b.WriteString("No pipeline changes.\n")
+	} else {
+		for _, action := range p.Actions {
+			b.WriteString(action.String())
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%d pipeline change(s), %d destructive.\n", len(p.Actions), len(p.Destructive()))
+	}
+	if p != nil {
+		for _, warning := range p.Warnings {
+			fmt.Fprintf(&b, "! %s\n", warning)
+		}
+	}
+	return b.String()
+}
+
+func (s *Spec) validatePipelines() error {
+	names := make(map[string]struct{}, len(s.Pipelines))
+	ids := make(map[v2.PipelineID]struct{}, len(s.Pipelines))
+	stageIDs := make(map[v2.StageID]struct{})
+	for _, pipeline := range s.Pipelines {
+		name := normalizeName(pipeline.Name)
+		if name == "" {
+			return fmt.Errorf("pipeline without name")
+		}
+		if _, dup := names[name]; dup {
+			return fmt.Errorf("pipeline %q declared more than once", pipeline.Name)
+		}
+		names[name] = struct{}{}
+		if pipeline.ID != 0 {
+			if _, dup := ids[pipeline.ID]; dup {
+				return fmt.Errorf("pipeline id %d declared more than once", pipeline.ID)
+			}
+			ids[pipeline.ID] = struct{}{}
+		}
+		stageNames := make(map[string]struct{}, len(pipeline.Stages))
+		for _, stage := range pipeline.Stages {
+			stageName := normalizeName(stage.Name)
+			if stageName == "" {
+				return fmt.Errorf("pipeline %q has a stage without name", pipeline.Name)
+			}
+			if _, dup := stageNames[stageName]; dup {
+				return fmt.Errorf("pipeline %q stage %q declared more than once", pipeline.Name, stage.Name)
+			}
+			stageNames[stageName] = struct{}{}
+			if stage.ID != 0 {
+				if _, dup := stageIDs[stage.ID]; dup {
+					return fmt.Errorf("stage id %d declared more than once", stage.ID)
+				}
+				stageIDs[stage.ID] = struct{}{}
+			}
+			if stage.Probability != nil && (*stage.Probability < 0 || *stage.Probability > 100) {
+				return fmt.Errorf("pipeline %q stage %q: probability must be between 0 and 100", pipeline.Name, stage.Name)
+			}
+			if stage.RottenDays != nil && *stage.RottenDays < 0 {
+				return fmt.Errorf("pipeline %q stage %q: rotten_days must not be negative", pipeline.Name, stage.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// PlanPipelines lists pipelines and stages, diffs them against spec and
+// counts the deals in stages and pipelines planned for deletion. It does
+// nothing when spec has no pipelines.
+func PlanPipelines(ctx context.Context, client *v2.Client, spec *Spec) (*PipelinePlan, error) {
+	if len(spec.Pipelines) == 0 {
+		return &PipelinePlan{}, nil
+	}
+	pipelines, err := collect(ctx, client.Pipelines.ListPager())
+	if err != nil {
+		return nil, fmt.Errorf("list pipelines: %w", err)
+	}
+	stages, err := collect(ctx, client.Stages.ListPager())
+	if err != nil {
+		return nil, fmt.Errorf("list stages: %w", err)
+	}
+	plan, err := DiffPipelines(spec, pipelines, stages)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plan.Actions {
+		action := &plan.Actions[i]
+		var opts []v2.ListDealsOption
+		switch action.Kind {
+		case ActionDeleteStage:
+			opts = append(opts, v2.WithDealsStageID(action.StageID))
+		case ActionDeletePipeline:
+			opts = append(opts, v2.WithDealsPipelineID(action.PipelineID))
+		default:
+			continue
+		}
+		opts = append(opts, v2.WithDealsPageSize(500))
+		count := 0
+		if err := client.Deals.ForEach(ctx, func(v2.Deal) error {
+			count++
+			return nil
+		}, opts...); err != nil {
+			return nil, fmt.Errorf("count deals: %w", err)
+		}
+		action.AffectedDeals = count
+	}
+	return plan, nil
+}
+
+// DiffPipelines computes the pipeline and stage changes turning the current
+// state into spec. Pipelines and stages missing from spec are planned for
+// deletion, after all creations and updates.
+func DiffPipelines(spec *Spec, pipelines []v2.Pipeline, stages []v2.Stage) (*PipelinePlan, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	plan := &PipelinePlan{}
+	if len(spec.Pipelines) == 0 {
+		return plan, nil
+	}
+
+	pipelineByID := make(map[v2.PipelineID]*v2.Pipeline)
+	pipelineByName := make(map[string]*v2.Pipeline)
+	for i := range pipelines {
+		pipeline := &pipelines[i]
+		if pipeline.IsDeleted {
+			continue
+		}
+		pipelineByID[pipeline.ID] = pipeline
+		name := normalizeName(pipeline.Name)
+		if _, dup := pipelineByName[name]; dup {
+			pipelineByName[name] = nil
+			continue
+		}
+		pipelineByName[name] = pipeline
+	}
+	stageByID := make(map[v2.StageID]*v2.Stage)
+	stagesByPipeline := make(map[v2.PipelineID][]*v2.Stage)
+	for i := range stages {
+		stage := &stages[i]
+		if stage.IsDeleted {
+			continue
+		}
+		stageByID[stage.ID] = stage
+		stagesByPipeline[stage.PipelineID] = append(stagesByPipeline[stage.PipelineID], stage)
+	}
+	for _, list := range stagesByPipeline {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Order < list[j].Order })
+	}
+
+	matchedPipelines := make(map[v2.PipelineID]struct{})
+	matchedStages := make(map[v2.StageID]struct{})
+	var deletions []PipelineAction
+	for i := range spec.Pipelines {
+		want := &spec.Pipelines[i]
+		var existing *v2.Pipeline
+		if want.ID != 0 {
+			pipeline, ok := pipelineByID[want.ID]
+			if !ok {
+				return nil, fmt.Errorf("pipeline %q: id %d not found", want.Name, want.ID)
+			}
+			existing = pipeline
+		} else if pipeline, ok := pipelineByName[normalizeName(want.Name)]; ok {
+			if pipeline == nil {
+				return nil, fmt.Errorf("pipeline %q matches more than one pipeline; pin it with id", want.Name)
+			}
+			existing = pipeline
+		}
+
+		if existing == nil {
+			action := PipelineAction{Kind: ActionCreatePipeline, PipelineName: want.Name, Pipeline: want}
+			if want.DealProbability != nil {
+				action.Changes = append(action.Changes, fmt.Sprintf("deal probability %t", *want.DealProbability))
+			}
+			plan.Actions = append(plan.Actions, action)
+			for j := range want.Stages {
+				plan.Actions = append(plan.Actions, createStageAction(0, want.Name, &want.Stages[j]))
+			}
+			continue
+		}
+		if _, dup := matchedPipelines[existing.ID]; dup {
+			return nil, fmt.Errorf("pipeline %q matches pipeline %d already claimed by another spec", want.Name, existing.ID)
+		}
+		matchedPipelines[existing.ID] = struct{}{}
+
+		update := PipelineAction{Kind: ActionUpdatePipeline, PipelineID: existing.ID, PipelineName: existing.Name, Pipeline: want}
+		if existing.Name != want.Name {
+			update.Rename = want.Name
+			update.Changes = append(update.Changes, fmt.Sprintf("name %q -> %q", existing.Name, want.Name))
+		}
+		if want.DealProbability != nil && *want.DealProbability != existing.DealProbabilityEnabled {
+			update.Changes = append(update.Changes, fmt.Sprintf("deal probability %t -> %t", existing.DealProbabilityEnabled, *want.DealProbability))
+		}
+		if len(update.Changes) > 0 {
+			plan.Actions = append(plan.Actions, update)
+		}
+
+		byName := make(map[string]*v2.Stage)
+		for _, stage := range stagesByPipeline[existing.ID] {
+			name := normalizeName(stage.Name)
+			if _, dup := byName[name]; dup {
+				byName[name] = nil
+				continue
+			}
+			byName[name] = stage
+		}
+
+		// The v2 API cannot reorder stages: new and moved stages are appended
+		// after the existing ones, so order drift is only reported.
+		type keptStage struct {
+			order int
+			name  string
+		}
+		var kept []keptStage
+		var appended, desired []string
+		for j := range want.Stages {
+			wantStage := &want.Stages[j]
+			desired = append(desired, wantStage.Name)
+			var stage *v2.Stage
+			if wantStage.ID != 0 {
+				found, ok := stageByID[wantStage.ID]
+				if !ok {
+					return nil, fmt.Errorf("pipeline %q stage %q: id %d not found", want.Name, wantStage.Name, wantStage.ID)
+				}
+				stage = found
+			} else if found, ok := byName[normalizeName(wantStage.Name)]; ok {
+				if found == nil {
+					return nil, fmt.Errorf("pipeline %q stage %q matches more than one stage; pin it with id", want.Name, wantStage.Name)
+				}
+				stage = found
+			}
+			if stage == nil {
+				plan.Actions = append(plan.Actions, createStageAction(existing.ID, existing.Name, wantStage))
+				appended = append(appended, wantStage.Name)
+				continue
+			}
+			if _, dup := matchedStages[stage.ID]; dup {
+				return nil, fmt.Errorf("pipeline %q stage %q matches stage %d already claimed by another spec", want.Name, wantStage.Name, stage.ID)
+			}
+			matchedStages[stage.ID] = struct{}{}
+			if stage.PipelineID == existing.ID {
+				kept = append(kept, keptStage{order: stage.Order, name: wantStage.Name})
+			} else {
+				appended = append(appended, wantStage.Name)
+			}
+			if action, ok := updateStageAction(existing, stage, wantStage); ok {
+				plan.Actions = append(plan.Actions, action)
+			}
+		}
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].order < kept[j].order })
+		resulting := make([]string, 0, len(desired))
+		for _, stage := range kept {
+			resulting = append(resulting, stage.name)
+		}
+		resulting = append(resulting, appended...)
+		if strings.Join(resulting, "\x00") != strings.Join(desired, "\x00") {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("pipeline %q stage order will be %s instead of %s; reorder stages in Pipedrive", want.Name, quoteList(resulting), quoteList(desired)))
+		}
+	}
+
+	for i := range pipelines {
+		pipeline := &pipelines[i]
+		if pipeline.IsDeleted {
+			continue
+		}
+		if _, ok := matchedPipelines[pipeline.ID]; !ok {
+			deletions = append(deletions, PipelineAction{Kind: ActionDeletePipeline, PipelineID: pipeline.ID, PipelineName: pipeline.Name})
+			continue
+		}
+		for _, stage := range stagesByPipeline[pipeline.ID] {
+			if _, ok := matchedStages[stage.ID]; !ok {
+				deletions = append(deletions, PipelineAction{Kind: ActionDeleteStage, PipelineID: pipeline.ID, PipelineName: pipeline.Name, StageID: stage.ID, StageName: stage.Name})
+			}
+		}
+	}
+	plan.Actions = append(plan.Actions, deletions...)
+	return plan, nil
+}
+
+func createStageAction(pipelineID v2.PipelineID, pipelineName string, want *StageSpec) PipelineAction {
+	action := PipelineAction{Kind: ActionCreateStage, PipelineID: pipelineID, PipelineName: pipelineName, StageName: want.Name, Stage: want}
+	if want.Probability != nil {
+		action.Changes = append(action.Changes, fmt.Sprintf("probability %d", *want.Probability))
+	}
+	if want.RottenDays != nil && *want.RottenDays > 0 {
+		action.Changes = append(action.Changes, fmt.Sprintf("rotten after %d day(s)", *want.RottenDays))
+	}
+	return action
+}
+
+func updateStageAction(pipeline *v2.Pipeline, stage *v2.Stage, want *StageSpec) (PipelineAction, bool) {
+	action := PipelineAction{Kind: ActionUpdateStage, PipelineID: pipeline.ID, PipelineName: pipeline.Name, StageID: stage.ID, StageName: stage.Name, Stage: want}
+	if stage.PipelineID != pipeline.ID {
+		action.MoveTo = pipeline.Name
+		action.Changes = append(action.Changes, fmt.Sprintf("move from pipeline %d", stage.PipelineID))
+	}
+	if stage.Name != want.Name {
+		action.Rename = want.Name
+		action.Changes = append(action.Changes, fmt.Sprintf("name %q -> %q", stage.Name, want.Name))
+	}
+	if want.Probability != nil && *want.Probability != stage.DealProbability {
+		action.Changes = append(action.Changes, fmt.Sprintf("probability %d -> %d", stage.DealProbability, *want.Probability))
+	}
+	if want.RottenDays != nil {
+		days := *want.RottenDays
+		switch {
+		case days == 0 && stage.DealRotEnabled:
+			action.Changes = append(action.Changes, "disable rotting")
+		case days > 0 && (!stage.DealRotEnabled || stage.DaysToRotten == nil || *stage.DaysToRotten != days):
+			action.Changes = append(action.Changes, fmt.Sprintf("rotten after %d day(s)", days))
+		}
+	}
+	return action, len(action.Changes) > 0
+}
+
+// ApplyPipelines executes plan in order and returns the completed actions.
+// Stages of pipelines created by the plan are attached to the new pipeline.
+func ApplyPipelines(ctx context.Context, client *v2.Client, plan *PipelinePlan, opts ApplyOptions) ([]PipelineAction, error) {
+	for _, action := range plan.Destructive() {
+		if action.Kind == ActionDeletePipeline && !opts.AllowDeletePipelines {
+			return nil, fmt.Errorf("plan deletes pipelines; deleting pipelines must be allowed explicitly")
+		}
+		if action.Kind == ActionDeleteStage && !opts.AllowDeleteStages {
+			return nil, fmt.Errorf("plan deletes stages; deleting stages must be allowed explicitly")
+		}
+	}
+
+	created := make(map[string]v2.PipelineID)
+	pipelineID := func(action PipelineAction) (v2.PipelineID, error) {
+		if action.PipelineID != 0 {
+			return action.PipelineID, nil
+		}
+		id, ok := created[action.PipelineName]
+		if !ok {
+			return 0, fmt.Errorf("pipeline %q was not created", action.PipelineName)
+		}
+		return id, nil
+	}
+
+	done := make([]PipelineAction, 0, len(plan.Actions))
+	for _, action := range plan.Actions {
+		var err error
+		switch action.Kind {
+		case ActionCreatePipeline:
+			opts := []v2.CreatePipelineOption{v2.WithPipelineName(action.Pipeline.Name)}
+			if action.Pipeline.DealProbability != nil {
+				opts = append(opts, v2.WithPipelineDealProbabilityEnabled(*action.Pipeline.DealProbability))
+			}
+			var pipeline *v2.Pipeline
+			pipeline, err = client.Pipelines.Create(ctx, opts...)
+			if err == nil {
+				created[action.PipelineName] = pipeline.ID
+			}
+		case ActionUpdatePipeline:
+			var opts []v2.UpdatePipelineOption
+			if action.Rename != "" {
+				opts = append(opts, v2.WithPipelineName(action.Rename))
+			}
+			if action.Pipeline.DealProbability != nil {
+				opts = append(opts, v2.WithPipelineDealProbabilityEnabled(*action.Pipeline.DealProbability))
+			}
+			_, err = client.Pipelines.Update(ctx, action.PipelineID, opts...)
+		case ActionCreateStage:
+			var id v2.PipelineID
+			if id, err = pipelineID(action); err == nil {
+				opts := []v2.CreateStageOption{v2.WithStageName(action.Stage.Name), v2.WithStagePipelineID(id)}
+				for _, opt := range stageSettings(action.Stage) {
+					opts = append(opts, opt)
+				}
+				_, err = client.Stages.Create(ctx, opts...)
+			}
+		case ActionUpdateStage:
+			var opts []v2.UpdateStageOption
+			if action.MoveTo != "" {
+				opts = append(opts, v2.WithStagePipelineID(action.PipelineID))
+			}
+			if action.Rename != "" {
+				opts = append(opts, v2.WithStageName(action.Rename))
+			}
+			for _, opt := range stageSettings(action.Stage) {
+				opts = append(opts, opt)
+			}
+			_, err = client.Stages.Update(ctx, action.StageID, opts...)
+		case ActionDeleteStage:
+			_, err = client.Stages.Delete(ctx, action.StageID)
+		case ActionDeletePipeline:
+			_, err = client.Pipelines.Delete(ctx, action.PipelineID)
+		default:
+			err = fmt.Errorf("unknown action %q", action.Kind)
+		}
+		if err != nil {
+			return done, fmt.Errorf("%s: %w", action.String(), err)
+		}
+		done = append(done, action)
+	}
+	return done, nil
+}
+
+func stageSettings(want *StageSpec) []v2.StageOption {
+	var opts []v2.StageOption
+	if want.Probability != nil {
+		opts = append(opts, v2.WithStageDealProbability(*want.Probability))
+	}
+	if want.RottenDays != nil {
+		if *want.RottenDays > 0 {
+			opts = append(opts, v2.WithStageDealRotEnabled(true), v2.WithStageDaysToRotten(*want.RottenDays))
+		} else {
+			opts = append(opts, v2.WithStageDealRotEnabled(false))
+		}
+	}
+	return opts
+}
@@ -0,0 +1,253 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+const testPipelineSpec = `
+pipelines:
+  - name: Sales
+    deal_probability: true
+    stages:
+      - {name: Qualified, probability: 20}
+      - {name: Proposal, probability: 60, rotten_days: 14}
+      - {name: Negotiation, probability: 80}
+  - name: Partners
+    stages:
+      - {name: Intro}
+`
+
+// fakePipedrive is a minimal in-memory stand-in for the v2 pipeline, stage
+// and deal endpoints.
+type fakePipedrive struct {
+	mu        sync.Mutex
+	nextID    int64
+	pipelines map[int64]map[string]interface{}
+	stages    map[int64]map[string]interface{}
+	deals     []map[string]interface{}
+	writes    int
+}
+
+func newFakePipedrive() *fakePipedrive {
+	f := &fakePipedrive{nextID: 100, pipelines: map[int64]map[string]interface{}{}, stages: map[int64]map[string]interface{}{}}
+	f.pipelines[1] = map[string]interface{}{"id": 1, "name": "Sales", "is_deal_probability_enabled": false}
+	f.pipelines[2] = map[string]interface{}{"id": 2, "name": "Legacy", "is_deal_probability_enabled": false}
+	f.stages[10] = map[string]interface{}{"id": 10, "pipeline_id": 1, "order_nr": 1, "name": "Qualified", "deal_probability": 20}
+	f.stages[11] = map[string]interface{}{"id": 11, "pipeline_id": 1, "order_nr": 2, "name": "Demo", "deal_probability": 40}
+	f.stages[12] = map[string]interface{}{"id": 12, "pipeline_id": 1, "order_nr": 3, "name": "Proposal", "deal_probability": 50}
+	f.stages[20] = map[string]interface{}{"id": 20, "pipeline_id": 2, "order_nr": 1, "name": "Old", "deal_probability": 100}
+	f.deals = []map[string]interface{}{
+		{"id": 1, "stage_id": 11, "pipeline_id": 1},
+		{"id": 2, "stage_id": 11, "pipeline_id": 1},
+		{"id": 3, "stage_id": 20, "pipeline_id": 2},
+	}
+	return f
+}
+
+func (f *fakePipedrive) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	var store map[int64]map[string]interface{}
+	switch parts[0] {
+	case "pipelines":
+		store = f.pipelines
+	case "stages":
+		store = f.stages
+	case "deals":
+		var out []map[string]interface{}
+		for _, deal := range f.deals {
+			if id := r.URL.Query().Get("stage_id"); id != "" && strconv.Itoa(deal["stage_id"].(int)) != id {
+				continue
+			}
+			if id := r.URL.Query().Get("pipeline_id"); id != "" && strconv.Itoa(deal["pipeline_id"].(int)) != id {
+				continue
+			}
+			out = append(out, deal)
+		}
+		writeData(w, out, true)
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		var out []map[string]interface{}
+		for _, item := range store {
+			out = append(out, item)
+		}
+		writeData(w, out, true)
+		return
+	}
+
+	f.writes++
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	switch r.Method {
+	case http.MethodPost:
+		f.nextID++
+		item := map[string]interface{}{"id": f.nextID}
+		if parts[0] == "stages" {
+			count := 0
+			for _, stage := range f.stages {
+				if stage["pipeline_id"] == int(body["pipeline_id"].(float64)) {
+					count++
+				}
+			}
+			item["order_nr"] = count + 1
+		}
+		store[f.nextID] = item
+		mergeBody(item, body)
+		writeData(w, item, false)
+	case http.MethodPatch:
+		id, _ := strconv.ParseInt(parts[1], 10, 64)
+		item := store[id]
+		mergeBody(item, body)
+		writeData(w, item, false)
+	case http.MethodDelete:
+		id, _ := strconv.ParseInt(parts[1], 10, 64)
+		delete(store, id)
+		if parts[0] == "pipelines" {
+			for stageID, stage := range f.stages {
+				if stage["pipeline_id"] == int(id) {
+					delete(f.stages, stageID)
+				}
+			}
+		}
+		writeData(w, map[string]interface{}{"id": id}, false)
+	}
+}
+
+func mergeBody(item, body map[string]interface{}) {
+	for key, value := range body {
+		if n, ok := value.(float64); ok {
+			value = int(n)
+		}
+		item[key] = value
+	}
+}
+
+func writeData(w http.ResponseWriter, data interface{}, list bool) {
+	payload := map[string]interface{}{"data": data}
+	if list {
+		payload["additional_data"] = map[string]interface{}{"next_cursor": nil}
+	}
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func newFakeClient(t *testing.T, fake *fakePipedrive) *v2.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	client, err := v2.NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	return client
+}
+
+func TestPlanPipelines(t *testing.T) {
+	t.Parallel()
+
+	spec, err := Load([]byte(testPipelineSpec))
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	client := newFakeClient(t, newFakePipedrive())
+	plan, err := PlanPipelines(context.Background(), client, spec)
+	if err != nil {
+		t.Fatalf("PlanPipelines error: %v", err)
+	}
+
+	out := plan.String()
+	for _, want := range []string{
+		`~ update pipeline "Sales" [1]: deal probability false -> true`,
+		`~ update stage "Proposal" [12] in pipeline "Sales" [1]: probability 50 -> 60, rotten after 14 day(s)`,
+		`+ create stage "Negotiation" in pipeline "Sales" [1]: probability 80`,
+		`+ create pipeline "Partners"`,
+		`+ create stage "Intro" in pipeline "Partners"`,
+		`- delete stage "Demo" [11] in pipeline "Sales" [1] (destructive, 2 deal(s) affected)`,
+		`- delete pipeline "Legacy" [2] (destructive, 1 deal(s) affected)`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("plan missing %q:\n%s", want, out)
+		}
+	}
+	if len(plan.Warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", plan.Warnings)
+	}
+}
+
+func TestApplyPipelines_Idempotent(t *testing.T) {
+	t.Parallel()
+
+	spec, err := Load([]byte(testPipelineSpec))
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	fake := newFakePipedrive()
+	client := newFakeClient(t, fake)
+	ctx := context.Background()
+
+	plan, err := PlanPipelines(ctx, client, spec)
+	if err != nil {
+		t.Fatalf("PlanPipelines error: %v", err)
+	}
+	if _, err := ApplyPipelines(ctx, client, plan, ApplyOptions{AllowDeleteStages: true}); err == nil {
+		t.Fatalf("expected pipeline deletion to be refused")
+	}
+	if fake.writes != 0 {
+		t.Fatalf("expected no writes before refusing, got %d", fake.writes)
+	}
+
+	done, err := ApplyPipelines(ctx, client, plan, ApplyOptions{AllowDeleteStages: true, AllowDeletePipelines: true})
+	if err != nil {
+		t.Fatalf("ApplyPipelines error: %v", err)
+	}
+	if len(done) != len(plan.Actions) {
+		t.Fatalf("expected %d actions, got %d", len(plan.Actions), len(done))
+	}
+
+	again, err := PlanPipelines(ctx, client, spec)
+	if err != nil {
+		t.Fatalf("PlanPipelines error: %v", err)
+	}
+	if again.HasChanges() || len(again.Warnings) != 0 {
+		t.Fatalf("expected converged state, got:\n%s", again)
+	}
+}
+
+func TestDiffPipelines_OrderWarning(t *testing.T) {
+	t.Parallel()
+
+	spec := &Spec{Pipelines: []PipelineSpec{{
+		Name:   "Sales",
+		Stages: []StageSpec{{Name: "B"}, {Name: "A"}},
+	}}}
+	pipelines := []v2.Pipeline{{ID: 1, Name: "Sales"}}
+	stages := []v2.Stage{
+		{ID: 1, PipelineID: 1, Order: 1, Name: "A"},
+		{ID: 2, PipelineID: 1, Order: 2, Name: "B"},
+	}
+	plan, err := DiffPipelines(spec, pipelines, stages)
+	if err != nil {
+		t.Fatalf("DiffPipelines error: %v", err)
+	}
+	if plan.HasChanges() || len(plan.Warnings) != 1 {
+		t.Fatalf("expected only an order warning, got:\n%s", plan)
+	}
+}
@@ -0,0 +1,145 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type Entity string
+
+const (
+	EntityDeal         Entity = "deal"
+	EntityPerson       Entity = "person"
+	EntityOrganization Entity = "organization"
+	EntityProduct      Entity = "product"
+	EntityProject      Entity = "project"
+)
+
+var fieldEntities = []Entity{
+	EntityDeal,
+	EntityPerson,
+	EntityOrganization,
+	EntityProduct,
+	EntityProject,
+}
+
+// Spec is the desired state. Entities missing from Fields are not managed;
+// for listed entities, custom fields absent from the spec are planned for
+// deletion. Likewise pipelines are only managed when Pipelines is not empty.
+type Spec struct {
+	Fields    map[Entity][]FieldSpec `yaml:"fields,omitempty"`
+	Pipelines []PipelineSpec         `yaml:"pipelines,omitempty"`
+}
+
+type FieldSpec struct {
+	Name string `yaml:"name"`
+	// Key pins the spec to an existing field hash key so the field can be
+	// renamed; without it fields are matched by name.
+	Key         string       `yaml:"key,omitempty"`
+	Type        v2.FieldType `yaml:"type"`
+	Description string       `yaml:"description,omitempty"`
+	Options     []OptionSpec `yaml:"options,omitempty"`
+}
+
+// OptionSpec is written either as a plain label or as a mapping with id and
+// label; the id pins an existing option so its label can be changed.
+type OptionSpec struct {
+	ID    int    `yaml:"id,omitempty"`
+	Label string `yaml:"label"`
+}
+
+func (o *OptionSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		o.ID = 0
+		o.Label = node.Value
+		return nil
+	}
+	type alias OptionSpec
+	var out alias
+	if err := node.Decode(&out); err != nil {
+		return err
+	}
+	*o = OptionSpec(out)
+	return nil
+}
+
+func Load(data []byte) (*Spec, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var spec Spec
+	if err := dec.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+func (s *Spec) Validate() error {
+	if err := s.validatePipelines(); err != nil {
+		return err
+	}
+	for entity, fields := range s.Fields {
+		if !isFieldEntity(entity) {
+			return fmt.Errorf("unknown entity %q", entity)
+		}
+		names := make(map[string]struct{}, len(fields))
+		keys := make(map[string]struct{}, len(fields))
+		for _, field := range fields {
+			name := normalizeName(field.Name)
+			if name == "" {
+				return fmt.Errorf("%s field without name", entity)
+			}
+			if _, dup := names[name]; dup {
+				return fmt.Errorf("%s field %q declared more than once", entity, field.Name)
+			}
+			names[name] = struct{}{}
+			if field.Key != "" {
+				if _, dup := keys[field.Key]; dup {
+					return fmt.Errorf("%s field key %q declared more than once", entity, field.Key)
+				}
+				keys[field.Key] = struct{}{}
+			}
+			if field.Type == "" {
+				return fmt.Errorf("%s field %q has no type", entity, field.Name)
+			}
+			if len(field.Options) > 0 && !hasOptions(field.Type) {
+				return fmt.Errorf("%s field %q of type %s cannot have options", entity, field.Name, field.Type)
+			}
+			labels := make(map[string]struct{}, len(field.Options))
+			for _, option := range field.Options {
+				label := normalizeName(option.Label)
+				if label == "" {
+					return fmt.Errorf("%s field %q has an option without label", entity, field.Name)
+				}
+				if _, dup := labels[label]; dup {
+					return fmt.Errorf("%s field %q option %q declared more than once", entity, field.Name, option.Label)
+				}
+				labels[label] = struct{}{}
+			}
+		}
+	}
+	return nil
+}
+
+func isFieldEntity(entity Entity) bool {
+	for _, known := range fieldEntities {
+		if known == entity {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOptions(fieldType v2.FieldType) bool {
+	return fieldType == v2.FieldTypeEnum || fieldType == v2.FieldTypeSet
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,425 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+type DealsTimelineInterval string
+
+const (
+	DealsTimelineDay     DealsTimelineInterval = "day"
+	DealsTimelineWeek    DealsTimelineInterval = "week"
+	DealsTimelineMonth   DealsTimelineInterval = "month"
+	DealsTimelineQuarter DealsTimelineInterval = "quarter"
+)
+
+// DealsTimelinePeriod is one interval of the deals timeline. Totals map
+// currency codes to amounts.
+type DealsTimelinePeriod struct {
+	PeriodStart *DateTime            `json:"period_start,omitempty"`
+	PeriodEnd   *DateTime            `json:"period_end,omitempty"`
+	Deals       []Deal               `json:"deals,omitempty"`
+	Totals      *DealsTimelineTotals `json:"totals,omitempty"`
+}
+
+type DealsTimelineTotals struct {
+	Count              int                `json:"count,omitempty"`
+	Values             map[string]float64 `json:"values,omitempty"`
+	WeightedValues     map[string]float64 `json:"weighted_values,omitempty"`
+	OpenCount          int                `json:"open_count,omitempty"`
+	OpenValues         map[string]float64 `json:"open_values,omitempty"`
+	WeightedOpenValues map[string]float64 `json:"weighted_open_values,omitempty"`
+	WonCount           int                `json:"won_count,omitempty"`
+	WonValues          map[string]float64 `json:"won_values,omitempty"`
+}
+
+// UnmarshalJSON reduces the related objects of the timeline deals, such as
+// user_id, to their IDs.
+func (p *DealsTimelinePeriod) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		PeriodStart *DateTime            `json:"period_start"`
+		PeriodEnd   *DateTime            `json:"period_end"`
+		Deals       []json.RawMessage    `json:"deals"`
+		Totals      *DealsTimelineTotals `json:"totals"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = DealsTimelinePeriod{PeriodStart: raw.PeriodStart, PeriodEnd: raw.PeriodEnd, Totals: raw.Totals}
+	for _, item := range raw.Deals {
+		flat, err := flattenRelatedIDs(item)
+		if err != nil {
+			return err
+		}
+		var deal Deal
+		if err := json.Unmarshal(flat, &deal); err != nil {
+			return err
+		}
+		p.Deals = append(p.Deals, deal)
+	}
+	return nil
+}
+
+// WithDealsTimelineWindow sets the required timeline parameters: amount
+// intervals starting at start, grouping deals by the date field fieldKey,
+// such as "expected_close_date".
+func WithDealsTimelineWindow(start time.Time, interval DealsTimelineInterval, amount int, fieldKey string) DealsOption {
+	return WithDealsQuery(url.Values{
+		"start_date": {start.Format("2006-01-02")},
+		"interval":   {string(interval)},
+		"amount":     {strconv.Itoa(amount)},
+		"field_key":  {fieldKey},
+	})
+}
+
+// TimelinePeriods is Timeline decoded into periods.
+func (s *DealsService) TimelinePeriods(ctx context.Context, opts ...DealsOption) ([]DealsTimelinePeriod, error) {
+	return s.timelinePeriods(ctx, "/deals/timeline", opts)
+}
+
+func (s *DealsService) ArchivedTimelinePeriods(ctx context.Context, opts ...DealsOption) ([]DealsTimelinePeriod, error) {
+	return s.timelinePeriods(ctx, "/deals/timeline/archived", opts)
+}
+
+func (s *DealsService) timelinePeriods(ctx context.Context, path string, opts []DealsOption) ([]DealsTimelinePeriod, error) {
+	cfg := newDealsOptions(opts)
+
+	var payload struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := s.client.Raw.Do(ctx, http.MethodGet, path, cfg.query, nil, &payload, cfg.requestOptions...); err != nil {
+		return nil, err
+	}
+	data := bytes.TrimSpace(payload.Data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+	// The API returns the periods as an array; older responses wrapped them
+	// in an object.
+	var periods []DealsTimelinePeriod
+	if data[0] == '{' {
+		var wrapped struct {
+			Periods []DealsTimelinePeriod `json:"periods"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("decode deals timeline: %w", err)
+		}
+		return wrapped.Periods, nil
+	}
+	if err := json.Unmarshal(data, &periods); err != nil {
+		return nil, fmt.Errorf("decode deals timeline: %w", err)
+	}
+	return periods, nil
+}
+
+// ChangelogValue is an old or new value of a logged change. Pipedrive logs
+// values of every field type, so the value is kept as returned and read
+// through the typed accessors.
+type ChangelogValue struct {
+	raw json.RawMessage
+}
+
+func (v *ChangelogValue) UnmarshalJSON(data []byte) error {
+	if v == nil {
+		return fmt.Errorf("v1.ChangelogValue: UnmarshalJSON on nil receiver")
+	}
+	v.raw = append(json.RawMessage(nil), bytes.TrimSpace(data)...)
+	return nil
+}
+
+func (v ChangelogValue) MarshalJSON() ([]byte, error) {
+	if v.IsNull() {
+		return []byte("null"), nil
+	}
+	return v.raw, nil
+}
+
+func (v ChangelogValue) Raw() json.RawMessage {
+	return v.raw
+}
+
+func (v ChangelogValue) IsNull() bool {
+	return len(v.raw) == 0 || bytes.Equal(v.raw, []byte("null"))
+}
+
+// String returns string values unquoted and other values as JSON text. Null
+// is the empty string.
+func (v ChangelogValue) String() string {
+	if v.IsNull() {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(v.raw, &s); err == nil {
+		return s
+	}
+	return string(v.raw)
+}
+
+// Int64 reads numbers and numeric strings, as IDs are logged in both forms.
+func (v ChangelogValue) Int64() (int64, bool) {
+	n, err := strconv.ParseInt(v.String(), 10, 64)
+	return n, err == nil && !v.IsNull()
+}
+
+func (v ChangelogValue) Float64() (float64, bool) {
+	f, err := strconv.ParseFloat(v.String(), 64)
+	return f, err == nil && !v.IsNull()
+}
+
+// Bool reads booleans and the 0/1 flags of the API.
+func (v ChangelogValue) Bool() (bool, bool) {
+	switch v.String() {
+	case "true", "1":
+		return true, true
+	case "false", "0":
+		return false, true
+	}
+	return false, false
+}
+
+// Time reads timestamps and dates.
+func (v ChangelogValue) Time() (time.Time, bool) {
+	s := v.String()
+	for _, layout := range append(v1TimeLayouts, "2006-01-02") {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+type DealChangelogEntry struct {
+	FieldKey              string         `json:"field_key,omitempty"`
+	OldValue              ChangelogValue `json:"old_value"`
+	NewValue              ChangelogValue `json:"new_value"`
+	ActorUserID           *UserID        `json:"actor_user_id,omitempty"`
+	Time                  *DateTime      `json:"time,omitempty"`
+	ChangeSource          string         `json:"change_source,omitempty"`
+	ChangeSourceUserAgent string         `json:"change_source_user_agent,omitempty"`
+	IsBulkUpdate          NumberBool     `json:"is_bulk_update_flag,omitempty"`
+}
+
+// ChangelogEntries is Changelog with typed entries.
+func (s *DealsService) ChangelogEntries(ctx context.Context, id DealID, opts ...DealsOption) ([]DealChangelogEntry, *CollectionPagination, error) {
+	if err := validateID(id, "deal id"); err != nil {
+		return nil, nil, err
+	}
+	cfg := newDealsOptions(opts)
+	path := fmt.Sprintf("/deals/%d/changelog", id)
+
+	var payload struct {
+		Data           []DealChangelogEntry  `json:"data"`
+		AdditionalData *CollectionPagination `json:"additional_data"`
+	}
+	if err := s.client.Raw.Do(ctx, http.MethodGet, path, cfg.query, nil, &payload, cfg.requestOptions...); err != nil {
+		return nil, nil, err
+	}
+	return payload.Data, payload.AdditionalData, nil
+}
+
+func (s *DealsService) ChangelogPager(id DealID, opts ...DealsOption) *pipedrive.CursorPager[DealChangelogEntry] {
+	return pipedrive.NewCursorPager(func(ctx context.Context, cursor *string) ([]DealChangelogEntry, *string, error) {
+		pageOpts := opts
+		if cursor != nil {
+			pageOpts = append(append([]DealsOption(nil), opts...), WithDealsQuery(url.Values{"cursor": {*cursor}}))
+		}
+		entries, page, err := s.ChangelogEntries(ctx, id, pageOpts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		if page == nil || page.NextCursor == nil || *page.NextCursor == "" {
+			return entries, nil, nil
+		}
+		return entries, page.NextCursor, nil
+	})
+}
+
+func (s *DealsService) ForEachChangelog(ctx context.Context, id DealID, fn func(DealChangelogEntry) error, opts ...DealsOption) error {
+	return s.ChangelogPager(id, opts...).ForEach(ctx, fn)
+}
+
+type DealUpdateObject string
+
+const (
+	DealUpdateActivity                  DealUpdateObject = "activity"
+	DealUpdatePlannedActivity           DealUpdateObject = "plannedActivity"
+	DealUpdateNote                      DealUpdateObject = "note"
+	DealUpdateFile                      DealUpdateObject = "file"
+	DealUpdateMailMessage               DealUpdateObject = "mailMessage"
+	DealUpdateMailMessageWithAttachment DealUpdateObject = "mailMessageWithAttachment"
+	DealUpdateDealChange                DealUpdateObject = "dealChange"
+	DealUpdateFollower                  DealUpdateObject = "follower"
+)
+
+// DealUpdate is one item of a deal's updates flow. The field matching Object
+// is set: Activity for activities and planned activities, Mail for mail
+// messages with or without attachments. Other objects only carry Raw.
+type DealUpdate struct {
+	Object     DealUpdateObject
+	Timestamp  *DateTime
+	Activity   *Activity
+	Note       *Note
+	File       *File
+	Mail       *MailMessage
+	DealChange *DealChange
+	Follower   *DealFollower
+	Raw        json.RawMessage
+	// Err is set when Raw does not fit the model for Object. The model field
+	// is then nil.
+	Err error
+}
+
+// DealChange is a field change in the updates flow.
+type DealChange struct {
+	ID                    int64                     `json:"id,omitempty"`
+	ItemID                DealID                    `json:"item_id,omitempty"`
+	UserID                *UserID                   `json:"user_id,omitempty"`
+	FieldKey              string                    `json:"field_key,omitempty"`
+	OldValue              ChangelogValue            `json:"old_value"`
+	NewValue              ChangelogValue            `json:"new_value"`
+	IsBulkUpdate          NumberBool                `json:"is_bulk_update_flag,omitempty"`
+	LogTime               *DateTime                 `json:"log_time,omitempty"`
+	ChangeSource          string                    `json:"change_source,omitempty"`
+	ChangeSourceUserAgent string                    `json:"change_source_user_agent,omitempty"`
+	AdditionalData        *DealChangeAdditionalData `json:"additional_data,omitempty"`
+}
+
+type DealChangeAdditionalData struct {
+	OldValueFormatted string `json:"old_value_formatted,omitempty"`
+	NewValueFormatted string `json:"new_value_formatted,omitempty"`
+}
+
+type DealFollower struct {
+	ID      int64     `json:"id,omitempty"`
+	UserID  *UserID   `json:"user_id,omitempty"`
+	DealID  *DealID   `json:"deal_id,omitempty"`
+	AddTime *DateTime `json:"add_time,omitempty"`
+}
+
+// UnmarshalJSON records an item that does not fit its model in Err rather
+// than failing, so one odd item cannot fail a whole page of the flow.
+func (u *DealUpdate) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Object    DealUpdateObject `json:"object"`
+		Timestamp json.RawMessage  `json:"timestamp"`
+		Data      json.RawMessage  `json:"data"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*u = DealUpdate{Object: raw.Object, Raw: raw.Data}
+	if len(raw.Timestamp) > 0 {
+		if err := json.Unmarshal(raw.Timestamp, &u.Timestamp); err != nil {
+			u.Err = fmt.Errorf("decode %s update timestamp: %w", raw.Object, err)
+		}
+	}
+	if err := u.decodeData(); err != nil {
+		u.Activity, u.Note, u.File, u.Mail, u.DealChange, u.Follower = nil, nil, nil, nil, nil, nil
+		u.Err = fmt.Errorf("decode %s update: %w", raw.Object, err)
+	}
+	return nil
+}
+
+func (u *DealUpdate) decodeData() error {
+	body := bytes.TrimSpace(u.Raw)
+	if len(body) == 0 || bytes.Equal(body, []byte("null")) {
+		return nil
+	}
+	body, err := flattenRelatedIDs(body)
+	if err != nil {
+		return err
+	}
+
+	var target interface{}
+	switch u.Object {
+	case DealUpdateActivity, DealUpdatePlannedActivity:
+		u.Activity = new(Activity)
+		target = u.Activity
+	case DealUpdateNote:
+		u.Note = new(Note)
+		target = u.Note
+	case DealUpdateFile:
+		u.File = new(File)
+		target = u.File
+	case DealUpdateMailMessage, DealUpdateMailMessageWithAttachment:
+		u.Mail = new(MailMessage)
+		target = u.Mail
+	case DealUpdateDealChange:
+		u.DealChange = new(DealChange)
+		target = u.DealChange
+	case DealUpdateFollower:
+		u.Follower = new(DealFollower)
+		target = u.Follower
+	default:
+		return nil
+	}
+	return json.Unmarshal(body, target)
+}
+
+func (u DealUpdate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Object    DealUpdateObject `json:"object"`
+		Timestamp *DateTime        `json:"timestamp,omitempty"`
+		Data      json.RawMessage  `json:"data,omitempty"`
+	}{Object: u.Object, Timestamp: u.Timestamp, Data: u.Raw})
+}
+
+// ListUpdateItems is ListUpdates with the flow items decoded by object. Items
+// that fail to decode are returned with Err set.
+func (s *DealsService) ListUpdateItems(ctx context.Context, id DealID, opts ...DealsOption) ([]DealUpdate, *Pagination, error) {
+	if err := validateID(id, "deal id"); err != nil {
+		return nil, nil, err
+	}
+	cfg := newDealsOptions(opts)
+	path := fmt.Sprintf("/deals/%d/flow", id)
+
+	var payload struct {
+		Data           []DealUpdate `json:"data"`
+		AdditionalData *struct {
+			Pagination *Pagination `json:"pagination"`
+		} `json:"additional_data"`
+	}
+	if err := s.client.Raw.Do(ctx, http.MethodGet, path, cfg.query, nil, &payload, cfg.requestOptions...); err != nil {
+		return nil, nil, err
+	}
+	var page *Pagination
+	if payload.AdditionalData != nil {
+		page = payload.AdditionalData.Pagination
+	}
+	return payload.Data, page, nil
+}
+
+func (s *DealsService) UpdatesPager(id DealID, opts ...DealsOption) *pipedrive.CursorPager[DealUpdate] {
+	return pipedrive.NewCursorPager(func(ctx context.Context, cursor *string) ([]DealUpdate, *string, error) {
+		pageOpts := opts
+		if cursor != nil {
+			pageOpts = append(append([]DealsOption(nil), opts...), WithDealsQuery(url.Values{"start": {*cursor}}))
+		}
+		updates, page, err := s.ListUpdateItems(ctx, id, pageOpts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		if page == nil || !page.MoreItemsInCollection || len(updates) == 0 {
+			return updates, nil, nil
+		}
+		next := page.NextStart
+		if next == 0 {
+			next = page.Start + len(updates)
+		}
+		value := strconv.Itoa(next)
+		return updates, &value, nil
+	})
+}
+
+func (s *DealsService) ForEachUpdate(ctx context.Context, id DealID, fn func(DealUpdate) error, opts ...DealsOption) error {
+	return s.UpdatesPager(id, opts...).ForEach(ctx, fn)
+}
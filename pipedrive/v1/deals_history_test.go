@@ -0,0 +1,230 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDealsService_TimelinePeriods(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/deals/timeline" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("start_date") != "2026-01-01" || q.Get("interval") != "month" || q.Get("amount") != "3" || q.Get("field_key") != "expected_close_date" {
+			t.Fatalf("unexpected query: %s", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":[{"period_start":"2026-01-01 00:00:00","period_end":"2026-01-31 23:59:59","deals":[{"id":4,"title":"Big","user_id":{"id":7,"name":"Ann","value":7}}],"totals":{"count":1,"values":{"EUR":1500},"weighted_values":{"EUR":750},"open_count":1,"open_values":{"EUR":1500},"weighted_open_values":{"EUR":750},"won_count":0,"won_values":{}}}]}`))
+	})
+
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	periods, err := client.Deals.TimelinePeriods(context.Background(), WithDealsTimelineWindow(start, DealsTimelineMonth, 3, "expected_close_date"))
+	if err != nil {
+		t.Fatalf("TimelinePeriods error: %v", err)
+	}
+	if len(periods) != 1 {
+		t.Fatalf("unexpected periods: %#v", periods)
+	}
+	period := periods[0]
+	if period.PeriodStart == nil || !period.PeriodStart.Equal(start) {
+		t.Fatalf("unexpected period start: %#v", period.PeriodStart)
+	}
+	if len(period.Deals) != 1 || period.Deals[0].ID != 4 || period.Deals[0].OwnerID == nil || *period.Deals[0].OwnerID != 7 {
+		t.Fatalf("unexpected deals: %#v", period.Deals)
+	}
+	if period.Totals == nil || period.Totals.Count != 1 || period.Totals.WeightedValues["EUR"] != 750 {
+		t.Fatalf("unexpected totals: %#v", period.Totals)
+	}
+}
+
+func TestDealsService_ArchivedTimelinePeriodsWrapped(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/deals/timeline/archived" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"periods":[{"totals":{"won_count":2}}]}}`))
+	})
+
+	periods, err := client.Deals.ArchivedTimelinePeriods(context.Background())
+	if err != nil {
+		t.Fatalf("ArchivedTimelinePeriods error: %v", err)
+	}
+	if len(periods) != 1 || periods[0].Totals == nil || periods[0].Totals.WonCount != 2 {
+		t.Fatalf("unexpected periods: %#v", periods)
+	}
+}
+
+func TestChangelogValue(t *testing.T) {
+	t.Parallel()
+
+	var entry DealChangelogEntry
+	data := `{"field_key":"value","old_value":"100","new_value":250.5,"actor_user_id":3,"time":"2026-02-03 10:00:00","is_bulk_update_flag":0}`
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if n, ok := entry.OldValue.Int64(); !ok || n != 100 {
+		t.Fatalf("unexpected old value: %d %v", n, ok)
+	}
+	if f, ok := entry.NewValue.Float64(); !ok || f != 250.5 {
+		t.Fatalf("unexpected new value: %v %v", f, ok)
+	}
+	if entry.ActorUserID == nil || *entry.ActorUserID != 3 || bool(entry.IsBulkUpdate) {
+		t.Fatalf("unexpected entry: %#v", entry)
+	}
+
+	var null ChangelogValue
+	if err := json.Unmarshal([]byte(`null`), &null); err != nil {
+		t.Fatalf("unmarshal null: %v", err)
+	}
+	if !null.IsNull() || null.String() != "" {
+		t.Fatalf("expected null value: %q", null.Raw())
+	}
+	if _, ok := null.Int64(); ok {
+		t.Fatalf("expected no number for null")
+	}
+
+	var date ChangelogValue
+	_ = json.Unmarshal([]byte(`"2026-03-01"`), &date)
+	if tm, ok := date.Time(); !ok || tm.Month() != time.March {
+		t.Fatalf("unexpected time: %v %v", tm, ok)
+	}
+	var flag ChangelogValue
+	_ = json.Unmarshal([]byte(`true`), &flag)
+	if b, ok := flag.Bool(); !ok || !b {
+		t.Fatalf("unexpected bool: %v %v", b, ok)
+	}
+
+	out, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var round map[string]any
+	_ = json.Unmarshal(out, &round)
+	if round["old_value"] != "100" || round["new_value"] != 250.5 {
+		t.Fatalf("unexpected round trip: %s", out)
+	}
+}
+
+func TestDealsService_ChangelogPager(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/deals/9/changelog" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"field_key":"title","old_value":"A","new_value":"B"}],"additional_data":{"next_cursor":"c2"}}`))
+		case "c2":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"field_key":"stage_id","old_value":1,"new_value":2}],"additional_data":{"next_cursor":null}}`))
+		default:
+			t.Fatalf("unexpected cursor: %s", r.URL.RawQuery)
+		}
+	})
+
+	var keys []string
+	err := client.Deals.ForEachChangelog(context.Background(), DealID(9), func(e DealChangelogEntry) error {
+		keys = append(keys, e.FieldKey+"="+e.NewValue.String())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachChangelog error: %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "title=B" || keys[1] != "stage_id=2" {
+		t.Fatalf("unexpected entries: %v", keys)
+	}
+}
+
+func TestDealsService_ListUpdateItems(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/deals/17/flow" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":[
+			{"object":"dealChange","timestamp":"2026-02-03 10:00:00","data":{"id":1,"item_id":17,"user_id":{"id":3,"value":3},"field_key":"status","old_value":"open","new_value":"won","log_time":"2026-02-03 10:00:00","additional_data":{"new_value_formatted":"Won"}}},
+			{"object":"plannedActivity","timestamp":"2026-02-03 09:00:00","data":{"id":5,"subject":"Call"}},
+			{"object":"note","data":{"id":6,"content":"Hi"}},
+			{"object":"file","data":{"id":7,"name":"a.pdf"}},
+			{"object":"mailMessageWithAttachment","data":{"id":8,"subject":"Offer"}},
+			{"object":"follower","data":{"id":9,"user_id":3,"deal_id":17}},
+			{"object":"invoice","data":{"id":10}}
+		],"additional_data":{"pagination":{"start":0,"limit":7,"more_items_in_collection":false}}}`))
+	})
+
+	updates, page, err := client.Deals.ListUpdateItems(context.Background(), DealID(17))
+	if err != nil {
+		t.Fatalf("ListUpdateItems error: %v", err)
+	}
+	if page == nil || page.Limit != 7 || len(updates) != 7 {
+		t.Fatalf("unexpected result: %#v %#v", updates, page)
+	}
+	change := updates[0].DealChange
+	if change == nil || change.FieldKey != "status" || change.NewValue.String() != "won" || change.UserID == nil || *change.UserID != 3 {
+		t.Fatalf("unexpected deal change: %#v", change)
+	}
+	if change.AdditionalData == nil || change.AdditionalData.NewValueFormatted != "Won" || updates[0].Timestamp == nil {
+		t.Fatalf("unexpected deal change data: %#v", updates[0])
+	}
+	if updates[1].Activity == nil || updates[1].Activity.ID != 5 {
+		t.Fatalf("unexpected activity: %#v", updates[1])
+	}
+	if updates[2].Note == nil || updates[3].File == nil || updates[4].Mail == nil {
+		t.Fatalf("unexpected note, file or mail: %#v", updates[2:5])
+	}
+	if f := updates[5].Follower; f == nil || f.DealID == nil || *f.DealID != 17 {
+		t.Fatalf("unexpected follower: %#v", updates[5])
+	}
+	other := updates[6]
+	if other.Object != "invoice" || len(other.Raw) == 0 || other.Activity != nil {
+		t.Fatalf("unexpected unknown update: %#v", other)
+	}
+}
+
+func TestDealsService_UpdatesPager(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("start") {
+		case "":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"object":"note","data":{"id":1}}],"additional_data":{"pagination":{"start":0,"limit":1,"more_items_in_collection":true,"next_start":1}}}`))
+		case "1":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"object":"note","data":{"id":"two"}},{"object":"note","data":{"id":3}}],"additional_data":{"pagination":{"start":1,"limit":2,"more_items_in_collection":false}}}`))
+		default:
+			t.Fatalf("unexpected start: %s", r.URL.RawQuery)
+		}
+	})
+
+	var ids []int64
+	err := client.Deals.ForEachUpdate(context.Background(), DealID(17), func(u DealUpdate) error {
+		if u.Err != nil {
+			if u.Note != nil || len(u.Raw) == 0 {
+				t.Fatalf("unexpected failed update: %#v", u)
+			}
+			ids = append(ids, 0)
+			return nil
+		}
+		ids = append(ids, int64(u.Note.ID))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachUpdate error: %v", err)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 0 || ids[2] != 3 {
+		t.Fatalf("unexpected updates: %v", ids)
+	}
+}
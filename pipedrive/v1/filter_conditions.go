@@ -0,0 +1,421 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxFilterConditions is the per-filter condition limit enforced by Pipedrive.
+const maxFilterConditions = 16
+
+type FilterGlue string
+
+const (
+	FilterGlueAnd FilterGlue = "and"
+	FilterGlueOr  FilterGlue = "or"
+)
+
+type FilterObject string
+
+const (
+	FilterObjectDeal         FilterObject = "deal"
+	FilterObjectPerson       FilterObject = "person"
+	FilterObjectOrganization FilterObject = "organization"
+	FilterObjectProduct      FilterObject = "product"
+	FilterObjectActivity     FilterObject = "activity"
+	FilterObjectLead         FilterObject = "lead"
+	FilterObjectProject      FilterObject = "project"
+)
+
+type FilterOperator string
+
+const (
+	FilterOperatorEqual          FilterOperator = "="
+	FilterOperatorNotEqual       FilterOperator = "!="
+	FilterOperatorLess           FilterOperator = "<"
+	FilterOperatorGreater        FilterOperator = ">"
+	FilterOperatorLessOrEqual    FilterOperator = "<="
+	FilterOperatorGreaterOrEqual FilterOperator = ">="
+	FilterOperatorIsNull         FilterOperator = "IS NULL"
+	FilterOperatorIsNotNull      FilterOperator = "IS NOT NULL"
+	FilterOperatorStartsWith     FilterOperator = "LIKE '$%'"
+	FilterOperatorContains       FilterOperator = "LIKE '%$%'"
+	FilterOperatorNotStartsWith  FilterOperator = "NOT LIKE '$%'"
+	FilterOperatorNotContains    FilterOperator = "NOT LIKE '%$%'"
+	FilterOperatorEndsWith       FilterOperator = "LIKE '%$'"
+	FilterOperatorNotEndsWith    FilterOperator = "NOT LIKE '%$'"
+)
+
+// FilterCondition is a single filter rule. FieldCode and FieldType are not
+// sent to the API. FieldCode is filled when decoding filters fetched with
+// WithFilterIncludeFieldCode. When FieldType is set, the operator is validated
+// against the operators Pipedrive allows for that field type instead of
+// against every known operator.
+type FilterCondition struct {
+	Object     FilterObject   `json:"object"`
+	FieldID    string         `json:"field_id"`
+	Operator   FilterOperator `json:"operator"`
+	Value      interface{}    `json:"value"`
+	ExtraValue interface{}    `json:"extra_value"`
+	FieldCode  string         `json:"-"`
+	FieldType  string         `json:"-"`
+}
+
+type FilterConditionGroup struct {
+	Glue       FilterGlue        `json:"glue"`
+	Conditions []FilterCondition `json:"conditions"`
+}
+
+// FilterConditionTree is the typed form of FilterConditions. Pipedrive only
+// accepts a top-level "and" glue over at most one "and" group and one "or"
+// group; ToConditions always emits both groups in that order.
+type FilterConditionTree struct {
+	Glue   FilterGlue             `json:"glue"`
+	Groups []FilterConditionGroup `json:"conditions"`
+}
+
+type FilterConditionError struct {
+	Group  int
+	Index  int
+	Reason string
+}
+
+func (e *FilterConditionError) Error() string {
+	if e == nil {
+		return "invalid filter condition"
+	}
+	if e.Index < 0 {
+		return fmt.Sprintf("filter condition group %d: %s", e.Group, e.Reason)
+	}
+	return fmt.Sprintf("filter condition %d in group %d: %s", e.Index, e.Group, e.Reason)
+}
+
+// FilterHelpers is the typed form of the filter helpers response. Operators
+// and RelativeDates map field types and date groups to value-label pairs.
+type FilterHelpers struct {
+	Operators           map[string]map[FilterOperator]string `json:"operators"`
+	DeprecatedOperators map[FilterOperator]string            `json:"deprecated_operators"`
+	RelativeDates       map[string]map[string]string         `json:"relative_dates"`
+}
+
+func NewFilterConditionTree() *FilterConditionTree {
+	return &FilterConditionTree{
+		Glue: FilterGlueAnd,
+		Groups: []FilterConditionGroup{
+			{Glue: FilterGlueAnd},
+			{Glue: FilterGlueOr},
+		},
+	}
+}
+
+// And appends conditions that must all match.
+func (t *FilterConditionTree) And(conditions ...FilterCondition) *FilterConditionTree {
+	group := t.group(FilterGlueAnd)
+	group.Conditions = append(group.Conditions, conditions...)
+	return t
+}
+
+// Or appends conditions of which at least one must match.
+func (t *FilterConditionTree) Or(conditions ...FilterCondition) *FilterConditionTree {
+	group := t.group(FilterGlueOr)
+	group.Conditions = append(group.Conditions, conditions...)
+	return t
+}
+
+func (t *FilterConditionTree) group(glue FilterGlue) *FilterConditionGroup {
+	if t.Glue == "" {
+		t.Glue = FilterGlueAnd
+	}
+	for i := range t.Groups {
+		if t.Groups[i].Glue == glue {
+			return &t.Groups[i]
+		}
+	}
+	t.Groups = append(t.Groups, FilterConditionGroup{Glue: glue})
+	return &t.Groups[len(t.Groups)-1]
+}
+
+// Len returns the number of conditions across all groups.
+func (t *FilterConditionTree) Len() int {
+	if t == nil {
+		return 0
+	}
+	n := 0
+	for _, group := range t.Groups {
+		n += len(group.Conditions)
+	}
+	return n
+}
+
+// Validate checks the tree shape and condition fields. When helpers is not
+// nil, each operator must also be one Pipedrive lists for the condition's
+// field type, or for any field type when FieldType is empty.
+func (t *FilterConditionTree) Validate(helpers *FilterHelpers) error {
+	if t == nil {
+		return fmt.Errorf("filter conditions are required")
+	}
+	if t.Glue != "" && t.Glue != FilterGlueAnd {
+		return fmt.Errorf("filter conditions: top-level glue must be %q, got %q", FilterGlueAnd, t.Glue)
+	}
+	if n := t.Len(); n > maxFilterConditions {
+		return fmt.Errorf("filter conditions: %d conditions exceed the limit of %d", n, maxFilterConditions)
+	}
+	seen := map[FilterGlue]bool{}
+	for g, group := range t.Groups {
+		if group.Glue != FilterGlueAnd && group.Glue != FilterGlueOr {
+			return &FilterConditionError{Group: g, Index: -1, Reason: fmt.Sprintf("unsupported glue %q", group.Glue)}
+		}
+		if seen[group.Glue] {
+			return &FilterConditionError{Group: g, Index: -1, Reason: fmt.Sprintf("only one %q group is supported", group.Glue)}
+		}
+		seen[group.Glue] = true
+		for i, condition := range group.Conditions {
+			if reason := condition.problem(helpers); reason != "" {
+				return &FilterConditionError{Group: g, Index: i, Reason: reason}
+			}
+		}
+	}
+	return nil
+}
+
+func (c FilterCondition) problem(helpers *FilterHelpers) string {
+	switch {
+	case c.Object == "":
+		return "object is required"
+	case strings.TrimSpace(c.FieldID) == "":
+		return "field id is required"
+	case c.Operator == "":
+		return "operator is required"
+	}
+	if helpers == nil {
+		return ""
+	}
+	if c.FieldType != "" {
+		operators, ok := helpers.Operators[c.FieldType]
+		if !ok {
+			return fmt.Sprintf("unknown field type %q", c.FieldType)
+		}
+		if _, ok := operators[c.Operator]; !ok {
+			return fmt.Sprintf("operator %q is not allowed for %s fields (allowed: %s)", c.Operator, c.FieldType, operatorList(operators))
+		}
+		return ""
+	}
+	if !helpers.KnowsOperator(c.Operator) {
+		return fmt.Sprintf("unknown operator %q", c.Operator)
+	}
+	return ""
+}
+
+// ToConditions validates the tree shape and returns the wire representation,
+// with the "and" group first and the "or" group second.
+func (t *FilterConditionTree) ToConditions() (FilterConditions, error) {
+	if err := t.Validate(nil); err != nil {
+		return nil, err
+	}
+	canonical := FilterConditionTree{Glue: FilterGlueAnd}
+	for _, glue := range []FilterGlue{FilterGlueAnd, FilterGlueOr} {
+		group := FilterConditionGroup{Glue: glue, Conditions: []FilterCondition{}}
+		for _, existing := range t.Groups {
+			if existing.Glue == glue {
+				group.Conditions = append(group.Conditions, existing.Conditions...)
+			}
+		}
+		canonical.Groups = append(canonical.Groups, group)
+	}
+
+	raw, err := json.Marshal(canonical)
+	if err != nil {
+		return nil, fmt.Errorf("encode filter conditions: %w", err)
+	}
+	var conditions FilterConditions
+	if err := json.Unmarshal(raw, &conditions); err != nil {
+		return nil, fmt.Errorf("encode filter conditions: %w", err)
+	}
+	return conditions, nil
+}
+
+// DecodeFilterConditions parses conditions returned by the API into a tree.
+func DecodeFilterConditions(conditions FilterConditions) (*FilterConditionTree, error) {
+	if conditions == nil {
+		return nil, fmt.Errorf("filter conditions are required")
+	}
+	raw, err := json.Marshal(conditions)
+	if err != nil {
+		return nil, fmt.Errorf("decode filter conditions: %w", err)
+	}
+
+	var payload struct {
+		Glue       FilterGlue `json:"glue"`
+		Conditions []struct {
+			Glue       FilterGlue `json:"glue"`
+			Conditions []struct {
+				Object     FilterObject    `json:"object"`
+				FieldID    json.RawMessage `json:"field_id"`
+				FieldCode  *string         `json:"field_code"`
+				Operator   FilterOperator  `json:"operator"`
+				Value      interface{}     `json:"value"`
+				ExtraValue interface{}     `json:"extra_value"`
+			} `json:"conditions"`
+		} `json:"conditions"`
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("decode filter conditions: %w", err)
+	}
+
+	tree := &FilterConditionTree{Glue: payload.Glue}
+	for g, group := range payload.Conditions {
+		decoded := FilterConditionGroup{Glue: group.Glue}
+		for i, condition := range group.Conditions {
+			fieldID, err := decodeFilterFieldID(condition.FieldID)
+			if err != nil {
+				return nil, &FilterConditionError{Group: g, Index: i, Reason: err.Error()}
+			}
+			decodedCondition := FilterCondition{
+				Object:     condition.Object,
+				FieldID:    fieldID,
+				Operator:   condition.Operator,
+				Value:      condition.Value,
+				ExtraValue: condition.ExtraValue,
+			}
+			if condition.FieldCode != nil {
+				decodedCondition.FieldCode = *condition.FieldCode
+			}
+			decoded.Conditions = append(decoded.Conditions, decodedCondition)
+		}
+		tree.Groups = append(tree.Groups, decoded)
+	}
+	return tree, nil
+}
+
+// ConditionTree decodes the filter's conditions into a tree.
+func (f *Filter) ConditionTree() (*FilterConditionTree, error) {
+	if f == nil {
+		return nil, fmt.Errorf("filter is nil")
+	}
+	return DecodeFilterConditions(f.Conditions)
+}
+
+func decodeFilterFieldID(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return "", fmt.Errorf("invalid field id %s", raw)
+	}
+	if _, err := strconv.ParseInt(n.String(), 10, 64); err != nil {
+		return "", fmt.Errorf("invalid field id %s", raw)
+	}
+	return n.String(), nil
+}
+
+// KnowsOperator reports whether operator is listed for any field type or as
+// a deprecated operator.
+func (h *FilterHelpers) KnowsOperator(operator FilterOperator) bool {
+	if h == nil {
+		return false
+	}
+	for _, operators := range h.Operators {
+		if _, ok := operators[operator]; ok {
+			return true
+		}
+	}
+	_, ok := h.DeprecatedOperators[operator]
+	return ok
+}
+
+// OperatorsFor returns the operators allowed for a field type, sorted.
+func (h *FilterHelpers) OperatorsFor(fieldType string) []FilterOperator {
+	if h == nil {
+		return nil
+	}
+	operators := h.Operators[fieldType]
+	out := make([]FilterOperator, 0, len(operators))
+	for operator := range operators {
+		out = append(out, operator)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func operatorList(operators map[FilterOperator]string) string {
+	names := make([]string, 0, len(operators))
+	for operator := range operators {
+		names = append(names, string(operator))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Helpers returns the typed filter helpers.
+func (s *FiltersService) Helpers(ctx context.Context, opts ...ListFilterHelpersOption) (*FilterHelpers, error) {
+	payload, err := s.ListHelpers(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := payload["data"]
+	if !ok || data == nil {
+		return nil, fmt.Errorf("missing filter helpers data in response")
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	var helpers FilterHelpers
+	if err := json.Unmarshal(raw, &helpers); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &helpers, nil
+}
+
+// WithFilterConditionTree sets the filter conditions from a typed tree. The
+// tree is validated against the operators from Helpers before the request is
+// sent; use WithFilterHelpers to reuse helpers fetched earlier.
+func WithFilterConditionTree(tree *FilterConditionTree) FilterOption {
+	return filterFieldOption(func(cfg *filterPayload) {
+		cfg.conditions = nil
+		cfg.tree = tree
+	})
+}
+
+// WithFilterHelpers supplies the helpers used to validate a condition tree
+// instead of fetching them for every request.
+func WithFilterHelpers(helpers *FilterHelpers) FilterOption {
+	return filterFieldOption(func(cfg *filterPayload) {
+		cfg.helpers = helpers
+	})
+}
+
+func (s *FiltersService) resolveConditionTree(ctx context.Context, p *filterPayload, opts ListFilterHelpersOption) error {
+	if p.tree == nil {
+		return nil
+	}
+	if err := p.tree.Validate(nil); err != nil {
+		return err
+	}
+	helpers := p.helpers
+	if helpers == nil {
+		var err error
+		helpers, err = s.Helpers(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("fetch filter helpers: %w", err)
+		}
+	}
+	if err := p.tree.Validate(helpers); err != nil {
+		return err
+	}
+	conditions, err := p.tree.ToConditions()
+	if err != nil {
+		return err
+	}
+	p.conditions = &conditions
+	return nil
+}
@@ -0,0 +1,207 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const testFilterHelpers = `{"success":true,"data":{
+	"operators":{
+		"int":{"=":"is","!=":"is not",">":"is more than","<":"is less than","IS NULL":"is empty","IS NOT NULL":"is not empty"},
+		"varchar":{"=":"is","!=":"is not","LIKE '%$%'":"contains","IS NULL":"is empty"}
+	},
+	"deprecated_operators":{"LIKE '%$'":"ends with"},
+	"relative_dates":{"Relative dates":{"today":"today"}}
+}}`
+
+func TestFilterConditionTree_ToConditions(t *testing.T) {
+	t.Parallel()
+
+	tree := NewFilterConditionTree().
+		And(FilterCondition{Object: FilterObjectDeal, FieldID: "12", Operator: FilterOperatorGreater, Value: 100}).
+		Or(
+			FilterCondition{Object: FilterObjectPerson, FieldID: "3", Operator: FilterOperatorContains, Value: "acme"},
+			FilterCondition{Object: FilterObjectPerson, FieldID: "4", Operator: FilterOperatorIsNull},
+		)
+
+	conditions, err := tree.ToConditions()
+	if err != nil {
+		t.Fatalf("ToConditions error: %v", err)
+	}
+	raw, err := json.Marshal(conditions)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"conditions":[{"conditions":[{"extra_value":null,"field_id":"12","object":"deal","operator":"\u003e","value":100}],"glue":"and"},` +
+		`{"conditions":[{"extra_value":null,"field_id":"3","object":"person","operator":"LIKE '%$%'","value":"acme"},` +
+		`{"extra_value":null,"field_id":"4","object":"person","operator":"IS NULL","value":null}],"glue":"or"}],"glue":"and"}`
+	if string(raw) != want {
+		t.Fatalf("unexpected conditions:\n got %s\nwant %s", raw, want)
+	}
+
+	empty, err := (&FilterConditionTree{}).ToConditions()
+	if err != nil {
+		t.Fatalf("ToConditions error: %v", err)
+	}
+	raw, _ = json.Marshal(empty)
+	if string(raw) != `{"conditions":[{"conditions":[],"glue":"and"},{"conditions":[],"glue":"or"}],"glue":"and"}` {
+		t.Fatalf("unexpected empty conditions: %s", raw)
+	}
+}
+
+func TestDecodeFilterConditions(t *testing.T) {
+	t.Parallel()
+
+	var filter Filter
+	err := json.Unmarshal([]byte(`{"id":1,"conditions":{"glue":"and","conditions":[
+		{"glue":"and","conditions":[{"object":"deal","field_id":12,"field_code":"title","operator":"=","value":"5","extra_value":null}]},
+		{"glue":"or","conditions":null}
+	]}}`), &filter)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	tree, err := filter.ConditionTree()
+	if err != nil {
+		t.Fatalf("ConditionTree error: %v", err)
+	}
+	if tree.Glue != FilterGlueAnd || len(tree.Groups) != 2 || tree.Len() != 1 {
+		t.Fatalf("unexpected tree: %#v", tree)
+	}
+	got := tree.Groups[0].Conditions[0]
+	if got.Object != FilterObjectDeal || got.FieldID != "12" || got.FieldCode != "title" || got.Operator != FilterOperatorEqual || got.Value != "5" {
+		t.Fatalf("unexpected condition: %#v", got)
+	}
+
+	if _, err := DecodeFilterConditions(FilterConditions{"conditions": []interface{}{
+		map[string]interface{}{"glue": "and", "conditions": []interface{}{map[string]interface{}{"field_id": true}}},
+	}}); err == nil {
+		t.Fatalf("expected field id error")
+	}
+}
+
+func TestFilterConditionTree_Validate(t *testing.T) {
+	t.Parallel()
+
+	var helpers FilterHelpers
+	var payload struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(testFilterHelpers), &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if err := json.Unmarshal(payload.Data, &helpers); err != nil {
+		t.Fatalf("unmarshal helpers: %v", err)
+	}
+
+	cond := func(op FilterOperator, fieldType string) FilterCondition {
+		return FilterCondition{Object: FilterObjectDeal, FieldID: "1", Operator: op, FieldType: fieldType}
+	}
+	valid := []*FilterConditionTree{
+		NewFilterConditionTree().And(cond(FilterOperatorGreater, "int")),
+		NewFilterConditionTree().Or(cond(FilterOperatorEndsWith, "")),
+	}
+	for i, tree := range valid {
+		if err := tree.Validate(&helpers); err != nil {
+			t.Fatalf("valid tree %d: %v", i, err)
+		}
+	}
+
+	many := NewFilterConditionTree()
+	for i := 0; i < 17; i++ {
+		many.And(cond(FilterOperatorEqual, ""))
+	}
+	invalid := []*FilterConditionTree{
+		NewFilterConditionTree().And(cond(FilterOperatorContains, "int")),
+		NewFilterConditionTree().And(cond("~", "")),
+		NewFilterConditionTree().And(cond(FilterOperatorEqual, "monetary")),
+		NewFilterConditionTree().And(FilterCondition{Object: FilterObjectDeal, Operator: FilterOperatorEqual}),
+		{Glue: FilterGlueOr},
+		{Glue: FilterGlueAnd, Groups: []FilterConditionGroup{{Glue: FilterGlueOr}, {Glue: FilterGlueOr}}},
+		many,
+	}
+	for i, tree := range invalid {
+		if err := tree.Validate(&helpers); err == nil {
+			t.Fatalf("invalid tree %d: expected error", i)
+		}
+	}
+
+	err := NewFilterConditionTree().Or(cond(FilterOperatorContains, "int")).Validate(&helpers)
+	var condErr *FilterConditionError
+	if !errors.As(err, &condErr) || condErr.Group != 1 || condErr.Index != 0 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ops := helpers.OperatorsFor("varchar"); len(ops) != 4 || ops[0] != FilterOperatorNotEqual {
+		t.Fatalf("unexpected operators: %v", ops)
+	}
+}
+
+func TestFiltersService_CreateWithConditionTree(t *testing.T) {
+	t.Parallel()
+
+	var helperCalls, createCalls atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/filters/helpers":
+			helperCalls.Add(1)
+			_, _ = w.Write([]byte(testFilterHelpers))
+		case r.Method == http.MethodPost && r.URL.Path == "/filters":
+			createCalls.Add(1)
+			body, _ := io.ReadAll(r.Body)
+			if !strings.Contains(string(body), `"field_id":"12"`) || !strings.Contains(string(body), `"glue":"or"`) {
+				t.Errorf("unexpected body: %s", body)
+			}
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":10,"name":"Big deals","type":"deals"}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	tree := NewFilterConditionTree().
+		And(FilterCondition{Object: FilterObjectDeal, FieldID: "12", Operator: FilterOperatorGreater, Value: 1000, FieldType: "int"})
+	filter, err := client.Filters.Create(
+		context.Background(),
+		WithFilterName("Big deals"),
+		WithFilterType(FilterTypeDeals),
+		WithFilterConditionTree(tree),
+	)
+	if err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	if filter.ID != 10 {
+		t.Fatalf("unexpected filter: %#v", filter)
+	}
+
+	bad := NewFilterConditionTree().
+		And(FilterCondition{Object: FilterObjectDeal, FieldID: "12", Operator: FilterOperatorContains, FieldType: "int"})
+	if _, err := client.Filters.Update(context.Background(), FilterID(10), WithFilterConditionTree(bad)); err == nil {
+		t.Fatalf("expected validation error")
+	}
+	if helperCalls.Load() != 2 || createCalls.Load() != 1 {
+		t.Fatalf("unexpected calls: helpers=%d create=%d", helperCalls.Load(), createCalls.Load())
+	}
+
+	helpers, err := client.Filters.Helpers(context.Background())
+	if err != nil {
+		t.Fatalf("Helpers error: %v", err)
+	}
+	if _, err := client.Filters.Update(context.Background(), FilterID(10), WithFilterConditionTree(bad), WithFilterHelpers(helpers)); err == nil {
+		t.Fatalf("expected validation error")
+	}
+	if helperCalls.Load() != 3 {
+		t.Fatalf("expected supplied helpers to be reused, got %d helper calls", helperCalls.Load())
+	}
+
+	if _, err := client.Filters.Create(context.Background(), WithFilterType(FilterTypeDeals), WithFilterConditionTree(tree)); err == nil {
+		t.Fatalf("expected name required error")
+	}
+	if helperCalls.Load() != 3 {
+		t.Fatalf("expected required fields to be checked before fetching helpers, got %d helper calls", helperCalls.Load())
+	}
+}
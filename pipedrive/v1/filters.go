@@ -138,6 +138,8 @@ type filterPayload struct {
 	name       *string
 	filterType *FilterType
 	conditions *FilterConditions
+	tree       *FilterConditionTree
+	helpers    *FilterHelpers
 }
 
 type filtersRequestOptions struct {
@@ -258,6 +260,7 @@ func WithFilterType(filterType FilterType) CreateFilterOption {
 
 func WithFilterConditions(conditions FilterConditions) FilterOption {
 	return filterFieldOption(func(cfg *filterPayload) {
+		cfg.tree = nil
 		if conditions == nil {
 			cfg.conditions = nil
 			return
@@ -407,17 +410,19 @@ func (s *FiltersService) Get(ctx context.Context, id FilterID, opts ...GetFilter
 
 func (s *FiltersService) Create(ctx context.Context, opts ...CreateFilterOption) (*Filter, error) {
 	cfg := newCreateFilterOptions(opts)
-	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
-
 	if cfg.payload.name == nil {
 		return nil, fmt.Errorf("name is required")
 	}
 	if cfg.payload.filterType == nil {
 		return nil, fmt.Errorf("filter type is required")
 	}
-	if cfg.payload.conditions == nil {
+	if cfg.payload.conditions == nil && cfg.payload.tree == nil {
 		return nil, fmt.Errorf("conditions are required")
 	}
+	if err := s.resolveConditionTree(ctx, &cfg.payload, WithFiltersRequestOptions(cfg.requestOptions...)); err != nil {
+		return nil, err
+	}
+	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap(true))
 	if err != nil {
@@ -454,11 +459,13 @@ func (s *FiltersService) Update(ctx context.Context, id FilterID, opts ...Update
 		return nil, err
 	}
 	cfg := newUpdateFilterOptions(opts)
-	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
-
-	if cfg.payload.name == nil && cfg.payload.conditions == nil {
+	if cfg.payload.name == nil && cfg.payload.conditions == nil && cfg.payload.tree == nil {
 		return nil, fmt.Errorf("name or conditions are required")
 	}
+	if err := s.resolveConditionTree(ctx, &cfg.payload, WithFiltersRequestOptions(cfg.requestOptions...)); err != nil {
+		return nil, err
+	}
+	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap(false))
 	if err != nil {
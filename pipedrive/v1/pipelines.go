@@ -62,6 +62,18 @@ type PipelineDealsAdditionalData struct {
 	DealsSummary map[string]any `json:"deals_summary,omitempty"`
 }
 
+type Pipeline struct {
+	ID              PipelineID `json:"id,omitempty"`
+	Name            string     `json:"name,omitempty"`
+	URLTitle        string     `json:"url_title,omitempty"`
+	OrderNr         int        `json:"order_nr,omitempty"`
+	Active          bool       `json:"active,omitempty"`
+	DealProbability bool       `json:"deal_probability,omitempty"`
+	Selected        bool       `json:"selected,omitempty"`
+	AddTime         *DateTime  `json:"add_time,omitempty"`
+	UpdateTime      *DateTime  `json:"update_time,omitempty"`
+}
+
 type PipelinesService struct {
 	client *Client
 }
@@ -1,10 +1,12 @@
 package v1
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -161,3 +163,218 @@ func (s *RecentsService) List(ctx context.Context, opts ...ListRecentsOption) ([
 	}
 	return payload.Data, payload.AdditionalData, nil
 }
+
+// RecentItem is a Recent decoded by its item kind. The field matching Item is
+// set; Raw keeps the data as returned. Deleted entities may come without
+// data, in which case only Item, ID and Raw are set.
+type RecentItem struct {
+	Item         RecentsItemType
+	ID           int64
+	Activity     *Activity
+	ActivityType *ActivityType
+	Deal         *Deal
+	File         *File
+	Filter       *Filter
+	Note         *Note
+	Organization *Organization
+	Person       *Person
+	Pipeline     *Pipeline
+	Product      *Product
+	Stage        *Stage
+	User         *User
+	Raw          json.RawMessage
+	// Err is set by ListItems when Raw does not fit the model for Item. The
+	// model field is then nil.
+	Err error
+}
+
+// Decode decodes the item data into the model for its kind. Related objects
+// such as {"person_id": {"name": ..., "value": 1}} are reduced to their IDs
+// first. Unknown kinds are returned with only Raw set.
+func (r Recent) Decode() (RecentItem, error) {
+	item := RecentItem{Item: r.Item, ID: r.ID, Raw: r.Data}
+	data := bytes.TrimSpace(r.Data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return item, nil
+	}
+	data, err := flattenRelatedIDs(data)
+	if err != nil {
+		return item, fmt.Errorf("decode recent %s %d: %w", r.Item, r.ID, err)
+	}
+
+	var target interface{}
+	switch r.Item {
+	case RecentsItemActivity:
+		item.Activity = new(Activity)
+		target = item.Activity
+	case RecentsItemActivityType:
+		item.ActivityType = new(ActivityType)
+		target = item.ActivityType
+	case RecentsItemDeal:
+		item.Deal = new(Deal)
+		target = item.Deal
+	case RecentsItemFile:
+		item.File = new(File)
+		target = item.File
+	case RecentsItemFilter:
+		item.Filter = new(Filter)
+		target = item.Filter
+	case RecentsItemNote:
+		item.Note = new(Note)
+		target = item.Note
+	case RecentsItemOrganization:
+		item.Organization = new(Organization)
+		target = item.Organization
+	case RecentsItemPerson:
+		item.Person = new(Person)
+		target = item.Person
+	case RecentsItemPipeline:
+		item.Pipeline = new(Pipeline)
+		target = item.Pipeline
+	case RecentsItemProduct:
+		item.Product = new(Product)
+		target = item.Product
+	case RecentsItemStage:
+		item.Stage = new(Stage)
+		target = item.Stage
+	case RecentsItemUser:
+		item.User = new(User)
+		target = item.User
+	default:
+		return item, nil
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return RecentItem{Item: r.Item, ID: r.ID, Raw: r.Data}, fmt.Errorf("decode recent %s %d: %w", r.Item, r.ID, err)
+	}
+	return item, nil
+}
+
+// flattenRelatedIDs replaces related objects under *_id keys with their
+// value or id.
+func flattenRelatedIDs(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	changed := false
+	for key, raw := range fields {
+		if !strings.HasSuffix(key, "_id") || !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+			continue
+		}
+		var related map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &related); err != nil {
+			continue
+		}
+		if value, ok := related["value"]; ok {
+			fields[key] = value
+		} else if id, ok := related["id"]; ok {
+			fields[key] = id
+		} else {
+			continue
+		}
+		changed = true
+	}
+	if !changed {
+		return data, nil
+	}
+	return json.Marshal(fields)
+}
+
+// ListItems is List with the items decoded; see Recent.Decode. Items that
+// fail to decode are returned with Err set.
+func (s *RecentsService) ListItems(ctx context.Context, opts ...ListRecentsOption) ([]RecentItem, *RecentsAdditionalData, error) {
+	recents, additional, err := s.List(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := make([]RecentItem, 0, len(recents))
+	for _, r := range recents {
+		// One item the model cannot hold must not fail the page, or
+		// ForEachSince would never get past it.
+		item, err := r.Decode()
+		item.Err = err
+		items = append(items, item)
+	}
+	return items, additional, nil
+}
+
+// ListPager walks the pages of one since_timestamp window, which
+// WithRecentsSince is required to set.
+func (s *RecentsService) ListPager(opts ...ListRecentsOption) *pipedrive.CursorPager[RecentItem] {
+	cfg := newListRecentsOptions(opts)
+	start := 0
+	if cfg.params.Start != nil {
+		start = *cfg.params.Start
+	}
+	startCursor := strconv.Itoa(start)
+	return pipedrive.NewCursorPager(func(ctx context.Context, cursor *string) ([]RecentItem, *string, error) {
+		if cursor == nil {
+			cursor = &startCursor
+		}
+		pageStart, err := strconv.Atoi(*cursor)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid recents cursor %q", *cursor)
+		}
+		pageOpts := append(append([]ListRecentsOption(nil), opts...), WithRecentsStart(pageStart))
+		items, additional, err := s.ListItems(ctx, pageOpts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		next := nextRecentsStart(pageStart, len(items), additional)
+		if next == nil {
+			return items, nil, nil
+		}
+		value := strconv.Itoa(*next)
+		return items, &value, nil
+	})
+}
+
+func (s *RecentsService) ForEach(ctx context.Context, fn func(RecentItem) error, opts ...ListRecentsOption) error {
+	return s.ListPager(opts...).ForEach(ctx, fn)
+}
+
+// ForEachSince visits every item changed since the given time and returns
+// the start of the next window, taken from last_timestamp_on_page. When
+// nothing changed it returns since.
+func (s *RecentsService) ForEachSince(ctx context.Context, since time.Time, fn func(RecentItem) error, opts ...ListRecentsOption) (time.Time, error) {
+	next := since
+	pageOpts := append(append([]ListRecentsOption(nil), opts...), WithRecentsSince(since.UTC()))
+	for start := 0; ; {
+		items, additional, err := s.ListItems(ctx, append(pageOpts, WithRecentsStart(start))...)
+		if err != nil {
+			return next, err
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return next, err
+			}
+		}
+		if last := additional.lastTimestamp(); last.After(next) {
+			next = last
+		}
+		nextStart := nextRecentsStart(start, len(items), additional)
+		if nextStart == nil {
+			return next, nil
+		}
+		start = *nextStart
+	}
+}
+
+func nextRecentsStart(start, count int, additional *RecentsAdditionalData) *int {
+	if additional == nil || additional.Pagination == nil || !additional.Pagination.MoreItemsInCollection || count == 0 {
+		return nil
+	}
+	next := start + count
+	return &next
+}
+
+func (d *RecentsAdditionalData) lastTimestamp() time.Time {
+	if d == nil {
+		return time.Time{}
+	}
+	last, err := time.Parse(v1DateTimeLayout, d.LastTimestampOnPage)
+	if err != nil {
+		return time.Time{}
+	}
+	return last
+}
@@ -94,3 +94,89 @@ func TestRecentsService_ListRequiresSince(t *testing.T) {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestRecentDecode(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		recent Recent
+		check  func(RecentItem) bool
+	}{
+		{Recent{Item: RecentsItemDeal, ID: 1, Data: json.RawMessage(`{"id":1,"title":"Big","person_id":{"name":"Jane","value":5},"user_id":{"id":9,"name":"Owner"},"update_time":"2024-01-01 10:00:00"}`)},
+			func(i RecentItem) bool {
+				return i.Deal != nil && i.Deal.Title == "Big" && *i.Deal.PersonID == 5 && *i.Deal.OwnerID == 9 && i.Deal.UpdateTime.Hour() == 10
+			}},
+		{Recent{Item: RecentsItemPerson, ID: 5, Data: json.RawMessage(`{"id":5,"name":"Jane","org_id":{"value":3}}`)},
+			func(i RecentItem) bool { return i.Person != nil && i.Person.Name == "Jane" && *i.Person.OrgID == 3 }},
+		{Recent{Item: RecentsItemStage, ID: 2, Data: json.RawMessage(`{"id":2,"name":"Won","pipeline_id":1}`)},
+			func(i RecentItem) bool { return i.Stage != nil && i.Stage.PipelineID == 1 }},
+		{Recent{Item: RecentsItemPipeline, ID: 1, Data: json.RawMessage(`{"id":1,"name":"Sales","active":true}`)},
+			func(i RecentItem) bool { return i.Pipeline != nil && i.Pipeline.Active }},
+		{Recent{Item: RecentsItemNote, ID: 4, Data: json.RawMessage(`null`)},
+			func(i RecentItem) bool { return i.Note == nil && i.ID == 4 }},
+		{Recent{Item: "unknown", ID: 7, Data: json.RawMessage(`{"id":7}`)},
+			func(i RecentItem) bool { return i.Deal == nil && string(i.Raw) == `{"id":7}` }},
+	}
+	for _, c := range cases {
+		item, err := c.recent.Decode()
+		if err != nil {
+			t.Fatalf("Decode %s error: %v", c.recent.Item, err)
+		}
+		if !c.check(item) {
+			t.Fatalf("unexpected %s item: %#v", c.recent.Item, item)
+		}
+	}
+}
+
+func TestRecentsService_Pagers(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("start") {
+		case "0":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"item":"deal","id":1,"data":{"id":1}},{"item":"person","id":2,"data":{"id":2,"name":5}}],"additional_data":{"last_timestamp_on_page":"2024-01-01 00:00:05","pagination":{"start":0,"limit":2,"more_items_in_collection":true}}}`))
+		case "2":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"item":"product","id":3,"data":{"id":3}}],"additional_data":{"last_timestamp_on_page":"2024-01-01 00:00:09","pagination":{"start":2,"limit":2,"more_items_in_collection":false}}}`))
+		default:
+			t.Errorf("unexpected start %q", r.URL.Query().Get("start"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var ids []int64
+	if err := client.Recents.ForEach(context.Background(), func(item RecentItem) error {
+		ids = append(ids, item.ID)
+		return nil
+	}, WithRecentsSince(since), WithRecentsLimit(2)); err != nil {
+		t.Fatalf("ForEach error: %v", err)
+	}
+	if len(ids) != 3 || ids[2] != 3 {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+
+	var kinds []RecentsItemType
+	next, err := client.Recents.ForEachSince(context.Background(), since, func(item RecentItem) error {
+		kinds = append(kinds, item.Item)
+		// The malformed person does not stop the window.
+		if item.Item == RecentsItemPerson && (item.Err == nil || item.Person != nil || len(item.Raw) == 0) {
+			t.Errorf("expected person decode error with raw data: %#v", item)
+		}
+		if item.Item != RecentsItemPerson && item.Err != nil {
+			t.Errorf("unexpected decode error: %v", item.Err)
+		}
+		return nil
+	}, WithRecentsLimit(2))
+	if err != nil {
+		t.Fatalf("ForEachSince error: %v", err)
+	}
+	if len(kinds) != 3 || kinds[2] != RecentsItemProduct || !next.Equal(time.Date(2024, 1, 1, 0, 0, 9, 0, time.UTC)) {
+		t.Fatalf("unexpected window: %v, next %s", kinds, next)
+	}
+}
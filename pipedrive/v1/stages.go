@@ -9,6 +9,20 @@ import (
 	"github.com/juhokoskela/pipedrive-go/pipedrive"
 )
 
+type Stage struct {
+	ID              StageID    `json:"id,omitempty"`
+	Name            string     `json:"name,omitempty"`
+	OrderNr         int        `json:"order_nr,omitempty"`
+	Active          bool       `json:"active_flag,omitempty"`
+	DealProbability int        `json:"deal_probability,omitempty"`
+	PipelineID      PipelineID `json:"pipeline_id,omitempty"`
+	PipelineName    string     `json:"pipeline_name,omitempty"`
+	RottenFlag      bool       `json:"rotten_flag,omitempty"`
+	RottenDays      *int       `json:"rotten_days,omitempty"`
+	AddTime         *DateTime  `json:"add_time,omitempty"`
+	UpdateTime      *DateTime  `json:"update_time,omitempty"`
+}
+
 type StagesService struct {
 	client *Client
 }
@@ -153,11 +153,11 @@ type activityPayload struct {
 	subject           *string
 	activityType      *string
 	ownerID           *UserID
-	dealID            *DealID
-	leadID            *LeadID
-	personID          *PersonID
-	orgID             *OrganizationID
-	projectID         *ProjectID
+	dealID            nullableValue[DealID]
+	leadID            nullableValue[LeadID]
+	personID          nullableValue[PersonID]
+	orgID             nullableValue[OrganizationID]
+	projectID         nullableValue[ProjectID]
 	dueDate           *string
 	dueTime           *string
 	duration          *string
@@ -252,31 +252,71 @@ func WithActivityOwnerID(id UserID) ActivityOption {
 
 func WithActivityDealID(id DealID) ActivityOption {
 	return activityFieldOption(func(payload *activityPayload) {
-		payload.dealID = &id
+		payload.dealID.assign(id)
+	})
+}
+
+// ClearActivityDealID sends an explicit JSON null deal, unlinking it from the
+// activity.
+func ClearActivityDealID() ActivityOption {
+	return activityFieldOption(func(payload *activityPayload) {
+		payload.dealID.clear()
 	})
 }
 
 func WithActivityLeadID(id LeadID) ActivityOption {
 	return activityFieldOption(func(payload *activityPayload) {
-		payload.leadID = &id
+		payload.leadID.assign(id)
+	})
+}
+
+// ClearActivityLeadID sends an explicit JSON null lead, unlinking it from the
+// activity.
+func ClearActivityLeadID() ActivityOption {
+	return activityFieldOption(func(payload *activityPayload) {
+		payload.leadID.clear()
 	})
 }
 
 func WithActivityPersonID(id PersonID) ActivityOption {
 	return activityFieldOption(func(payload *activityPayload) {
-		payload.personID = &id
+		payload.personID.assign(id)
+	})
+}
+
+// ClearActivityPersonID sends an explicit JSON null person, unlinking it from the
+// activity.
+func ClearActivityPersonID() ActivityOption {
+	return activityFieldOption(func(payload *activityPayload) {
+		payload.personID.clear()
 	})
 }
 
 func WithActivityOrgID(id OrganizationID) ActivityOption {
 	return activityFieldOption(func(payload *activityPayload) {
-		payload.orgID = &id
+		payload.orgID.assign(id)
+	})
+}
+
+// ClearActivityOrgID sends an explicit JSON null organization, unlinking it from the
+// activity.
+func ClearActivityOrgID() ActivityOption {
+	return activityFieldOption(func(payload *activityPayload) {
+		payload.orgID.clear()
 	})
 }
 
 func WithActivityProjectID(id ProjectID) ActivityOption {
 	return activityFieldOption(func(payload *activityPayload) {
-		payload.projectID = &id
+		payload.projectID.assign(id)
+	})
+}
+
+// ClearActivityProjectID sends an explicit JSON null project, unlinking it from the
+// activity.
+func ClearActivityProjectID() ActivityOption {
+	return activityFieldOption(func(payload *activityPayload) {
+		payload.projectID.clear()
 	})
 }
 
@@ -568,6 +608,9 @@ func (s *ActivitiesService) ForEach(ctx context.Context, fn func(Activity) error
 
 func (s *ActivitiesService) Create(ctx context.Context, opts ...CreateActivityOption) (*Activity, error) {
 	cfg := newCreateActivityOptions(opts)
+	if err := cfg.payload.validate(); err != nil {
+		return nil, err
+	}
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap())
@@ -600,6 +643,9 @@ func (s *ActivitiesService) Update(ctx context.Context, id ActivityID, opts ...U
 		return nil, err
 	}
 	cfg := newUpdateActivityOptions(opts)
+	if err := cfg.payload.validate(); err != nil {
+		return nil, err
+	}
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap())
@@ -693,20 +739,40 @@ func (p activityPayload) toMap() map[string]interface{} {
 	if p.ownerID != nil {
 		body["owner_id"] = int(*p.ownerID)
 	}
-	if p.dealID != nil {
-		body["deal_id"] = int(*p.dealID)
+	if p.dealID.set {
+		if p.dealID.value == nil {
+			body["deal_id"] = nil
+		} else {
+			body["deal_id"] = int(*p.dealID.value)
+		}
 	}
-	if p.leadID != nil {
-		body["lead_id"] = string(*p.leadID)
+	if p.leadID.set {
+		if p.leadID.value == nil {
+			body["lead_id"] = nil
+		} else {
+			body["lead_id"] = string(*p.leadID.value)
+		}
 	}
-	if p.personID != nil {
-		body["person_id"] = int(*p.personID)
+	if p.personID.set {
+		if p.personID.value == nil {
+			body["person_id"] = nil
+		} else {
+			body["person_id"] = int(*p.personID.value)
+		}
 	}
-	if p.orgID != nil {
-		body["org_id"] = int(*p.orgID)
+	if p.orgID.set {
+		if p.orgID.value == nil {
+			body["org_id"] = nil
+		} else {
+			body["org_id"] = int(*p.orgID.value)
+		}
 	}
-	if p.projectID != nil {
-		body["project_id"] = int(*p.projectID)
+	if p.projectID.set {
+		if p.projectID.value == nil {
+			body["project_id"] = nil
+		} else {
+			body["project_id"] = int(*p.projectID.value)
+		}
 	}
 	if p.dueDate != nil {
 		body["due_date"] = *p.dueDate
@@ -0,0 +1,275 @@
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+// timestampLayouts are the layouts Pipedrive accepts for deal archive, close,
+// won and lost times.
+var timestampLayouts = []string{time.RFC3339, "2006-01-02 15:04:05"}
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func checkDate(field string, value *string) error {
+	if value == nil {
+		return nil
+	}
+	if _, err := pipedrive.ParseDate(*value); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	return nil
+}
+
+func checkTimestamp(field string, value *string) error {
+	if value == nil {
+		return nil
+	}
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, *value); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %w", field, &pipedrive.FormatError{Kind: "timestamp", Value: *value, Layout: "RFC 3339 or YYYY-MM-DD HH:MM:SS"})
+}
+
+func (p *dealPayload) validate() error {
+	if err := checkDate("expected_close_date", p.expectedCloseDate.value); err != nil {
+		return err
+	}
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"archive_time", p.archiveTime},
+		{"close_time", p.closeTime.value},
+		{"lost_time", p.lostTime},
+		{"won_time", p.wonTime},
+	} {
+		if err := checkTimestamp(field.name, field.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *dealProductPayload) validate() error {
+	return checkDate("billing_start_date", p.billingStartDate)
+}
+
+func (p *installmentPayload) validate() error {
+	return checkDate("billing_date", p.billingDate)
+}
+
+func (p *activityPayload) validate() error {
+	if err := checkDate("due_date", p.dueDate); err != nil {
+		return err
+	}
+	if p.dueTime != nil {
+		if _, err := pipedrive.ParseTimeOfDay(*p.dueTime); err != nil {
+			return fmt.Errorf("due_time: %w", err)
+		}
+	}
+	if p.duration != nil {
+		if _, err := ParseActivityDuration(*p.duration); err != nil {
+			return fmt.Errorf("duration: %w", err)
+		}
+	}
+	return nil
+}
+
+// ExpectedCloseOn parses ExpectedCloseDate. It returns the zero Date when the
+// deal has no expected close date.
+func (d Deal) ExpectedCloseOn() (pipedrive.Date, error) {
+	return parseOptionalDate(d.ExpectedCloseDate)
+}
+
+// BillingStartOn parses BillingStartDate. It returns the zero Date when the
+// product has no billing start date.
+func (p DealProduct) BillingStartOn() (pipedrive.Date, error) {
+	return parseOptionalDate(p.BillingStartDate)
+}
+
+func (i Installment) BillingOn() (pipedrive.Date, error) {
+	return parseOptionalDate(i.BillingDate)
+}
+
+func parseOptionalDate(value *string) (pipedrive.Date, error) {
+	if value == nil || *value == "" {
+		return pipedrive.Date{}, nil
+	}
+	return pipedrive.ParseDate(*value)
+}
+
+func WithDealExpectedCloseDateOn(date pipedrive.Date) DealOption {
+	return WithDealExpectedCloseDate(optionalDateString(date))
+}
+
+// WithDealArchiveTimeAt, WithDealCloseTimeAt, WithDealLostTimeAt and
+// WithDealWonTimeAt send t in UTC as RFC 3339. A zero t is ignored.
+func WithDealArchiveTimeAt(t time.Time) DealOption {
+	return WithDealArchiveTime(optionalTimestamp(t))
+}
+
+func WithDealCloseTimeAt(t time.Time) DealOption {
+	return WithDealCloseTime(optionalTimestamp(t))
+}
+
+func WithDealLostTimeAt(t time.Time) DealOption {
+	return WithDealLostTime(optionalTimestamp(t))
+}
+
+func WithDealWonTimeAt(t time.Time) DealOption {
+	return WithDealWonTime(optionalTimestamp(t))
+}
+
+func WithDealProductBillingStartDateOn(date pipedrive.Date) DealProductOption {
+	return WithDealProductBillingStartDate(optionalDateString(date))
+}
+
+func WithInstallmentBillingDateOn(date pipedrive.Date) InstallmentOption {
+	return WithInstallmentBillingDate(optionalDateString(date))
+}
+
+func optionalDateString(date pipedrive.Date) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.String()
+}
+
+func optionalTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return formatTimestamp(t)
+}
+
+// ParseActivityDuration parses an activity duration in HH:MM or HH:MM:SS
+// format. Hours may exceed 23.
+func ParseActivityDuration(s string) (time.Duration, error) {
+	invalid := &pipedrive.FormatError{Kind: "duration", Value: s, Layout: "HH:MM:SS"}
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, invalid
+	}
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	var total time.Duration
+	for i, part := range parts {
+		if len(part) < 2 || strings.TrimLeft(part, "0123456789") != "" {
+			return 0, invalid
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || (i > 0 && (len(part) != 2 || n > 59)) {
+			return 0, invalid
+		}
+		total += time.Duration(n) * units[i]
+	}
+	return total, nil
+}
+
+// FormatActivityDuration formats d as HH:MM:SS, truncated to whole seconds.
+func FormatActivityDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	seconds := int64(d / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// ActivitySchedule is an activity due date, time and duration resolved to
+// instants in a user's time zone.
+type ActivitySchedule struct {
+	// Date is the due date in the requested time zone.
+	Date  pipedrive.Date
+	Start time.Time
+	// End is Start plus the duration. All-day activities without a duration
+	// end at the following midnight.
+	End time.Time
+	// AllDay is set when the activity has a due date but no due time.
+	AllDay   bool
+	Duration time.Duration
+}
+
+// Schedule resolves the activity due date, time and duration in loc. Pipedrive
+// stores due times in UTC; all-day activities have no time and are placed at
+// midnight in loc. It returns nil when the activity has no due date. A nil loc
+// means UTC.
+func (a Activity) Schedule(loc *time.Location) (*ActivitySchedule, error) {
+	if a.DueDate == "" {
+		return nil, nil
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	date, err := pipedrive.ParseDate(a.DueDate)
+	if err != nil {
+		return nil, fmt.Errorf("due_date: %w", err)
+	}
+	schedule := &ActivitySchedule{}
+	if a.Duration != "" {
+		if schedule.Duration, err = ParseActivityDuration(a.Duration); err != nil {
+			return nil, fmt.Errorf("duration: %w", err)
+		}
+	}
+	if a.DueTime == "" {
+		schedule.AllDay = true
+		schedule.Start = date.In(loc)
+		schedule.End = schedule.Start.AddDate(0, 0, 1)
+	} else {
+		clock, err := pipedrive.ParseTimeOfDay(a.DueTime)
+		if err != nil {
+			return nil, fmt.Errorf("due_time: %w", err)
+		}
+		schedule.Start = date.At(clock, time.UTC).In(loc)
+		schedule.End = schedule.Start
+	}
+	if schedule.Duration > 0 {
+		schedule.End = schedule.Start.Add(schedule.Duration)
+	}
+	schedule.Date = pipedrive.DateOf(schedule.Start)
+	return schedule, nil
+}
+
+func WithActivityDueDateOn(date pipedrive.Date) ActivityOption {
+	return activityFieldOption(func(payload *activityPayload) {
+		value := date.String()
+		payload.dueDate = &value
+	})
+}
+
+func WithActivityDueTimeAt(clock pipedrive.TimeOfDay) ActivityOption {
+	return activityFieldOption(func(payload *activityPayload) {
+		value := clock.String()
+		payload.dueTime = &value
+	})
+}
+
+func WithActivityDurationOf(duration time.Duration) ActivityOption {
+	return activityFieldOption(func(payload *activityPayload) {
+		value := FormatActivityDuration(duration)
+		payload.duration = &value
+	})
+}
+
+// WithActivitySchedule sets the due date and time from start, converted to
+// UTC as Pipedrive expects, and the duration when it is positive.
+func WithActivitySchedule(start time.Time, duration time.Duration) ActivityOption {
+	return activityFieldOption(func(payload *activityPayload) {
+		utc := start.UTC()
+		date := pipedrive.DateOf(utc).String()
+		clock := pipedrive.TimeOfDayOf(utc).String()
+		payload.dueDate = &date
+		payload.dueTime = &clock
+		if duration > 0 {
+			value := FormatActivityDuration(duration)
+			payload.duration = &value
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+func TestDealsService_CreateWithTypedDates(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"id":1,"expected_close_date":"2024-06-10"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	won := time.Date(2024, 6, 14, 12, 0, 0, 0, time.FixedZone("EEST", 3*60*60))
+	deal, err := client.Deals.Create(context.Background(),
+		WithDealTitle("Deal"),
+		WithDealExpectedCloseDateOn(pipedrive.Date{Year: 2024, Month: time.June, Day: 10}),
+		WithDealWonTimeAt(won),
+	)
+	if err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	if body["expected_close_date"] != "2024-06-10" || body["won_time"] != "2024-06-14T09:00:00Z" {
+		t.Fatalf("unexpected body: %#v", body)
+	}
+	if date, err := deal.ExpectedCloseOn(); err != nil || date.String() != "2024-06-10" {
+		t.Fatalf("ExpectedCloseOn = %v, %v", date, err)
+	}
+}
+
+func TestDateOptionsAreValidatedBeforeRequest(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	ctx := context.Background()
+
+	var formatErr *pipedrive.FormatError
+	if _, err := client.Deals.Update(ctx, 1, WithDealExpectedCloseDate("10.06.2024")); !errors.As(err, &formatErr) || formatErr.Kind != "date" {
+		t.Fatalf("expected date error, got %v", err)
+	}
+	if _, err := client.Deals.Create(ctx, WithDealCloseTime("yesterday")); !errors.As(err, &formatErr) || formatErr.Kind != "timestamp" {
+		t.Fatalf("expected timestamp error, got %v", err)
+	}
+	if _, err := client.Deals.AddInstallment(ctx, 1, WithInstallmentBillingDate("2024-02-30")); !errors.As(err, &formatErr) {
+		t.Fatalf("expected installment date error, got %v", err)
+	}
+	if _, err := client.Activities.Create(ctx, WithActivityDueTime("25:00")); !errors.As(err, &formatErr) || formatErr.Kind != "time" {
+		t.Fatalf("expected time error, got %v", err)
+	}
+	if _, err := client.Activities.Create(ctx, WithActivityDuration("1h")); !errors.As(err, &formatErr) || formatErr.Kind != "duration" {
+		t.Fatalf("expected duration error, got %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 0 {
+		t.Fatalf("expected no requests, got %d", n)
+	}
+}
+
+func TestActivitySchedule(t *testing.T) {
+	t.Parallel()
+
+	helsinki := time.FixedZone("EET", 2*60*60)
+	schedule, err := Activity{DueDate: "2024-01-05", DueTime: "23:30", Duration: "01:00:00"}.Schedule(helsinki)
+	if err != nil {
+		t.Fatalf("Schedule error: %v", err)
+	}
+	if schedule.AllDay || schedule.Date.String() != "2024-01-06" || schedule.Start.Hour() != 1 || schedule.End.Sub(schedule.Start) != time.Hour {
+		t.Fatalf("unexpected schedule: %#v", schedule)
+	}
+
+	allDay, err := Activity{DueDate: "2024-01-05"}.Schedule(helsinki)
+	if err != nil || !allDay.AllDay || !allDay.Start.Equal(time.Date(2024, 1, 5, 0, 0, 0, 0, helsinki)) || allDay.End.Sub(allDay.Start) != 24*time.Hour {
+		t.Fatalf("unexpected all-day schedule: %#v, %v", allDay, err)
+	}
+
+	if none, err := (Activity{}).Schedule(nil); none != nil || err != nil {
+		t.Fatalf("expected no schedule, got %#v, %v", none, err)
+	}
+
+	cfg := newCreateActivityOptions([]CreateActivityOption{WithActivitySchedule(schedule.Start, 90*time.Minute)})
+	body := cfg.payload.toMap()
+	if body["due_date"] != "2024-01-05" || body["due_time"] != "23:30:00" || body["duration"] != "01:30:00" {
+		t.Fatalf("unexpected body: %#v", body)
+	}
+}
+
+func TestActivityDuration(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]time.Duration{"00:30": 30 * time.Minute, "01:00:00": time.Hour, "26:15:05": 26*time.Hour + 15*time.Minute + 5*time.Second} {
+		got, err := ParseActivityDuration(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseActivityDuration(%q) = %v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "1:00", "01", "01:60", "01:00:00:00", "-1:00"} {
+		if _, err := ParseActivityDuration(in); err == nil {
+			t.Fatalf("ParseActivityDuration(%q): expected error", in)
+		}
+	}
+	if got := FormatActivityDuration(26*time.Hour + 90*time.Second); got != "26:01:30" {
+		t.Fatalf("FormatActivityDuration = %s", got)
+	}
+}
@@ -0,0 +1,121 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+// ConversionError is returned when a deal or lead conversion job ends as
+// failed or rejected, or completes without the converted entity.
+type ConversionError struct {
+	ConversionID ConversionID
+	Status       ConversionStatus
+}
+
+func (e *ConversionError) Error() string {
+	if e == nil {
+		return "conversion failed"
+	}
+	if e.Status == ConversionStatusCompleted {
+		return fmt.Sprintf("conversion %s completed without a result", e.ConversionID)
+	}
+	return fmt.Sprintf("conversion %s %s", e.ConversionID, e.Status)
+}
+
+type ConversionWaitOption interface {
+	ConvertDealOption
+	ConvertLeadOption
+}
+
+type conversionPollPolicy struct {
+	policy pipedrive.PollPolicy
+}
+
+func (o conversionPollPolicy) applyConvertDeal(cfg *convertDealOptions) {
+	cfg.pollPolicy = &o.policy
+}
+
+func (o conversionPollPolicy) applyConvertLead(cfg *convertLeadOptions) {
+	cfg.pollPolicy = &o.policy
+}
+
+// WithConversionPollPolicy sets how ConvertToLeadAndWait and
+// ConvertToDealAndWait poll the conversion status. The default is
+// pipedrive.DefaultPollPolicy.
+func WithConversionPollPolicy(policy pipedrive.PollPolicy) ConversionWaitOption {
+	return conversionPollPolicy{policy: policy}
+}
+
+func conversionDone(conversionID ConversionID, status ConversionStatus) (bool, error) {
+	switch status {
+	case ConversionStatusCompleted:
+		return true, nil
+	case ConversionStatusFailed, ConversionStatusRejected:
+		return true, &ConversionError{ConversionID: conversionID, Status: status}
+	default:
+		return false, nil
+	}
+}
+
+func pollPolicyOrDefault(policy *pipedrive.PollPolicy) pipedrive.PollPolicy {
+	if policy == nil {
+		return pipedrive.DefaultPollPolicy()
+	}
+	return *policy
+}
+
+// ConvertToLeadAndWait starts converting the deal to a lead and polls the
+// conversion status until the job finishes. Unlike ConvertToDealAndWait it
+// returns only the ID of the new lead, not the lead itself: leads are read
+// through the v1 API, which this client does not reach. Pass the ID to the v1
+// LeadsService.Get to load the lead. Bound the wait with a context deadline.
+func (s *DealsService) ConvertToLeadAndWait(ctx context.Context, id DealID, opts ...ConvertDealOption) (LeadID, error) {
+	job, err := s.ConvertToLead(ctx, id, opts...)
+	if err != nil {
+		return "", err
+	}
+	cfg := newConvertDealOptions(opts)
+	status, err := pipedrive.PollUntil(ctx, pollPolicyOrDefault(cfg.pollPolicy), func(ctx context.Context) (*DealConversionStatus, bool, error) {
+		status, err := s.ConversionStatus(ctx, id, job.ConversionID, WithDealRequestOptions(cfg.requestOptions...))
+		if err != nil {
+			return nil, false, err
+		}
+		done, err := conversionDone(job.ConversionID, status.Status)
+		return status, done, err
+	})
+	if err != nil {
+		return "", err
+	}
+	if status.LeadID == nil || *status.LeadID == "" {
+		return "", &ConversionError{ConversionID: job.ConversionID, Status: status.Status}
+	}
+	return *status.LeadID, nil
+}
+
+// ConvertToDealAndWait starts converting the lead to a deal, polls the
+// conversion status until the job finishes and returns the new deal. Bound the
+// wait with a context deadline.
+func (s *LeadsService) ConvertToDealAndWait(ctx context.Context, id LeadID, opts ...ConvertLeadOption) (*Deal, error) {
+	job, err := s.ConvertToDeal(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	cfg := newConvertLeadOptions(opts)
+	status, err := pipedrive.PollUntil(ctx, pollPolicyOrDefault(cfg.pollPolicy), func(ctx context.Context) (*LeadConversionStatus, bool, error) {
+		status, err := s.ConversionStatus(ctx, id, job.ConversionID, WithLeadRequestOptions(cfg.requestOptions...))
+		if err != nil {
+			return nil, false, err
+		}
+		done, err := conversionDone(job.ConversionID, status.Status)
+		return status, done, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status.DealID == nil {
+		return nil, &ConversionError{ConversionID: job.ConversionID, Status: status.Status}
+	}
+	return s.client.Deals.Get(ctx, *status.DealID, WithDealRequestOptions(cfg.requestOptions...))
+}
@@ -0,0 +1,91 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+var fastPoll = WithConversionPollPolicy(pipedrive.PollPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+func TestLeadsService_ConvertToDealAndWait(t *testing.T) {
+	t.Parallel()
+
+	leadID := "123e4567-e89b-12d3-a456-426614174000"
+	conversionID := "223e4567-e89b-12d3-a456-426614174111"
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Test"); got != "1" {
+			t.Fatalf("missing request option header on %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/leads/" + leadID + "/convert/deal":
+			_, _ = w.Write([]byte(`{"data":{"conversion_id":"` + conversionID + `"}}`))
+		case "/leads/" + leadID + "/convert/status/" + conversionID:
+			polls++
+			status := "running"
+			if polls == 3 {
+				status = "completed"
+			}
+			_, _ = w.Write([]byte(`{"data":{"conversion_id":"` + conversionID + `","status":"` + status + `","deal_id":5}}`))
+		case "/deals/5":
+			_, _ = w.Write([]byte(`{"data":{"id":5,"title":"Converted"}}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	deal, err := client.Leads.ConvertToDealAndWait(context.Background(), LeadID(leadID), fastPoll, WithLeadRequestOptions(pipedrive.WithHeader("X-Test", "1")))
+	if err != nil {
+		t.Fatalf("ConvertToDealAndWait error: %v", err)
+	}
+	if deal.ID != 5 || deal.Title != "Converted" || polls != 3 {
+		t.Fatalf("unexpected deal %#v after %d polls", deal, polls)
+	}
+}
+
+func TestDealsService_ConvertToLeadAndWait(t *testing.T) {
+	t.Parallel()
+
+	conversionID := "223e4567-e89b-12d3-a456-426614174111"
+	status := "completed"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/deals/7/convert/lead":
+			_, _ = w.Write([]byte(`{"data":{"conversion_id":"` + conversionID + `"}}`))
+		case "/deals/7/convert/status/" + conversionID:
+			_, _ = w.Write([]byte(`{"data":{"conversion_id":"` + conversionID + `","status":"` + status + `","lead_id":"323e4567-e89b-12d3-a456-426614174222"}}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	leadID, err := client.Deals.ConvertToLeadAndWait(context.Background(), 7, fastPoll)
+	if err != nil || leadID != "323e4567-e89b-12d3-a456-426614174222" {
+		t.Fatalf("ConvertToLeadAndWait = %q, %v", leadID, err)
+	}
+
+	status = "rejected"
+	_, err = client.Deals.ConvertToLeadAndWait(context.Background(), 7, fastPoll)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.Status != ConversionStatusRejected {
+		t.Fatalf("expected rejected conversion error, got %v", err)
+	}
+}
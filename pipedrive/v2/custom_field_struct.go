@@ -0,0 +1,385 @@
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// customFieldTag is the struct tag naming the custom field a Go struct field
+// maps to, e.g. `pipedrive:"Region"` or `pipedrive:"ARR band,omitempty"`. The
+// name may also be the field's hash key.
+const customFieldTag = "pipedrive"
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	fieldOptionType = reflect.TypeOf(FieldOption{})
+)
+
+type taggedCustomField struct {
+	ref       string
+	index     []int
+	omitEmpty bool
+}
+
+// UnmarshalCustomFields copies a CustomFields map into the struct pointed to
+// by dst, using `pipedrive:"Field Name"` tags to pick the custom field for
+// each struct field. Every tagged name must exist in the account; values
+// absent from customFields leave the struct field untouched.
+//
+// Enum and set options decode into string labels, integer IDs or
+// FieldOption values. An option missing from the definitions cannot become a
+// label and fails with *FieldOptionNotFoundError. Monetary fields decode into
+// a float amount or MonetaryValue. Date fields decode into time.Time or a
+// "2006-01-02" string. Pointer fields are set to nil for null values.
+func (r *CustomFieldResolver) UnmarshalCustomFields(customFields map[string]interface{}, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("custom fields destination must be a non-nil struct pointer, got %T", dst)
+	}
+	tagged, err := taggedCustomFields(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	for _, tf := range tagged {
+		field, err := r.Lookup(tf.ref)
+		if err != nil {
+			return err
+		}
+		raw, ok := customFields[field.FieldCode]
+		if !ok {
+			continue
+		}
+		value, err := r.DecodeValue(field.FieldCode, raw)
+		if err != nil {
+			return err
+		}
+		target := rv.Elem().FieldByIndex(tf.index)
+		if err := assignCustomFieldValue(target, value.Value); err != nil {
+			return customFieldAssignError(field.FieldName, err)
+		}
+	}
+	return nil
+}
+
+// MarshalCustomFields builds a hash-keyed custom field map from the tagged
+// fields of src, suitable for WithDealCustomFieldsMap and the other
+// CustomFieldsMap options. Option labels are resolved to option IDs. Nil
+// pointers clear the field unless the tag has omitempty, which also skips
+// zero values.
+func (r *CustomFieldResolver) MarshalCustomFields(src interface{}) (map[string]interface{}, error) {
+	rv := reflect.ValueOf(src)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("custom fields source must not be nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("custom fields source must be a struct, got %T", src)
+	}
+	tagged, err := taggedCustomFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{}, len(tagged))
+	for _, tf := range tagged {
+		field, err := r.Lookup(tf.ref)
+		if err != nil {
+			return nil, err
+		}
+		v := rv.FieldByIndex(tf.index)
+		if tf.omitEmpty && v.IsZero() {
+			continue
+		}
+		if _, dup := values[field.FieldCode]; dup {
+			return nil, fmt.Errorf("custom field %q tagged more than once", field.FieldName)
+		}
+		values[field.FieldCode] = customFieldGoValue(field, v)
+	}
+	return r.Encode(values)
+}
+
+// GetCustomField decodes a single custom field into the value pointed to by
+// dst, following the same conversion rules as UnmarshalCustomFields. The
+// boolean result reports whether customFields contains the field.
+func (r *CustomFieldResolver) GetCustomField(customFields map[string]interface{}, ref string, dst interface{}) (bool, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return false, fmt.Errorf("custom field destination must be a non-nil pointer, got %T", dst)
+	}
+	value, ok, err := r.Value(customFields, ref)
+	if err != nil || !ok {
+		return ok, err
+	}
+	if err := assignCustomFieldValue(rv.Elem(), value.Value); err != nil {
+		return true, customFieldAssignError(value.Name, err)
+	}
+	return true, nil
+}
+
+// SetCustomField encodes value into customFields under the field's hash key,
+// following the same conversion rules as MarshalCustomFields.
+func (r *CustomFieldResolver) SetCustomField(customFields map[string]interface{}, ref string, value interface{}) error {
+	if customFields == nil {
+		return fmt.Errorf("custom fields map must not be nil")
+	}
+	field, err := r.Lookup(ref)
+	if err != nil {
+		return err
+	}
+	encoded, err := r.Encode(map[string]interface{}{
+		field.FieldCode: customFieldGoValue(field, reflect.ValueOf(value)),
+	})
+	if err != nil {
+		return err
+	}
+	customFields[field.FieldCode] = encoded[field.FieldCode]
+	return nil
+}
+
+func taggedCustomFields(t reflect.Type) ([]taggedCustomField, error) {
+	var out []taggedCustomField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, hasTag := sf.Tag.Lookup(customFieldTag)
+		if tag == "-" {
+			continue
+		}
+		if !hasTag {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				nested, err := taggedCustomFields(sf.Type)
+				if err != nil {
+					return nil, err
+				}
+				for _, n := range nested {
+					n.index = append([]int{i}, n.index...)
+					out = append(out, n)
+				}
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			return nil, fmt.Errorf("custom field tag on unexported struct field %s", sf.Name)
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("empty custom field name in tag on struct field %s", sf.Name)
+		}
+		tf := taggedCustomField{ref: name, index: []int{i}}
+		for _, opt := range strings.Split(opts, ",") {
+			switch strings.TrimSpace(opt) {
+			case "":
+			case "omitempty":
+				tf.omitEmpty = true
+			default:
+				return nil, fmt.Errorf("unknown custom field tag option %q on struct field %s", opt, sf.Name)
+			}
+		}
+		out = append(out, tf)
+	}
+	return out, nil
+}
+
+func assignCustomFieldValue(target reflect.Value, value interface{}) error {
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+	if target.Kind() == reflect.Pointer {
+		elem := reflect.New(target.Type().Elem())
+		if err := assignCustomFieldValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		target.Set(elem)
+		return nil
+	}
+	// Interface fields such as interface{} take any value they are
+	// assignable from; others fall through to the type mismatch error.
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(target.Type()) {
+		target.Set(src)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case FieldOption:
+		if target.Kind() == reflect.String {
+			label, err := fieldOptionLabel(v)
+			if err != nil {
+				return err
+			}
+			target.SetString(label)
+			return nil
+		}
+		if isIntKind(target.Kind()) {
+			target.SetInt(int64(v.ID))
+			return nil
+		}
+	case []FieldOption:
+		if target.Kind() == reflect.Slice {
+			return assignFieldOptions(target, v)
+		}
+	case MonetaryValue:
+		if isFloatKind(target.Kind()) {
+			target.SetFloat(v.Amount)
+			return nil
+		}
+	case time.Time:
+		if target.Kind() == reflect.String {
+			target.SetString(v.Format(customFieldDateLayout))
+			return nil
+		}
+	}
+
+	switch {
+	case isIntKind(target.Kind()):
+		n, ok := customFieldInt(src)
+		if ok {
+			target.SetInt(n)
+			return nil
+		}
+	case isFloatKind(target.Kind()):
+		if src.CanFloat() {
+			target.SetFloat(src.Float())
+			return nil
+		}
+		if src.CanInt() {
+			target.SetFloat(float64(src.Int()))
+			return nil
+		}
+	case target.Kind() == reflect.String && src.Kind() == reflect.String:
+		target.SetString(src.String())
+		return nil
+	}
+	return fmt.Errorf("cannot decode %T into %s", value, target.Type())
+}
+
+func assignFieldOptions(target reflect.Value, options []FieldOption) error {
+	elemType := target.Type().Elem()
+	out := reflect.MakeSlice(target.Type(), 0, len(options))
+	for _, option := range options {
+		elem := reflect.New(elemType).Elem()
+		switch {
+		case elemType == fieldOptionType:
+			elem.Set(reflect.ValueOf(option))
+		case elemType.Kind() == reflect.String:
+			label, err := fieldOptionLabel(option)
+			if err != nil {
+				return err
+			}
+			elem.SetString(label)
+		case isIntKind(elemType.Kind()):
+			elem.SetInt(int64(option.ID))
+		default:
+			return fmt.Errorf("cannot decode options into %s", target.Type())
+		}
+		out = reflect.Append(out, elem)
+	}
+	target.Set(out)
+	return nil
+}
+
+// fieldOptionLabel returns the label of a decoded option. Options deleted
+// since the definitions were fetched decode with only their ID.
+func fieldOptionLabel(option FieldOption) (string, error) {
+	if option.Label == "" && option.ID != 0 {
+		return "", &FieldOptionNotFoundError{Label: strconv.Itoa(option.ID)}
+	}
+	return option.Label, nil
+}
+
+func customFieldAssignError(name string, err error) error {
+	var optionErr *FieldOptionNotFoundError
+	if errors.As(err, &optionErr) {
+		optionErr.Field = name
+		return optionErr
+	}
+	return fmt.Errorf("custom field %q: %w", name, err)
+}
+
+func customFieldInt(v reflect.Value) (int64, bool) {
+	switch {
+	case v.CanInt():
+		return v.Int(), true
+	case v.CanFloat():
+		f := v.Float()
+		if f != math.Trunc(f) {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
+
+// customFieldGoValue converts a struct field into a value Encode understands
+// for the given field type.
+func customFieldGoValue(field *Field, v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch field.FieldType {
+	case FieldTypeEnum:
+		// The zero option ID clears the field, like the empty label.
+		if isIntKind(v.Kind()) {
+			if v.Int() == 0 {
+				return nil
+			}
+			return FieldOption{ID: int(v.Int())}
+		}
+		if v.Kind() == reflect.String {
+			if v.String() == "" {
+				return nil
+			}
+			return v.String()
+		}
+	case FieldTypeMonetary:
+		if isFloatKind(v.Kind()) {
+			return MonetaryValue{Amount: v.Float()}
+		}
+	case FieldTypeSet:
+		if v.Kind() == reflect.Slice && v.Type().Elem() != fieldOptionType {
+			options := make([]FieldOption, 0, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				elem := v.Index(i)
+				switch {
+				case isIntKind(elem.Kind()):
+					options = append(options, FieldOption{ID: int(elem.Int())})
+				case elem.Kind() == reflect.String:
+					options = append(options, FieldOption{Label: elem.String()})
+				default:
+					return v.Interface()
+				}
+			}
+			return options
+		}
+	case FieldTypeDate:
+		if v.Type() == timeType && v.Interface().(time.Time).IsZero() {
+			return nil
+		}
+	}
+	return v.Interface()
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
@@ -0,0 +1,177 @@
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type customFieldsTestDeal struct {
+	Budget  float64    `pipedrive:"Budget"`
+	Launch  *time.Time `pipedrive:"Launch date"`
+	Tier    string     `pipedrive:"Tier"`
+	TierID  int        `pipedrive:"h_tier,omitempty"`
+	Tags    []string   `pipedrive:"Tags"`
+	Seats   *int       `pipedrive:"Seats"`
+	Ignored string
+}
+
+func TestCustomFieldResolver_UnmarshalCustomFields(t *testing.T) {
+	t.Parallel()
+
+	resolver := newTestCustomFieldResolver(t)
+	var custom map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+		"h_budget":{"value":250,"currency":"EUR"},
+		"h_launch":"2026-05-04",
+		"h_tier":1,
+		"h_tags":[11],
+		"h_seats":null
+	}`), &custom); err != nil {
+		t.Fatalf("unmarshal custom fields: %v", err)
+	}
+
+	seats := 3
+	deal := customFieldsTestDeal{Seats: &seats}
+	if err := resolver.UnmarshalCustomFields(custom, &deal); err != nil {
+		t.Fatalf("UnmarshalCustomFields error: %v", err)
+	}
+	if deal.Budget != 250 || deal.Tier != "Gold" || deal.TierID != 1 {
+		t.Fatalf("unexpected deal: %#v", deal)
+	}
+	if deal.Launch == nil || deal.Launch.Format("2006-01-02") != "2026-05-04" {
+		t.Fatalf("unexpected launch: %v", deal.Launch)
+	}
+	if len(deal.Tags) != 1 || deal.Tags[0] != "Cold" {
+		t.Fatalf("unexpected tags: %#v", deal.Tags)
+	}
+	if deal.Seats != nil {
+		t.Fatalf("expected null seats to clear pointer")
+	}
+}
+
+func TestCustomFieldResolver_MarshalCustomFields(t *testing.T) {
+	t.Parallel()
+
+	resolver := newTestCustomFieldResolver(t)
+	out, err := resolver.MarshalCustomFields(customFieldsTestDeal{
+		Budget: 10.5,
+		Tier:   "silver",
+		Tags:   []string{"Hot", "Cold"},
+	})
+	if err != nil {
+		t.Fatalf("MarshalCustomFields error: %v", err)
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"h_budget":{"value":10.5},"h_launch":null,"h_seats":null,"h_tags":[10,11],"h_tier":2}`
+	if string(data) != want {
+		t.Fatalf("unexpected payload:\n got %s\nwant %s", data, want)
+	}
+}
+
+func TestCustomFieldResolver_StructErrors(t *testing.T) {
+	t.Parallel()
+
+	resolver := newTestCustomFieldResolver(t)
+
+	var renamed struct {
+		Region string `pipedrive:"Region"`
+	}
+	err := resolver.UnmarshalCustomFields(map[string]interface{}{}, &renamed)
+	var notFound *CustomFieldNotFoundError
+	if !errors.As(err, &notFound) || notFound.Name != "Region" {
+		t.Fatalf("expected CustomFieldNotFoundError, got %v", err)
+	}
+
+	_, err = resolver.MarshalCustomFields(struct {
+		Tier string `pipedrive:"Tier"`
+	}{Tier: "Platinum"})
+	var optionErr *FieldOptionNotFoundError
+	if !errors.As(err, &optionErr) || optionErr.Field != "Tier" || optionErr.Label != "Platinum" {
+		t.Fatalf("expected FieldOptionNotFoundError, got %v", err)
+	}
+
+	var deleted struct {
+		Tier string   `pipedrive:"Tier"`
+		Tags []string `pipedrive:"Tags"`
+	}
+	err = resolver.UnmarshalCustomFields(map[string]interface{}{"h_tier": float64(99)}, &deleted)
+	if !errors.As(err, &optionErr) || optionErr.Field != "Tier" || optionErr.Label != "99" {
+		t.Fatalf("expected FieldOptionNotFoundError for deleted option, got %v", err)
+	}
+	err = resolver.UnmarshalCustomFields(map[string]interface{}{"h_tags": []interface{}{float64(10), float64(98)}}, &deleted)
+	if !errors.As(err, &optionErr) || optionErr.Field != "Tags" || optionErr.Label != "98" {
+		t.Fatalf("expected FieldOptionNotFoundError for deleted set option, got %v", err)
+	}
+	var deletedID struct {
+		Tier int `pipedrive:"Tier"`
+	}
+	if err := resolver.UnmarshalCustomFields(map[string]interface{}{"h_tier": float64(99)}, &deletedID); err != nil || deletedID.Tier != 99 {
+		t.Fatalf("expected deleted option ID to decode, got %d, %v", deletedID.Tier, err)
+	}
+
+	cleared, err := resolver.MarshalCustomFields(struct {
+		Tier int `pipedrive:"Tier"`
+	}{})
+	if err != nil {
+		t.Fatalf("MarshalCustomFields error: %v", err)
+	}
+	if got, ok := cleared["h_tier"]; !ok || got != nil {
+		t.Fatalf("expected zero option ID to clear the field: %#v", cleared)
+	}
+
+	var mismatched struct {
+		Tier bool `pipedrive:"Tier"`
+	}
+	if err := resolver.UnmarshalCustomFields(map[string]interface{}{"h_tier": float64(1)}, &mismatched); err == nil {
+		t.Fatalf("expected type mismatch error")
+	}
+
+	var stringer struct {
+		Tier fmt.Stringer `pipedrive:"Tier"`
+	}
+	if err := resolver.UnmarshalCustomFields(map[string]interface{}{"h_tier": float64(1)}, &stringer); err == nil || !strings.Contains(err.Error(), "cannot decode") {
+		t.Fatalf("expected type mismatch error for interface field, got %v", err)
+	}
+	var anyTier struct {
+		Tier interface{} `pipedrive:"Tier"`
+	}
+	if err := resolver.UnmarshalCustomFields(map[string]interface{}{"h_tier": float64(1)}, &anyTier); err != nil || anyTier.Tier == nil {
+		t.Fatalf("expected interface{} field to take the value, got %#v, %v", anyTier.Tier, err)
+	}
+}
+
+func TestCustomFieldResolver_GetSetCustomField(t *testing.T) {
+	t.Parallel()
+
+	resolver := newTestCustomFieldResolver(t)
+	custom := map[string]interface{}{}
+	if err := resolver.SetCustomField(custom, "Tier", "Silver"); err != nil {
+		t.Fatalf("SetCustomField error: %v", err)
+	}
+	if err := resolver.SetCustomField(custom, "Seats", nil); err != nil {
+		t.Fatalf("SetCustomField error: %v", err)
+	}
+	if got, ok := custom["h_tier"]; !ok || got != 2 {
+		t.Fatalf("unexpected tier: %#v", custom)
+	}
+	if got, ok := custom["h_seats"]; !ok || got != nil {
+		t.Fatalf("unexpected seats: %#v", custom)
+	}
+
+	var tier string
+	ok, err := resolver.GetCustomField(map[string]interface{}{"h_tier": float64(2)}, "Tier", &tier)
+	if err != nil || !ok || tier != "Silver" {
+		t.Fatalf("unexpected tier: %q, %v, %v", tier, ok, err)
+	}
+	ok, err = resolver.GetCustomField(map[string]interface{}{}, "Tier", &tier)
+	if err != nil || ok {
+		t.Fatalf("expected missing field, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,716 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+const customFieldDateLayout = "2006-01-02"
+
+// MonetaryValue is the decoded form of a monetary custom field.
+type MonetaryValue struct {
+	Amount   float64
+	Currency string
+}
+
+// RangeValue is the decoded form of daterange and timerange custom fields.
+// Start and End keep Pipedrive's wire layout ("2006-01-02" for dates,
+// "15:04:05" for times).
+type RangeValue struct {
+	Start string
+	End   string
+}
+
+// CustomFieldValue is a single custom field value decoded against its field
+// definition. Value holds a typed Go value chosen by the field type:
+//
+//	int                          int64
+//	double                       float64
+//	boolean                      bool
+//	varchar, text, phone, ...    string
+//	monetary                     MonetaryValue
+//	date                         time.Time (UTC midnight)
+//	time                         string
+//	daterange, timerange         RangeValue
+//	enum                         FieldOption
+//	set                          []FieldOption
+//	address                      OrganizationAddress
+//	user                         UserID
+//	org                          OrganizationID
+//	people                       PersonID
+//	deal                         DealID
+//	lead                         LeadID
+//	project                      ProjectID
+//	stage                        StageID
+//	activity                     ActivityID
+//
+// Unknown field types and keys without a definition keep the raw JSON value.
+type CustomFieldValue struct {
+	Key   string
+	Name  string
+	Type  FieldType
+	Value interface{}
+	Raw   interface{}
+}
+
+func (v CustomFieldValue) IsNull() bool {
+	return v.Raw == nil
+}
+
+func (v CustomFieldValue) String() (string, bool) {
+	s, ok := v.Value.(string)
+	return s, ok
+}
+
+func (v CustomFieldValue) Float() (float64, bool) {
+	switch n := v.Value.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func (v CustomFieldValue) Monetary() (MonetaryValue, bool) {
+	m, ok := v.Value.(MonetaryValue)
+	return m, ok
+}
+
+func (v CustomFieldValue) Date() (time.Time, bool) {
+	t, ok := v.Value.(time.Time)
+	return t, ok
+}
+
+func (v CustomFieldValue) Range() (RangeValue, bool) {
+	r, ok := v.Value.(RangeValue)
+	return r, ok
+}
+
+func (v CustomFieldValue) Option() (FieldOption, bool) {
+	o, ok := v.Value.(FieldOption)
+	return o, ok
+}
+
+func (v CustomFieldValue) Options() ([]FieldOption, bool) {
+	o, ok := v.Value.([]FieldOption)
+	return o, ok
+}
+
+func (v CustomFieldValue) Address() (OrganizationAddress, bool) {
+	a, ok := v.Value.(OrganizationAddress)
+	return a, ok
+}
+
+// CustomFieldNotFoundError reports a field name that does not match exactly
+// one custom field in the account, typically because the field was renamed
+// or deleted after the calling code was written.
+type CustomFieldNotFoundError struct {
+	Name      string
+	Ambiguous bool
+}
+
+func (e *CustomFieldNotFoundError) Error() string {
+	if e == nil {
+		return "custom field not found"
+	}
+	if e.Ambiguous {
+		return fmt.Sprintf("custom field name %q matches more than one field; refer to it by key", e.Name)
+	}
+	return fmt.Sprintf("custom field %q not found; it may have been renamed or deleted", e.Name)
+}
+
+type FieldOptionNotFoundError struct {
+	Field string
+	Label string
+}
+
+func (e *FieldOptionNotFoundError) Error() string {
+	if e == nil {
+		return "field option not found"
+	}
+	return fmt.Sprintf("option %q not found for custom field %q; it may have been renamed or deleted", e.Label, e.Field)
+}
+
+// CustomFieldResolver maps custom field hash keys to their definitions and
+// converts values between Pipedrive's wire form and typed Go values.
+type CustomFieldResolver struct {
+	byKey  map[string]*Field
+	byName map[string]*Field
+	// ambiguous records names shared by more than one custom field; lookups
+	// by those names fail instead of silently picking one of the fields.
+	ambiguous map[string]struct{}
+}
+
+// NewCustomFieldResolver indexes field definitions returned by any of the
+// field services. Only custom fields are indexed; built-in fields never
+// appear in CustomFields maps.
+func NewCustomFieldResolver(fields []Field) *CustomFieldResolver {
+	r := &CustomFieldResolver{
+		byKey:     make(map[string]*Field),
+		byName:    make(map[string]*Field),
+		ambiguous: make(map[string]struct{}),
+	}
+	for i := range fields {
+		field := fields[i]
+		if !field.IsCustomField || field.FieldCode == "" {
+			continue
+		}
+		r.byKey[field.FieldCode] = &field
+		name := normalizeFieldName(field.FieldName)
+		if name == "" {
+			continue
+		}
+		if _, exists := r.byName[name]; exists {
+			r.ambiguous[name] = struct{}{}
+			continue
+		}
+		r.byName[name] = &field
+	}
+	return r
+}
+
+func (s *DealFieldsService) Resolver(ctx context.Context, opts ...ListDealFieldsOption) (*CustomFieldResolver, error) {
+	return newCustomFieldResolverFromPager(ctx, s.ListPager(opts...))
+}
+
+func (s *PersonFieldsService) Resolver(ctx context.Context, opts ...ListPersonFieldsOption) (*CustomFieldResolver, error) {
+	return newCustomFieldResolverFromPager(ctx, s.ListPager(opts...))
+}
+
+func (s *OrganizationFieldsService) Resolver(ctx context.Context, opts ...ListOrganizationFieldsOption) (*CustomFieldResolver, error) {
+	return newCustomFieldResolverFromPager(ctx, s.ListPager(opts...))
+}
+
+func (s *ProductFieldsService) Resolver(ctx context.Context, opts ...ListProductFieldsOption) (*CustomFieldResolver, error) {
+	return newCustomFieldResolverFromPager(ctx, s.ListPager(opts...))
+}
+
+func (s *ActivityFieldsService) Resolver(ctx context.Context, opts ...ListActivityFieldsOption) (*CustomFieldResolver, error) {
+	return newCustomFieldResolverFromPager(ctx, s.ListPager(opts...))
+}
+
+func (s *ProjectFieldsService) Resolver(ctx context.Context, opts ...ListProjectFieldsOption) (*CustomFieldResolver, error) {
+	return newCustomFieldResolverFromPager(ctx, s.ListPager(opts...))
+}
+
+func newCustomFieldResolverFromPager(ctx context.Context, pager *pipedrive.CursorPager[Field]) (*CustomFieldResolver, error) {
+	var fields []Field
+	if err := pager.ForEach(ctx, func(f Field) error {
+		fields = append(fields, f)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return NewCustomFieldResolver(fields), nil
+}
+
+// Fields returns the indexed custom field definitions ordered by name.
+func (r *CustomFieldResolver) Fields() []Field {
+	out := make([]Field, 0, len(r.byKey))
+	for _, field := range r.byKey {
+		out = append(out, *field)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].FieldName != out[j].FieldName {
+			return out[i].FieldName < out[j].FieldName
+		}
+		return out[i].FieldCode < out[j].FieldCode
+	})
+	return out
+}
+
+func (r *CustomFieldResolver) Field(key string) (*Field, bool) {
+	field, ok := r.byKey[key]
+	return field, ok
+}
+
+// FieldByName looks a field up by its display name, ignoring case and
+// surrounding whitespace.
+func (r *CustomFieldResolver) FieldByName(name string) (*Field, error) {
+	normalized := normalizeFieldName(name)
+	if _, ok := r.ambiguous[normalized]; ok {
+		return nil, &CustomFieldNotFoundError{Name: name, Ambiguous: true}
+	}
+	field, ok := r.byName[normalized]
+	if !ok {
+		return nil, &CustomFieldNotFoundError{Name: name}
+	}
+	return field, nil
+}
+
+// Lookup resolves a reference that is either a field hash key or a field
+// name.
+func (r *CustomFieldResolver) Lookup(ref string) (*Field, error) {
+	if field, ok := r.byKey[ref]; ok {
+		return field, nil
+	}
+	return r.FieldByName(ref)
+}
+
+func (r *CustomFieldResolver) Name(key string) (string, bool) {
+	field, ok := r.byKey[key]
+	if !ok {
+		return "", false
+	}
+	return field.FieldName, true
+}
+
+func (r *CustomFieldResolver) Key(name string) (string, error) {
+	field, err := r.FieldByName(name)
+	if err != nil {
+		return "", err
+	}
+	return field.FieldCode, nil
+}
+
+// Decode converts a CustomFields map keyed by hash into typed values keyed by
+// field name. Keys without a definition are kept under their hash key.
+func (r *CustomFieldResolver) Decode(customFields map[string]interface{}) (map[string]CustomFieldValue, error) {
+	out := make(map[string]CustomFieldValue, len(customFields))
+	for key, raw := range customFields {
+		value, err := r.DecodeValue(key, raw)
+		if err != nil {
+			return nil, err
+		}
+		name := value.Name
+		if name == "" {
+			name = key
+		}
+		if _, ok := r.ambiguous[normalizeFieldName(name)]; ok {
+			name = key
+		}
+		out[name] = value
+	}
+	return out, nil
+}
+
+// Value decodes the value stored under the named field. The boolean result
+// reports whether customFields contains the field at all.
+func (r *CustomFieldResolver) Value(customFields map[string]interface{}, ref string) (CustomFieldValue, bool, error) {
+	field, err := r.Lookup(ref)
+	if err != nil {
+		return CustomFieldValue{}, false, err
+	}
+	raw, ok := customFields[field.FieldCode]
+	if !ok {
+		return CustomFieldValue{Key: field.FieldCode, Name: field.FieldName, Type: field.FieldType}, false, nil
+	}
+	value, err := r.DecodeValue(field.FieldCode, raw)
+	return value, true, err
+}
+
+func (r *CustomFieldResolver) DecodeValue(key string, raw interface{}) (CustomFieldValue, error) {
+	value := CustomFieldValue{Key: key, Value: raw, Raw: raw}
+	field, ok := r.byKey[key]
+	if !ok {
+		return value, nil
+	}
+	value.Name = field.FieldName
+	value.Type = field.FieldType
+	if raw == nil {
+		value.Value = nil
+		return value, nil
+	}
+	decoded, err := decodeCustomFieldValue(field, raw)
+	if err != nil {
+		return CustomFieldValue{}, fmt.Errorf("decode custom field %q: %w", field.FieldName, err)
+	}
+	value.Value = decoded
+	return value, nil
+}
+
+// Encode converts values keyed by field name or hash key into the wire form
+// accepted by WithDealCustomFieldsMap and the other CustomFieldsMap options.
+// Typed values (MonetaryValue, RangeValue, time.Time, FieldOption, option
+// labels, OrganizationAddress and typed IDs) are converted; a nil value
+// clears the field.
+func (r *CustomFieldResolver) Encode(values map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(values))
+	for ref, value := range values {
+		field, err := r.Lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := out[field.FieldCode]; dup {
+			return nil, fmt.Errorf("custom field %q set more than once", field.FieldName)
+		}
+		encoded, err := encodeCustomFieldValue(field, value)
+		if err != nil {
+			return nil, fmt.Errorf("encode custom field %q: %w", field.FieldName, err)
+		}
+		out[field.FieldCode] = encoded
+	}
+	return out, nil
+}
+
+// OptionByLabel finds an enum or set option by label, ignoring case.
+func (r *CustomFieldResolver) OptionByLabel(ref, label string) (FieldOption, error) {
+	field, err := r.Lookup(ref)
+	if err != nil {
+		return FieldOption{}, err
+	}
+	return fieldOptionByLabel(field, label)
+}
+
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func fieldOptionByLabel(field *Field, label string) (FieldOption, error) {
+	want := normalizeFieldName(label)
+	for _, option := range field.Options {
+		if normalizeFieldName(option.Label) == want {
+			return option, nil
+		}
+	}
+	return FieldOption{}, &FieldOptionNotFoundError{Field: field.FieldName, Label: label}
+}
+
+func fieldOptionByID(field *Field, id int) FieldOption {
+	for _, option := range field.Options {
+		if option.ID == id {
+			return option
+		}
+	}
+	// Options deleted since the definitions were fetched still decode; the
+	// label is simply unknown.
+	return FieldOption{ID: id}
+}
+
+func decodeCustomFieldValue(field *Field, raw interface{}) (interface{}, error) {
+	switch field.FieldType {
+	case FieldTypeInt:
+		n, err := customFieldNumber(raw)
+		if err != nil {
+			return nil, err
+		}
+		return int64(n), nil
+	case FieldTypeDouble:
+		return customFieldNumber(raw)
+	case FieldTypeBoolean:
+		b, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected boolean, got %T", raw)
+		}
+		return b, nil
+	case FieldTypeVarchar, FieldTypeText, FieldTypePhone, FieldTypeVarcharAuto, FieldTypeVarcharOptions, FieldTypeTime:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %T", raw)
+		}
+		return s, nil
+	case FieldTypeMonetary:
+		return decodeMonetaryValue(raw)
+	case FieldTypeDate:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected date string, got %T", raw)
+		}
+		return time.Parse(customFieldDateLayout, s)
+	case FieldTypeDateRange, FieldTypeTimeRange:
+		return decodeRangeValue(raw)
+	case FieldTypeEnum:
+		id, err := customFieldOptionID(raw)
+		if err != nil {
+			return nil, err
+		}
+		return fieldOptionByID(field, id), nil
+	case FieldTypeSet:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected option list, got %T", raw)
+		}
+		options := make([]FieldOption, 0, len(items))
+		for _, item := range items {
+			id, err := customFieldOptionID(item)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, fieldOptionByID(field, id))
+		}
+		return options, nil
+	case FieldTypeAddress:
+		return decodeAddressValue(raw)
+	case FieldTypeUser:
+		id, err := customFieldReferenceID(raw)
+		return UserID(id), err
+	case FieldTypeOrg:
+		id, err := customFieldReferenceID(raw)
+		return OrganizationID(id), err
+	case FieldTypePeople:
+		id, err := customFieldReferenceID(raw)
+		return PersonID(id), err
+	case FieldTypeDeal:
+		id, err := customFieldReferenceID(raw)
+		return DealID(id), err
+	case FieldTypeProject:
+		id, err := customFieldReferenceID(raw)
+		return ProjectID(id), err
+	case FieldTypeStage:
+		id, err := customFieldReferenceID(raw)
+		return StageID(id), err
+	case FieldTypeActivity:
+		id, err := customFieldReferenceID(raw)
+		return ActivityID(id), err
+	case FieldTypeLead:
+		if obj, ok := raw.(map[string]interface{}); ok {
+			raw = obj["id"]
+		}
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected lead id string, got %T", raw)
+		}
+		return LeadID(s), nil
+	default:
+		return raw, nil
+	}
+}
+
+func customFieldNumber(raw interface{}) (float64, error) {
+	switch n := raw.(type) {
+	case float64:
+		return n, nil
+	case json.Number:
+		return n.Float64()
+	case string:
+		// Some numeric fields are returned as numeric strings.
+		return strconv.ParseFloat(n, 64)
+	}
+	return 0, fmt.Errorf("expected number, got %T", raw)
+}
+
+func customFieldOptionID(raw interface{}) (int, error) {
+	// include_option_labels=true returns {id, label} objects.
+	if obj, ok := raw.(map[string]interface{}); ok {
+		raw = obj["id"]
+	}
+	n, err := customFieldNumber(raw)
+	if err != nil {
+		return 0, fmt.Errorf("option id: %w", err)
+	}
+	if n != math.Trunc(n) {
+		return 0, fmt.Errorf("option id %v is not an integer", n)
+	}
+	return int(n), nil
+}
+
+func customFieldReferenceID(raw interface{}) (int64, error) {
+	if obj, ok := raw.(map[string]interface{}); ok {
+		if id, ok := obj["id"]; ok {
+			raw = id
+		} else {
+			raw = obj["value"]
+		}
+	}
+	n, err := customFieldNumber(raw)
+	if err != nil {
+		return 0, fmt.Errorf("reference id: %w", err)
+	}
+	if n != math.Trunc(n) {
+		return 0, fmt.Errorf("reference id %v is not an integer", n)
+	}
+	return int64(n), nil
+}
+
+func decodeMonetaryValue(raw interface{}) (MonetaryValue, error) {
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		// A bare number is a monetary value without currency.
+		amount, err := customFieldNumber(raw)
+		if err != nil {
+			return MonetaryValue{}, fmt.Errorf("expected monetary object, got %T", raw)
+		}
+		return MonetaryValue{Amount: amount}, nil
+	}
+	var value MonetaryValue
+	if amount, ok := obj["value"]; ok && amount != nil {
+		n, err := customFieldNumber(amount)
+		if err != nil {
+			return MonetaryValue{}, err
+		}
+		value.Amount = n
+	}
+	if currency, ok := obj["currency"].(string); ok {
+		value.Currency = currency
+	}
+	return value, nil
+}
+
+func decodeRangeValue(raw interface{}) (RangeValue, error) {
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return RangeValue{}, fmt.Errorf("expected range object, got %T", raw)
+	}
+	var value RangeValue
+	if start, ok := obj["value"].(string); ok {
+		value.Start = start
+	}
+	if end, ok := obj["until"].(string); ok {
+		value.End = end
+	}
+	return value, nil
+}
+
+func decodeAddressValue(raw interface{}) (OrganizationAddress, error) {
+	if s, ok := raw.(string); ok {
+		return OrganizationAddress{Value: s}, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return OrganizationAddress{}, err
+	}
+	var address OrganizationAddress
+	if err := json.Unmarshal(data, &address); err != nil {
+		return OrganizationAddress{}, fmt.Errorf("expected address object: %w", err)
+	}
+	return address, nil
+}
+
+func encodeCustomFieldValue(field *Field, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch field.FieldType {
+	case FieldTypeMonetary:
+		switch v := value.(type) {
+		case MonetaryValue:
+			body := map[string]interface{}{"value": v.Amount}
+			if v.Currency != "" {
+				body["currency"] = v.Currency
+			}
+			return body, nil
+		case *MonetaryValue:
+			if v == nil {
+				return nil, nil
+			}
+			return encodeCustomFieldValue(field, *v)
+		}
+	case FieldTypeDate:
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format(customFieldDateLayout), nil
+		case string:
+			if _, err := time.Parse(customFieldDateLayout, v); err != nil {
+				return nil, fmt.Errorf("invalid date %q", v)
+			}
+			return v, nil
+		}
+	case FieldTypeDateRange, FieldTypeTimeRange:
+		switch v := value.(type) {
+		case RangeValue:
+			return map[string]interface{}{"value": v.Start, "until": v.End}, nil
+		case *RangeValue:
+			if v == nil {
+				return nil, nil
+			}
+			return encodeCustomFieldValue(field, *v)
+		}
+	case FieldTypeEnum:
+		return encodeOptionValue(field, value)
+	case FieldTypeSet:
+		return encodeOptionSetValue(field, value)
+	case FieldTypeAddress:
+		switch v := value.(type) {
+		case OrganizationAddress:
+			return v, nil
+		case *OrganizationAddress:
+			if v == nil {
+				return nil, nil
+			}
+			return *v, nil
+		}
+	case FieldTypeUser, FieldTypeOrg, FieldTypePeople, FieldTypeDeal, FieldTypeProject, FieldTypeStage, FieldTypeActivity:
+		if id, ok := customFieldIDValue(value); ok {
+			return id, nil
+		}
+	case FieldTypeLead:
+		if id, ok := value.(LeadID); ok {
+			return string(id), nil
+		}
+	}
+	return value, nil
+}
+
+func customFieldIDValue(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case UserID:
+		return int64(v), true
+	case OrganizationID:
+		return int64(v), true
+	case PersonID:
+		return int64(v), true
+	case DealID:
+		return int64(v), true
+	case ProjectID:
+		return int64(v), true
+	case StageID:
+		return int64(v), true
+	case ActivityID:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+func encodeOptionValue(field *Field, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case FieldOption:
+		if v.ID != 0 {
+			for _, option := range field.Options {
+				if option.ID == v.ID {
+					return v.ID, nil
+				}
+			}
+			return nil, &FieldOptionNotFoundError{Field: field.FieldName, Label: strconv.Itoa(v.ID)}
+		}
+		option, err := fieldOptionByLabel(field, v.Label)
+		if err != nil {
+			return nil, err
+		}
+		return option.ID, nil
+	case string:
+		option, err := fieldOptionByLabel(field, v)
+		if err != nil {
+			return nil, err
+		}
+		return option.ID, nil
+	}
+	return value, nil
+}
+
+func encodeOptionSetValue(field *Field, value interface{}) (interface{}, error) {
+	var items []interface{}
+	switch v := value.(type) {
+	case []FieldOption:
+		for _, option := range v {
+			items = append(items, option)
+		}
+	case []string:
+		for _, label := range v {
+			items = append(items, label)
+		}
+	default:
+		return value, nil
+	}
+	// Pipedrive rejects an empty array for set fields; null clears them.
+	if len(items) == 0 {
+		return nil, nil
+	}
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		id, err := encodeOptionValue(field, item)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id.(int))
+	}
+	return ids, nil
+}
@@ -0,0 +1,174 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const customFieldsTestFields = `[
+	{"field_code":"title","field_name":"Title","field_type":"varchar","is_custom_field":false},
+	{"field_code":"h_budget","field_name":"Budget","field_type":"monetary","is_custom_field":true},
+	{"field_code":"h_launch","field_name":"Launch date","field_type":"date","is_custom_field":true},
+	{"field_code":"h_window","field_name":"Window","field_type":"daterange","is_custom_field":true},
+	{"field_code":"h_tier","field_name":"Tier","field_type":"enum","is_custom_field":true,"options":[{"id":1,"label":"Gold"},{"id":2,"label":"Silver"}]},
+	{"field_code":"h_tags","field_name":"Tags","field_type":"set","is_custom_field":true,"options":[{"id":10,"label":"Hot"},{"id":11,"label":"Cold"}]},
+	{"field_code":"h_addr","field_name":"Site","field_type":"address","is_custom_field":true},
+	{"field_code":"h_owner","field_name":"Reviewer","field_type":"user","is_custom_field":true},
+	{"field_code":"h_seats","field_name":"Seats","field_type":"int","is_custom_field":true},
+	{"field_code":"h_dup1","field_name":"Notes","field_type":"text","is_custom_field":true},
+	{"field_code":"h_dup2","field_name":"notes","field_type":"text","is_custom_field":true}
+]`
+
+func newTestCustomFieldResolver(t *testing.T) *CustomFieldResolver {
+	t.Helper()
+
+	var fields []Field
+	if err := json.Unmarshal([]byte(customFieldsTestFields), &fields); err != nil {
+		t.Fatalf("unmarshal fields: %v", err)
+	}
+	return NewCustomFieldResolver(fields)
+}
+
+func TestDealFieldsService_Resolver(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dealFields" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{"data":[{"field_code":"h_a","field_name":"Alpha","field_type":"varchar","is_custom_field":true}],"additional_data":{"next_cursor":"c2"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"field_code":"h_b","field_name":"Beta","field_type":"int","is_custom_field":true}],"additional_data":{"next_cursor":null}}`))
+	})
+
+	resolver, err := client.DealFields.Resolver(context.Background())
+	if err != nil {
+		t.Fatalf("Resolver error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 requests, got %d", calls)
+	}
+	if key, err := resolver.Key("beta"); err != nil || key != "h_b" {
+		t.Fatalf("unexpected key: %q, %v", key, err)
+	}
+	if name, ok := resolver.Name("h_a"); !ok || name != "Alpha" {
+		t.Fatalf("unexpected name: %q", name)
+	}
+}
+
+func TestCustomFieldResolver_Lookup(t *testing.T) {
+	t.Parallel()
+
+	resolver := newTestCustomFieldResolver(t)
+	if _, ok := resolver.Field("title"); ok {
+		t.Fatalf("expected built-in field to be skipped")
+	}
+	if _, err := resolver.FieldByName("Notes"); err == nil {
+		t.Fatalf("expected ambiguous name error")
+	}
+	if _, err := resolver.Lookup("Missing"); err == nil {
+		t.Fatalf("expected unknown field error")
+	}
+	field, err := resolver.Lookup("h_dup2")
+	if err != nil || field.FieldName != "notes" {
+		t.Fatalf("expected lookup by key to bypass name ambiguity: %#v, %v", field, err)
+	}
+}
+
+func TestCustomFieldResolver_Decode(t *testing.T) {
+	t.Parallel()
+
+	resolver := newTestCustomFieldResolver(t)
+	var custom map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+		"h_budget":{"value":1500.5,"currency":"EUR"},
+		"h_launch":"2026-03-01",
+		"h_window":{"value":"2026-03-01","until":"2026-03-31"},
+		"h_tier":{"id":2,"label":"Silver"},
+		"h_tags":[10,11],
+		"h_addr":{"value":"Main St 1, Helsinki","country":"Finland","locality":"Helsinki"},
+		"h_owner":{"id":42,"name":"Ann"},
+		"h_seats":12,
+		"h_dup1":"first",
+		"h_unknown":"kept"
+	}`), &custom); err != nil {
+		t.Fatalf("unmarshal custom fields: %v", err)
+	}
+
+	values, err := resolver.Decode(custom)
+	if err != nil {
+		t.Fatalf("Decode error: %v", err)
+	}
+	if m, ok := values["Budget"].Monetary(); !ok || m.Amount != 1500.5 || m.Currency != "EUR" {
+		t.Fatalf("unexpected budget: %#v", values["Budget"])
+	}
+	if d, ok := values["Launch date"].Date(); !ok || !d.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected launch date: %#v", values["Launch date"])
+	}
+	if r, ok := values["Window"].Range(); !ok || r.Start != "2026-03-01" || r.End != "2026-03-31" {
+		t.Fatalf("unexpected window: %#v", values["Window"])
+	}
+	if o, ok := values["Tier"].Option(); !ok || o.ID != 2 || o.Label != "Silver" {
+		t.Fatalf("unexpected tier: %#v", values["Tier"])
+	}
+	if o, ok := values["Tags"].Options(); !ok || len(o) != 2 || o[0].Label != "Hot" || o[1].Label != "Cold" {
+		t.Fatalf("unexpected tags: %#v", values["Tags"])
+	}
+	if a, ok := values["Site"].Address(); !ok || a.Locality != "Helsinki" {
+		t.Fatalf("unexpected address: %#v", values["Site"])
+	}
+	if id, ok := values["Reviewer"].Value.(UserID); !ok || id != 42 {
+		t.Fatalf("unexpected reviewer: %#v", values["Reviewer"])
+	}
+	if n, ok := values["Seats"].Value.(int64); !ok || n != 12 {
+		t.Fatalf("unexpected seats: %#v", values["Seats"])
+	}
+	if v := values["h_dup1"]; v.Name != "Notes" || v.Value != "first" {
+		t.Fatalf("expected ambiguous field keyed by hash: %#v", v)
+	}
+	if v := values["h_unknown"]; v.Value != "kept" || v.Name != "" {
+		t.Fatalf("unexpected unknown field: %#v", v)
+	}
+}
+
+func TestCustomFieldResolver_Encode(t *testing.T) {
+	t.Parallel()
+
+	resolver := newTestCustomFieldResolver(t)
+	out, err := resolver.Encode(map[string]interface{}{
+		"Budget":      MonetaryValue{Amount: 99, Currency: "USD"},
+		"launch date": time.Date(2026, 4, 2, 15, 0, 0, 0, time.UTC),
+		"Window":      RangeValue{Start: "2026-04-01", End: "2026-04-30"},
+		"Tier":        "gold",
+		"Tags":        []string{"Cold"},
+		"Reviewer":    UserID(7),
+		"h_seats":     nil,
+	})
+	if err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"h_budget":{"currency":"USD","value":99},"h_launch":"2026-04-02","h_owner":7,"h_seats":null,"h_tags":[11],"h_tier":1,"h_window":{"until":"2026-04-30","value":"2026-04-01"}}`
+	if string(data) != want {
+		t.Fatalf("unexpected payload:\n got %s\nwant %s", data, want)
+	}
+
+	if _, err := resolver.Encode(map[string]interface{}{"Tier": "Bronze"}); err == nil {
+		t.Fatalf("expected unknown option error")
+	}
+	if _, err := resolver.Encode(map[string]interface{}{"Tier": "Gold", "h_tier": 2}); err == nil {
+		t.Fatalf("expected duplicate field error")
+	}
+}
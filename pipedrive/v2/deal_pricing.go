@@ -0,0 +1,275 @@
+package v2
+
+import (
+	"fmt"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+const defaultPricingDecimals = 2
+
+var (
+	decimalZero    = pipedrive.DecimalFromInt(0)
+	decimalOne     = pipedrive.DecimalFromInt(1)
+	decimalHundred = pipedrive.DecimalFromInt(100)
+)
+
+// billingCyclesPerYear is used to annualize recurring products that bill
+// indefinitely and to derive MRR and ARR.
+var billingCyclesPerYear = map[BillingFrequency]int64{
+	BillingFrequencyWeekly:       52,
+	BillingFrequencyMonthly:      12,
+	BillingFrequencyQuarterly:    4,
+	BillingFrequencySemiAnnually: 2,
+	BillingFrequencyAnnually:     1,
+}
+
+// DealProductLineTotal explains how one deal product line is priced.
+type DealProductLineTotal struct {
+	Product DealProduct
+	// Gross is item price times quantity.
+	Gross pipedrive.Decimal
+	// Discount is the line discount as an amount.
+	Discount pipedrive.Decimal
+	// Net is the discounted line amount without tax.
+	Net pipedrive.Decimal
+	Tax pipedrive.Decimal
+	// Sum is the line amount Pipedrive stores as the product sum: the
+	// discounted amount including tax, rounded.
+	Sum pipedrive.Decimal
+	// DealValue is what the line adds to the deal value: zero for disabled
+	// products and Sum times the number of billing cycles for recurring
+	// ones.
+	DealValue pipedrive.Decimal
+	Recurring bool
+}
+
+// DealPricing is the result of CalculateDealPricing.
+type DealPricing struct {
+	Lines []DealProductLineTotal
+	// ProductsTotal is the sum of the line deal values.
+	ProductsTotal pipedrive.Decimal
+	// AdditionalDiscounts holds each additional discount as an amount, in
+	// input order.
+	AdditionalDiscounts []pipedrive.Decimal
+	// Total is the deal value after additional discounts.
+	Total pipedrive.Decimal
+	// Recurring sums the per-cycle line amounts of enabled recurring
+	// products by billing frequency.
+	Recurring map[BillingFrequency]pipedrive.Decimal
+	MRR       pipedrive.Decimal
+	ARR       pipedrive.Decimal
+	// InstallmentsTotal is the sum of the installment amounts.
+	InstallmentsTotal pipedrive.Decimal
+}
+
+type DealPricingOption func(*dealPricingOptions)
+
+type dealPricingOptions struct {
+	decimals int
+}
+
+// WithDealPricingDecimals sets the decimal places line sums and totals are
+// rounded to. The default is 2.
+func WithDealPricingDecimals(decimals int) DealPricingOption {
+	return func(cfg *dealPricingOptions) {
+		if decimals >= 0 {
+			cfg.decimals = decimals
+		}
+	}
+}
+
+// DealPricingError reports a deal product, additional discount or installment
+// that cannot be priced. Index is the position in the corresponding input
+// slice.
+type DealPricingError struct {
+	Item   string
+	Index  int
+	Reason string
+}
+
+func (e *DealPricingError) Error() string {
+	if e == nil {
+		return "invalid deal pricing input"
+	}
+	return fmt.Sprintf("%s %d: %s", e.Item, e.Index, e.Reason)
+}
+
+// InstallmentMismatchError is returned when installments do not add up to the
+// deal value.
+type InstallmentMismatchError struct {
+	Total        pipedrive.Decimal
+	Installments pipedrive.Decimal
+}
+
+func (e *InstallmentMismatchError) Error() string {
+	if e == nil {
+		return "installments do not match the deal value"
+	}
+	return fmt.Sprintf("installments add up to %s but the deal value is %s", e.Installments, e.Total)
+}
+
+// CalculateDealPricing prices deal products the way Pipedrive does, using
+// exact decimal arithmetic:
+//
+//   - the line discount is taken from item price times quantity, either as a
+//     percentage or as an amount for the whole line;
+//   - exclusive tax is added on top of the discounted amount, inclusive tax
+//     is already part of it, and the result is rounded to give the line sum;
+//   - disabled products do not count towards the deal value, recurring ones
+//     count once per billing cycle, or for one year when the number of cycles
+//     is open-ended;
+//   - percentage additional discounts are taken from the products total and
+//     amount discounts are subtracted as is.
+//
+// Installments are only allowed on deals without recurring products and must
+// add up to the deal value; otherwise the pricing is returned together with
+// an *InstallmentMismatchError.
+func CalculateDealPricing(products []DealProduct, discounts []AdditionalDiscount, installments []Installment, opts ...DealPricingOption) (*DealPricing, error) {
+	cfg := dealPricingOptions{decimals: defaultPricingDecimals}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	pricing := &DealPricing{Recurring: map[BillingFrequency]pipedrive.Decimal{}}
+	monthly := decimalZero
+	for i, product := range products {
+		line, err := priceDealProduct(i, product, cfg.decimals)
+		if err != nil {
+			return nil, err
+		}
+		pricing.Lines = append(pricing.Lines, line)
+		pricing.ProductsTotal = pricing.ProductsTotal.Add(line.DealValue)
+		if line.Recurring && !line.DealValue.IsZero() {
+			frequency := product.BillingFrequency
+			pricing.Recurring[frequency] = pricing.Recurring[frequency].Add(line.Sum)
+			perYear := pipedrive.DecimalFromInt(billingCyclesPerYear[frequency])
+			monthly = monthly.Add(line.Sum.Mul(perYear).Quo(pipedrive.DecimalFromInt(12)))
+		}
+	}
+	pricing.MRR = monthly.Round(cfg.decimals)
+	pricing.ARR = monthly.Mul(pipedrive.DecimalFromInt(12)).Round(cfg.decimals)
+
+	total := pricing.ProductsTotal
+	for i, discount := range discounts {
+		amount, ok := discount.AmountDecimal()
+		if !ok {
+			return nil, &DealPricingError{Item: "additional discount", Index: i, Reason: "amount is required"}
+		}
+		if amount.Sign() < 0 {
+			return nil, &DealPricingError{Item: "additional discount", Index: i, Reason: "amount is negative"}
+		}
+		switch discount.Type {
+		case AdditionalDiscountTypePercentage:
+			if amount.Cmp(decimalHundred) > 0 {
+				return nil, &DealPricingError{Item: "additional discount", Index: i, Reason: "percentage is above 100"}
+			}
+			amount = pricing.ProductsTotal.Mul(amount).Quo(decimalHundred).Round(cfg.decimals)
+		case AdditionalDiscountTypeAmount, "":
+		default:
+			return nil, &DealPricingError{Item: "additional discount", Index: i, Reason: fmt.Sprintf("unknown type %q", discount.Type)}
+		}
+		pricing.AdditionalDiscounts = append(pricing.AdditionalDiscounts, amount)
+		total = total.Sub(amount)
+	}
+	pricing.Total = total.Round(cfg.decimals)
+
+	if len(installments) == 0 {
+		return pricing, nil
+	}
+	if len(pricing.Recurring) > 0 {
+		return nil, &DealPricingError{Item: "installment", Index: 0, Reason: "installments cannot be combined with recurring products"}
+	}
+	for i, installment := range installments {
+		amount, ok := installment.AmountDecimal()
+		if !ok {
+			return nil, &DealPricingError{Item: "installment", Index: i, Reason: "amount is required"}
+		}
+		pricing.InstallmentsTotal = pricing.InstallmentsTotal.Add(amount)
+	}
+	if !pricing.InstallmentsTotal.Round(cfg.decimals).Equal(pricing.Total) {
+		return pricing, &InstallmentMismatchError{Total: pricing.Total, Installments: pricing.InstallmentsTotal}
+	}
+	return pricing, nil
+}
+
+func priceDealProduct(index int, product DealProduct, decimals int) (DealProductLineTotal, error) {
+	line := DealProductLineTotal{Product: product}
+	invalid := func(reason string) (DealProductLineTotal, error) {
+		return DealProductLineTotal{}, &DealPricingError{Item: "deal product", Index: index, Reason: reason}
+	}
+	price, ok := product.ItemPriceMoney()
+	if !ok {
+		return invalid("item price is required")
+	}
+	if product.Quantity == nil {
+		return invalid("quantity is required")
+	}
+	line.Gross = price.Amount.Mul(pipedrive.DecimalFromFloat(*product.Quantity))
+
+	if product.Discount != nil {
+		discount := pipedrive.DecimalFromFloat(*product.Discount)
+		if discount.Sign() < 0 {
+			return invalid("discount is negative")
+		}
+		switch product.DiscountType {
+		case DealProductDiscountTypePercentage, "":
+			if discount.Cmp(decimalHundred) > 0 {
+				return invalid("discount percentage is above 100")
+			}
+			line.Discount = line.Gross.Mul(discount).Quo(decimalHundred)
+		case DealProductDiscountTypeAmount:
+			line.Discount = discount
+		default:
+			return invalid(fmt.Sprintf("unknown discount type %q", product.DiscountType))
+		}
+	}
+	discounted := line.Gross.Sub(line.Discount)
+
+	tax := decimalZero
+	if product.Tax != nil {
+		tax = pipedrive.DecimalFromFloat(*product.Tax)
+		if tax.Sign() < 0 {
+			return invalid("tax is negative")
+		}
+	}
+	rate := tax.Quo(decimalHundred)
+	switch product.TaxMethod {
+	case DealProductTaxMethodExclusive:
+		line.Net = discounted
+		line.Tax = discounted.Mul(rate)
+		line.Sum = discounted.Add(line.Tax).Round(decimals)
+	case DealProductTaxMethodInclusive:
+		line.Net = discounted.Quo(decimalOne.Add(rate))
+		line.Tax = discounted.Sub(line.Net)
+		line.Sum = discounted.Round(decimals)
+	case DealProductTaxMethodNone, "":
+		line.Net = discounted
+		line.Sum = discounted.Round(decimals)
+	default:
+		return invalid(fmt.Sprintf("unknown tax method %q", product.TaxMethod))
+	}
+
+	if product.IsEnabled != nil && !*product.IsEnabled {
+		line.DealValue = decimalZero
+		return line, nil
+	}
+	line.DealValue = line.Sum
+	switch product.BillingFrequency {
+	case BillingFrequencyOneTime, "":
+	default:
+		perYear, ok := billingCyclesPerYear[product.BillingFrequency]
+		if !ok {
+			return invalid(fmt.Sprintf("unknown billing frequency %q", product.BillingFrequency))
+		}
+		line.Recurring = true
+		cycles := perYear
+		if product.BillingFrequencyCycles != nil {
+			cycles = int64(*product.BillingFrequencyCycles)
+		}
+		line.DealValue = line.Sum.Mul(pipedrive.DecimalFromInt(cycles))
+	}
+	return line, nil
+}
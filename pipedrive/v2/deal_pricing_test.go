@@ -0,0 +1,80 @@
+package v2
+
+import (
+	"errors"
+	"testing"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestCalculateDealPricing(t *testing.T) {
+	t.Parallel()
+
+	disabled := false
+	cycles := 6
+	products := []DealProduct{
+		// 3 x 19.99 with 10% off and 24% tax on top.
+		{ItemPrice: float(19.99), Quantity: float(3), Discount: float(10), DiscountType: DealProductDiscountTypePercentage, Tax: float(24), TaxMethod: DealProductTaxMethodExclusive},
+		// 2 x 100 with 15 off and tax included.
+		{ItemPrice: float(100), Quantity: float(2), Discount: float(15), DiscountType: DealProductDiscountTypeAmount, Tax: float(25), TaxMethod: DealProductTaxMethodInclusive},
+		{ItemPrice: float(1000), Quantity: float(1), IsEnabled: &disabled},
+		{ItemPrice: float(50), Quantity: float(1), BillingFrequency: BillingFrequencyMonthly, BillingFrequencyCycles: &cycles},
+		{ItemPrice: float(120), Quantity: float(1), BillingFrequency: BillingFrequencyAnnually},
+	}
+	discounts := []AdditionalDiscount{
+		{Amount: float(5), Type: AdditionalDiscountTypePercentage},
+		{Amount: float(10), Type: AdditionalDiscountTypeAmount},
+	}
+	pricing, err := CalculateDealPricing(products, discounts, nil)
+	if err != nil {
+		t.Fatalf("CalculateDealPricing error: %v", err)
+	}
+
+	wantSums := []string{"66.93", "185", "1000", "50", "120"}
+	wantValues := []string{"66.93", "185", "0", "300", "120"}
+	for i, line := range pricing.Lines {
+		if line.Sum.String() != wantSums[i] || line.DealValue.String() != wantValues[i] {
+			t.Fatalf("line %d: sum %s value %s, want %s and %s", i, line.Sum, line.DealValue, wantSums[i], wantValues[i])
+		}
+	}
+	if got := pricing.Lines[0].Tax.StringFixed(4); got != "12.9535" {
+		t.Fatalf("unexpected exclusive tax: %s", got)
+	}
+	if got := pricing.Lines[1].Net.String(); got != "148" {
+		t.Fatalf("unexpected inclusive net: %s", got)
+	}
+	if pricing.ProductsTotal.String() != "671.93" || pricing.AdditionalDiscounts[0].String() != "33.6" || pricing.Total.String() != "628.33" {
+		t.Fatalf("unexpected totals: %s, %v, %s", pricing.ProductsTotal, pricing.AdditionalDiscounts, pricing.Total)
+	}
+	if pricing.MRR.String() != "60" || pricing.ARR.String() != "720" || pricing.Recurring[BillingFrequencyMonthly].String() != "50" {
+		t.Fatalf("unexpected recurring totals: %s, %s, %v", pricing.MRR, pricing.ARR, pricing.Recurring)
+	}
+}
+
+func TestCalculateDealPricing_Installments(t *testing.T) {
+	t.Parallel()
+
+	products := []DealProduct{{ItemPrice: float(0.1), Quantity: float(3)}}
+	pricing, err := CalculateDealPricing(products, nil, []Installment{{Amount: float(0.1)}, {Amount: float(0.2)}})
+	if err != nil || pricing.Total.String() != "0.3" {
+		t.Fatalf("expected exact installment match, got %v, %v", pricing, err)
+	}
+
+	_, err = CalculateDealPricing(products, nil, []Installment{{Amount: float(0.2)}})
+	var mismatch *InstallmentMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Installments.String() != "0.2" {
+		t.Fatalf("expected installment mismatch, got %v", err)
+	}
+
+	recurring := []DealProduct{{ItemPrice: float(10), Quantity: float(1), BillingFrequency: BillingFrequencyWeekly}}
+	var pricingErr *DealPricingError
+	if _, err := CalculateDealPricing(recurring, nil, []Installment{{Amount: float(520)}}); !errors.As(err, &pricingErr) {
+		t.Fatalf("expected recurring installment error, got %v", err)
+	}
+	invalid := []DealProduct{{ItemPrice: float(10), Quantity: float(1), Discount: float(120)}}
+	if _, err := CalculateDealPricing(invalid, nil, nil); !errors.As(err, &pricingErr) || pricingErr.Index != 0 {
+		t.Fatalf("expected discount error, got %v", err)
+	}
+}
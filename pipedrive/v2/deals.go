@@ -160,6 +160,8 @@ type Deal struct {
 	SmartBCCEmail         *string                `json:"smart_bcc_email,omitempty"`
 	UndoneActivitiesCount *int                   `json:"undone_activities_count,omitempty"`
 	Labels                []EntityLabel          `json:"labels,omitempty"`
+
+	exact *exactAmounts
 }
 
 type DealSearchItem struct {
@@ -208,6 +210,8 @@ type DealProduct struct {
 	Sum                    *float64                `json:"sum,omitempty"`
 	AddTime                *time.Time              `json:"add_time,omitempty"`
 	UpdateTime             *time.Time              `json:"update_time,omitempty"`
+
+	exact *exactAmounts
 }
 
 type DealProductInput struct {
@@ -233,6 +237,8 @@ type AdditionalDiscount struct {
 	UpdatedAt   *time.Time             `json:"updated_at,omitempty"`
 	CreatedBy   *UserID                `json:"created_by,omitempty"`
 	UpdatedBy   *UserID                `json:"updated_by,omitempty"`
+
+	exact *exactAmounts
 }
 
 type AdditionalDiscountDeleteResult struct {
@@ -245,6 +251,8 @@ type Installment struct {
 	Amount      *float64      `json:"amount,omitempty"`
 	BillingDate *string       `json:"billing_date,omitempty"`
 	Description *string       `json:"description,omitempty"`
+
+	exact *exactAmounts
 }
 
 type InstallmentDeleteResult struct {
@@ -457,6 +465,7 @@ type searchDealsOptions struct {
 
 type convertDealOptions struct {
 	requestOptions []pipedrive.RequestOption
+	pollPolicy     *pipedrive.PollPolicy
 }
 
 type getDealConversionStatusOptions struct {
@@ -554,14 +563,15 @@ type deleteInstallmentOptions struct {
 type dealPayload struct {
 	title             *string
 	value             *float64
+	valueExact        *pipedrive.Decimal
 	currency          *string
 	ownerID           *UserID
-	personID          *PersonID
-	orgID             *OrganizationID
+	personID          nullableValue[PersonID]
+	orgID             nullableValue[OrganizationID]
 	stageID           *StageID
 	pipelineID        *PipelineID
 	status            *DealStatus
-	expectedCloseDate *string
+	expectedCloseDate nullableValue[string]
 	probability       nullableValue[float64]
 	lostReason        nullableValue[string]
 	visibleTo         *int
@@ -580,6 +590,7 @@ type dealProductPayload struct {
 	productID              *ProductID
 	productVariationID     *ProductVariationID
 	itemPrice              *float64
+	itemPriceExact         *pipedrive.Decimal
 	quantity               *float64
 	discount               *float64
 	discountType           *DealProductDiscountType
@@ -594,12 +605,14 @@ type dealProductPayload struct {
 
 type additionalDiscountPayload struct {
 	amount       *float64
+	amountExact  *pipedrive.Decimal
 	discountType *AdditionalDiscountType
 	description  *string
 }
 
 type installmentPayload struct {
 	amount      *float64
+	amountExact *pipedrive.Decimal
 	billingDate *string
 	description *string
 }
@@ -1154,6 +1167,7 @@ func WithDealTitle(title string) DealOption {
 func WithDealValue(value float64) DealOption {
 	return dealFieldOption(func(payload *dealPayload) {
 		payload.value = &value
+		payload.valueExact = nil
 	})
 }
 
@@ -1171,13 +1185,29 @@ func WithDealOwnerID(id UserID) DealOption {
 
 func WithDealPersonID(id PersonID) DealOption {
 	return dealFieldOption(func(payload *dealPayload) {
-		payload.personID = &id
+		payload.personID.assign(id)
+	})
+}
+
+// ClearDealPersonID sends an explicit JSON null person, unlinking it from the
+// deal.
+func ClearDealPersonID() DealOption {
+	return dealFieldOption(func(payload *dealPayload) {
+		payload.personID.clear()
 	})
 }
 
 func WithDealOrganizationID(id OrganizationID) DealOption {
 	return dealFieldOption(func(payload *dealPayload) {
-		payload.orgID = &id
+		payload.orgID.assign(id)
+	})
+}
+
+// ClearDealOrganizationID sends an explicit JSON null organization, unlinking
+// it from the deal.
+func ClearDealOrganizationID() DealOption {
+	return dealFieldOption(func(payload *dealPayload) {
+		payload.orgID.clear()
 	})
 }
 
@@ -1204,7 +1234,14 @@ func WithDealExpectedCloseDate(date string) DealOption {
 		if date == "" {
 			return
 		}
-		payload.expectedCloseDate = &date
+		payload.expectedCloseDate.assign(date)
+	})
+}
+
+// ClearDealExpectedCloseDate sends an explicit JSON null expected close date.
+func ClearDealExpectedCloseDate() DealOption {
+	return dealFieldOption(func(payload *dealPayload) {
+		payload.expectedCloseDate.clear()
 	})
 }
 
@@ -1509,6 +1546,7 @@ func WithDealProductVariationID(id ProductVariationID) DealProductOption {
 func WithDealProductItemPrice(price float64) DealProductOption {
 	return dealProductFieldOption(func(payload *dealProductPayload) {
 		payload.itemPrice = &price
+		payload.itemPriceExact = nil
 	})
 }
 
@@ -1591,6 +1629,7 @@ func WithDealProductAttachmentIDs(ids ...DealProductAttachmentID) DeleteDealProd
 func WithAdditionalDiscountAmount(amount float64) AdditionalDiscountOption {
 	return additionalDiscountFieldOption(func(payload *additionalDiscountPayload) {
 		payload.amount = &amount
+		payload.amountExact = nil
 	})
 }
 
@@ -1612,6 +1651,7 @@ func WithAdditionalDiscountDescription(description string) AdditionalDiscountOpt
 func WithInstallmentAmount(amount float64) InstallmentOption {
 	return installmentFieldOption(func(payload *installmentPayload) {
 		payload.amount = &amount
+		payload.amountExact = nil
 	})
 }
 
@@ -2078,6 +2118,9 @@ func (s *DealsService) ForEachArchived(ctx context.Context, fn func(Deal) error,
 
 func (s *DealsService) Create(ctx context.Context, opts ...CreateDealOption) (*Deal, error) {
 	cfg := newCreateDealOptions(opts)
+	if err := cfg.payload.validate(); err != nil {
+		return nil, err
+	}
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap())
@@ -2110,6 +2153,9 @@ func (s *DealsService) Update(ctx context.Context, id DealID, opts ...UpdateDeal
 		return nil, err
 	}
 	cfg := newUpdateDealOptions(opts)
+	if err := cfg.payload.validate(); err != nil {
+		return nil, err
+	}
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap())
@@ -2444,6 +2490,9 @@ func (s *DealsService) AddProduct(ctx context.Context, id DealID, opts ...AddDea
 		return nil, err
 	}
 	cfg := newAddDealProductOptions(opts)
+	if err := cfg.payload.validate(); err != nil {
+		return nil, err
+	}
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap())
@@ -2475,6 +2524,11 @@ func (s *DealsService) AddProducts(ctx context.Context, id DealID, products []De
 	if err := validateID(id, "deal id"); err != nil {
 		return nil, err
 	}
+	for _, product := range products {
+		if err := product.payload.validate(); err != nil {
+			return nil, err
+		}
+	}
 	cfg := newAddManyDealProductsOptions(opts)
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
@@ -2514,6 +2568,9 @@ func (s *DealsService) UpdateProduct(ctx context.Context, id DealID, attachmentI
 		return nil, err
 	}
 	cfg := newUpdateDealProductOptions(opts)
+	if err := cfg.payload.validate(); err != nil {
+		return nil, err
+	}
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap())
@@ -2796,6 +2853,9 @@ func (s *DealsService) AddInstallment(ctx context.Context, id DealID, opts ...Ad
 		return nil, err
 	}
 	cfg := newAddInstallmentOptions(opts)
+	if err := cfg.payload.validate(); err != nil {
+		return nil, err
+	}
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap())
@@ -2831,6 +2891,9 @@ func (s *DealsService) UpdateInstallment(ctx context.Context, id DealID, install
 		return nil, err
 	}
 	cfg := newUpdateInstallmentOptions(opts)
+	if err := cfg.payload.validate(); err != nil {
+		return nil, err
+	}
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap())
@@ -3101,17 +3164,28 @@ func (p dealPayload) toMap() map[string]interface{} {
 	if p.value != nil {
 		body["value"] = *p.value
 	}
+	if p.valueExact != nil {
+		body["value"] = *p.valueExact
+	}
 	if p.currency != nil {
 		body["currency"] = *p.currency
 	}
 	if p.ownerID != nil {
 		body["owner_id"] = int(*p.ownerID)
 	}
-	if p.personID != nil {
-		body["person_id"] = int(*p.personID)
+	if p.personID.set {
+		if p.personID.value == nil {
+			body["person_id"] = nil
+		} else {
+			body["person_id"] = int(*p.personID.value)
+		}
 	}
-	if p.orgID != nil {
-		body["org_id"] = int(*p.orgID)
+	if p.orgID.set {
+		if p.orgID.value == nil {
+			body["org_id"] = nil
+		} else {
+			body["org_id"] = int(*p.orgID.value)
+		}
 	}
 	if p.stageID != nil {
 		body["stage_id"] = int(*p.stageID)
@@ -3122,8 +3196,12 @@ func (p dealPayload) toMap() map[string]interface{} {
 	if p.status != nil {
 		body["status"] = string(*p.status)
 	}
-	if p.expectedCloseDate != nil {
-		body["expected_close_date"] = *p.expectedCloseDate
+	if p.expectedCloseDate.set {
+		if p.expectedCloseDate.value == nil {
+			body["expected_close_date"] = nil
+		} else {
+			body["expected_close_date"] = *p.expectedCloseDate.value
+		}
 	}
 	if p.probability.set {
 		if p.probability.value == nil {
@@ -3187,6 +3265,9 @@ func (p dealProductPayload) toMap() map[string]interface{} {
 	if p.itemPrice != nil {
 		body["item_price"] = *p.itemPrice
 	}
+	if p.itemPriceExact != nil {
+		body["item_price"] = *p.itemPriceExact
+	}
 	if p.quantity != nil {
 		body["quantity"] = *p.quantity
 	}
@@ -3225,6 +3306,9 @@ func (p additionalDiscountPayload) toMap() map[string]interface{} {
 	if p.amount != nil {
 		body["amount"] = *p.amount
 	}
+	if p.amountExact != nil {
+		body["amount"] = *p.amountExact
+	}
 	if p.discountType != nil {
 		body["type"] = string(*p.discountType)
 	}
@@ -3239,6 +3323,9 @@ func (p installmentPayload) toMap() map[string]interface{} {
 	if p.amount != nil {
 		body["amount"] = *p.amount
 	}
+	if p.amountExact != nil {
+		body["amount"] = *p.amountExact
+	}
 	if p.billingDate != nil {
 		body["billing_date"] = *p.billingDate
 	}
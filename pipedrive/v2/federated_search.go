@@ -0,0 +1,404 @@
+package v2
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+const defaultFederatedSearchPageSize = 50
+
+// federatedSearchSources lists the sources in tie-break order. Item search only
+// covers the types no entity search method exists for.
+var federatedSearchSources = []ItemSearchType{
+	ItemSearchTypeDeal,
+	ItemSearchTypePerson,
+	ItemSearchTypeOrganization,
+	ItemSearchTypeProduct,
+	ItemSearchTypeLead,
+	ItemSearchTypeProject,
+	itemSearchSource,
+}
+
+const itemSearchSource ItemSearchType = "item_search"
+
+type FederatedSearchOption interface {
+	applyFederatedSearch(*federatedSearchOptions)
+}
+
+type federatedSearchOptions struct {
+	types          map[ItemSearchType]bool
+	limit          int
+	cursor         string
+	exactMatch     bool
+	requestOptions []pipedrive.RequestOption
+}
+
+type federatedSearchOptionFunc func(*federatedSearchOptions)
+
+func (f federatedSearchOptionFunc) applyFederatedSearch(cfg *federatedSearchOptions) {
+	f(cfg)
+}
+
+// WithFederatedSearchTypes limits the searched item types. All types are
+// searched by default.
+func WithFederatedSearchTypes(types ...ItemSearchType) FederatedSearchOption {
+	return federatedSearchOptionFunc(func(cfg *federatedSearchOptions) {
+		if len(types) == 0 {
+			return
+		}
+		cfg.types = make(map[ItemSearchType]bool, len(types))
+		for _, itemType := range types {
+			cfg.types[itemType] = true
+		}
+	})
+}
+
+func WithFederatedSearchPageSize(limit int) FederatedSearchOption {
+	return federatedSearchOptionFunc(func(cfg *federatedSearchOptions) {
+		if limit > 0 {
+			cfg.limit = limit
+		}
+	})
+}
+
+func WithFederatedSearchCursor(cursor string) FederatedSearchOption {
+	return federatedSearchOptionFunc(func(cfg *federatedSearchOptions) {
+		cfg.cursor = cursor
+	})
+}
+
+func WithFederatedSearchExactMatch(enabled bool) FederatedSearchOption {
+	return federatedSearchOptionFunc(func(cfg *federatedSearchOptions) {
+		cfg.exactMatch = enabled
+	})
+}
+
+func WithFederatedSearchRequestOptions(opts ...pipedrive.RequestOption) FederatedSearchOption {
+	return federatedSearchOptionFunc(func(cfg *federatedSearchOptions) {
+		cfg.requestOptions = append(cfg.requestOptions, opts...)
+	})
+}
+
+func newFederatedSearchOptions(opts []FederatedSearchOption) federatedSearchOptions {
+	cfg := federatedSearchOptions{limit: defaultFederatedSearchPageSize}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt.applyFederatedSearch(&cfg)
+	}
+	return cfg
+}
+
+func (cfg federatedSearchOptions) wants(itemType ItemSearchType) bool {
+	return cfg.types == nil || cfg.types[itemType]
+}
+
+// federatedSourceState is the resume position of one source: the cursor of
+// the page holding the next unread hit and the number of hits to skip on it.
+type federatedSourceState struct {
+	Cursor *string `json:"c,omitempty"`
+	Offset int     `json:"o,omitempty"`
+	Done   bool    `json:"d,omitempty"`
+}
+
+type federatedBufferedHit struct {
+	hit    SearchHit
+	cursor *string
+	index  int
+}
+
+type federatedBuffer struct {
+	hits []federatedBufferedHit
+	next *string
+	err  error
+}
+
+type federatedSearchFetch func(ctx context.Context, cursor *string, limit int) ([]SearchHit, *string, error)
+
+// FederatedSearch runs the deal, person, organization, product, lead and
+// project searches plus an item search for files and mail attachments
+// concurrently, and returns one page of hits merged by descending result
+// score. The returned cursor resumes the merged stream.
+func (s *ItemSearchService) FederatedSearch(ctx context.Context, term string, opts ...FederatedSearchOption) ([]SearchHit, *string, error) {
+	cfg := newFederatedSearchOptions(opts)
+	return s.federatedSearch(ctx, term, cfg, cfg.cursor)
+}
+
+func (s *ItemSearchService) FederatedSearchPager(term string, opts ...FederatedSearchOption) *pipedrive.CursorPager[SearchHit] {
+	cfg := newFederatedSearchOptions(opts)
+	return pipedrive.NewCursorPager(func(ctx context.Context, cursor *string) ([]SearchHit, *string, error) {
+		start := cfg.cursor
+		if cursor != nil {
+			start = *cursor
+		}
+		return s.federatedSearch(ctx, term, cfg, start)
+	})
+}
+
+func (s *ItemSearchService) ForEachFederatedSearch(ctx context.Context, term string, fn func(SearchHit) error, opts ...FederatedSearchOption) error {
+	return s.FederatedSearchPager(term, opts...).ForEach(ctx, fn)
+}
+
+func (s *ItemSearchService) federatedSearch(ctx context.Context, term string, cfg federatedSearchOptions, cursor string) ([]SearchHit, *string, error) {
+	states, err := decodeFederatedCursor(cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	fetchers := s.federatedFetchers(term, cfg)
+	if len(fetchers) == 0 {
+		return nil, nil, fmt.Errorf("no searchable item types selected")
+	}
+
+	buffers := make(map[ItemSearchType]*federatedBuffer, len(fetchers))
+	var wg sync.WaitGroup
+	for _, source := range federatedSearchSources {
+		fetch, ok := fetchers[source]
+		if !ok || states[source].Done {
+			continue
+		}
+		buffer := &federatedBuffer{}
+		buffers[source] = buffer
+		wg.Add(1)
+		go func(fetch federatedSearchFetch, state federatedSourceState) {
+			defer wg.Done()
+			buffer.fill(ctx, fetch, state, cfg.limit)
+		}(fetch, states[source])
+	}
+	wg.Wait()
+	for _, source := range federatedSearchSources {
+		if buffer, ok := buffers[source]; ok && buffer.err != nil {
+			return nil, nil, fmt.Errorf("search %s: %w", source, buffer.err)
+		}
+	}
+
+	consumed := make(map[ItemSearchType]int, len(buffers))
+	hits := make([]SearchHit, 0, cfg.limit)
+	for len(hits) < cfg.limit {
+		best := ItemSearchType("")
+		for _, source := range federatedSearchSources {
+			buffer, ok := buffers[source]
+			if !ok || consumed[source] >= len(buffer.hits) {
+				continue
+			}
+			if best == "" || buffer.hits[consumed[source]].hit.Score() > buffers[best].hits[consumed[best]].hit.Score() {
+				best = source
+			}
+		}
+		if best == "" {
+			break
+		}
+		hits = append(hits, buffers[best].hits[consumed[best]].hit)
+		consumed[best]++
+	}
+
+	next := make(map[ItemSearchType]federatedSourceState, len(buffers))
+	more := false
+	for source, buffer := range buffers {
+		var state federatedSourceState
+		if n := consumed[source]; n < len(buffer.hits) {
+			state = federatedSourceState{Cursor: buffer.hits[n].cursor, Offset: buffer.hits[n].index}
+		} else if buffer.next != nil {
+			state = federatedSourceState{Cursor: buffer.next}
+		} else {
+			state = federatedSourceState{Done: true}
+		}
+		if !state.Done {
+			more = true
+		}
+		next[source] = state
+	}
+	for source, state := range states {
+		if state.Done {
+			next[source] = state
+		}
+	}
+	if !more {
+		return hits, nil, nil
+	}
+	token, err := encodeFederatedCursor(next)
+	if err != nil {
+		return nil, nil, err
+	}
+	return hits, &token, nil
+}
+
+// fill reads pages from the source's resume position until it holds limit
+// hits or the source is exhausted.
+func (b *federatedBuffer) fill(ctx context.Context, fetch federatedSearchFetch, state federatedSourceState, limit int) {
+	cursor, skip := state.Cursor, state.Offset
+	for {
+		hits, next, err := fetch(ctx, cursor, limit)
+		if err != nil {
+			b.err = err
+			return
+		}
+		for i := skip; i < len(hits); i++ {
+			b.hits = append(b.hits, federatedBufferedHit{hit: hits[i], cursor: cursor, index: i})
+		}
+		b.next = next
+		skip = 0
+		if len(b.hits) >= limit || next == nil || (cursor != nil && *next == *cursor) {
+			return
+		}
+		cursor = next
+	}
+}
+
+func (s *ItemSearchService) federatedFetchers(term string, cfg federatedSearchOptions) map[ItemSearchType]federatedSearchFetch {
+	client := s.client
+	fetchers := map[ItemSearchType]federatedSearchFetch{}
+	if cfg.wants(ItemSearchTypeDeal) {
+		fetchers[ItemSearchTypeDeal] = func(ctx context.Context, cursor *string, limit int) ([]SearchHit, *string, error) {
+			opts := []SearchDealsOption{WithDealSearchPageSize(limit), WithDealSearchExactMatch(cfg.exactMatch), WithDealRequestOptions(cfg.requestOptions...)}
+			if cursor != nil {
+				opts = append(opts, WithDealSearchCursor(*cursor))
+			}
+			results, next, err := client.Deals.Search(ctx, term, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits, err := results.Hits()
+			return asSearchHits(hits), next, err
+		}
+	}
+	if cfg.wants(ItemSearchTypePerson) {
+		fetchers[ItemSearchTypePerson] = func(ctx context.Context, cursor *string, limit int) ([]SearchHit, *string, error) {
+			opts := []SearchPersonsOption{WithPersonSearchPageSize(limit), WithPersonSearchExactMatch(cfg.exactMatch), WithPersonRequestOptions(cfg.requestOptions...)}
+			if cursor != nil {
+				opts = append(opts, WithPersonSearchCursor(*cursor))
+			}
+			results, next, err := client.Persons.Search(ctx, term, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits, err := results.Hits()
+			return asSearchHits(hits), next, err
+		}
+	}
+	if cfg.wants(ItemSearchTypeOrganization) {
+		fetchers[ItemSearchTypeOrganization] = func(ctx context.Context, cursor *string, limit int) ([]SearchHit, *string, error) {
+			opts := []SearchOrganizationsOption{WithOrganizationSearchPageSize(limit), WithOrganizationSearchExactMatch(cfg.exactMatch), WithOrganizationRequestOptions(cfg.requestOptions...)}
+			if cursor != nil {
+				opts = append(opts, WithOrganizationSearchCursor(*cursor))
+			}
+			results, next, err := client.Organizations.Search(ctx, term, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits, err := results.Hits()
+			return asSearchHits(hits), next, err
+		}
+	}
+	if cfg.wants(ItemSearchTypeProduct) {
+		fetchers[ItemSearchTypeProduct] = func(ctx context.Context, cursor *string, limit int) ([]SearchHit, *string, error) {
+			opts := []SearchProductsOption{WithProductSearchPageSize(limit), WithProductSearchExactMatch(cfg.exactMatch), WithProductRequestOptions(cfg.requestOptions...)}
+			if cursor != nil {
+				opts = append(opts, WithProductSearchCursor(*cursor))
+			}
+			results, next, err := client.Products.Search(ctx, term, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits, err := results.Hits()
+			return asSearchHits(hits), next, err
+		}
+	}
+	if cfg.wants(ItemSearchTypeLead) {
+		fetchers[ItemSearchTypeLead] = func(ctx context.Context, cursor *string, limit int) ([]SearchHit, *string, error) {
+			opts := []SearchLeadsOption{WithLeadSearchPageSize(limit), WithLeadSearchExactMatch(cfg.exactMatch), WithLeadRequestOptions(cfg.requestOptions...)}
+			if cursor != nil {
+				opts = append(opts, WithLeadSearchCursor(*cursor))
+			}
+			results, next, err := client.Leads.Search(ctx, term, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits, err := results.Hits()
+			return asSearchHits(hits), next, err
+		}
+	}
+	if cfg.wants(ItemSearchTypeProject) {
+		fetchers[ItemSearchTypeProject] = func(ctx context.Context, cursor *string, limit int) ([]SearchHit, *string, error) {
+			opts := []SearchProjectsOption{WithProjectSearchPageSize(limit), WithProjectSearchExactMatch(cfg.exactMatch), WithProjectRequestOptions(cfg.requestOptions...)}
+			if cursor != nil {
+				opts = append(opts, WithProjectSearchCursor(*cursor))
+			}
+			results, next, err := client.Projects.Search(ctx, term, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits := make([]SearchHit, 0, len(results))
+			for _, result := range results {
+				hits = append(hits, result.Hit())
+			}
+			return hits, next, nil
+		}
+	}
+
+	var itemTypes []ItemSearchType
+	for _, itemType := range []ItemSearchType{ItemSearchTypeFile, ItemSearchTypeMailAttachment} {
+		if cfg.wants(itemType) {
+			itemTypes = append(itemTypes, itemType)
+		}
+	}
+	if len(itemTypes) > 0 {
+		fetchers[itemSearchSource] = func(ctx context.Context, cursor *string, limit int) ([]SearchHit, *string, error) {
+			opts := []SearchItemsOption{
+				WithItemSearchTypes(itemTypes...),
+				WithItemSearchPageSize(limit),
+				WithItemSearchExactMatch(cfg.exactMatch),
+				WithItemSearchRequestOptions(cfg.requestOptions...),
+			}
+			if cursor != nil {
+				opts = append(opts, WithItemSearchCursor(*cursor))
+			}
+			results, next, err := s.Search(ctx, term, opts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits, err := results.Hits()
+			if err != nil {
+				return nil, nil, err
+			}
+			return hits.Items, next, nil
+		}
+	}
+	return fetchers
+}
+
+func asSearchHits[H SearchHit](hits []H) []SearchHit {
+	out := make([]SearchHit, len(hits))
+	for i, hit := range hits {
+		out[i] = hit
+	}
+	return out
+}
+
+func encodeFederatedCursor(states map[ItemSearchType]federatedSourceState) (string, error) {
+	raw, err := json.Marshal(states)
+	if err != nil {
+		return "", fmt.Errorf("encode federated search cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeFederatedCursor(cursor string) (map[ItemSearchType]federatedSourceState, error) {
+	states := map[ItemSearchType]federatedSourceState{}
+	if cursor == "" {
+		return states, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid federated search cursor: %w", err)
+	}
+	if err := json.Unmarshal(raw, &states); err != nil {
+		return nil, fmt.Errorf("invalid federated search cursor: %w", err)
+	}
+	return states, nil
+}
@@ -0,0 +1,180 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+type federatedSearchFake struct {
+	mu     sync.Mutex
+	scores map[string][]float64
+	calls  map[string]int
+	query  map[string]string
+}
+
+func (f *federatedSearchFake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	source := strings.TrimSuffix(strings.Trim(r.URL.Path, "/"), "/search")
+	scores, ok := f.scores[source]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	f.calls[source]++
+	f.query[source] = r.URL.RawQuery
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	end := offset + limit
+	if end > len(scores) {
+		end = len(scores)
+	}
+	items := []map[string]interface{}{}
+	for i := offset; i < end; i++ {
+		item := map[string]interface{}{"id": i + 1}
+		switch source {
+		case "itemSearch":
+			item["type"] = "file"
+		case "leads":
+			item["id"] = "lead-" + strconv.Itoa(i+1)
+		}
+		items = append(items, map[string]interface{}{"result_score": scores[i], "item": item})
+	}
+	var next interface{}
+	if end < len(scores) {
+		next = strconv.Itoa(end)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":            map[string]interface{}{"items": items},
+		"additional_data": map[string]interface{}{"next_cursor": next},
+	})
+}
+
+func newFederatedSearchClient(t *testing.T, fake *federatedSearchFake) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	client, err := NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	return client
+}
+
+func TestItemSearchService_FederatedSearchPager(t *testing.T) {
+	t.Parallel()
+
+	fake := &federatedSearchFake{
+		scores: map[string][]float64{
+			"deals":         {0.9, 0.7, 0.3, 0.1},
+			"persons":       {0.8, 0.75, 0.2},
+			"organizations": {0.5},
+			"products":      {},
+			"leads":         {0.65, 0.05},
+			"projects":      {0.4},
+			"itemSearch":    {0.6, 0.01},
+		},
+		calls: map[string]int{},
+		query: map[string]string{},
+	}
+	client := newFederatedSearchClient(t, fake)
+
+	pager := client.ItemSearch.FederatedSearchPager("acme", WithFederatedSearchPageSize(3), WithFederatedSearchExactMatch(true))
+	var (
+		scores []float64
+		pages  int
+		seen   = map[string]bool{}
+	)
+	for pager.Next(context.Background()) {
+		pages++
+		if len(pager.Items()) > 3 {
+			t.Fatalf("page %d has %d items", pages, len(pager.Items()))
+		}
+		for _, hit := range pager.Items() {
+			raw, _ := json.Marshal(hit)
+			key := string(hit.Type()) + string(raw)
+			if seen[key] {
+				t.Fatalf("duplicate hit %s", key)
+			}
+			seen[key] = true
+			scores = append(scores, hit.Score())
+		}
+	}
+	if err := pager.Err(); err != nil {
+		t.Fatalf("pager error: %v", err)
+	}
+	if len(scores) != 13 {
+		t.Fatalf("expected 13 hits, got %d: %v", len(scores), scores)
+	}
+	if !sort.SliceIsSorted(scores, func(i, j int) bool { return scores[i] > scores[j] }) {
+		t.Fatalf("hits not merged by score: %v", scores)
+	}
+	if pages != 5 {
+		t.Fatalf("expected 5 pages, got %d", pages)
+	}
+	if q := fake.query["itemSearch"]; !strings.Contains(q, "item_types=file%2Cmail_attachment") || !strings.Contains(q, "exact_match=true") {
+		t.Fatalf("unexpected item search query: %s", q)
+	}
+}
+
+func TestItemSearchService_FederatedSearch(t *testing.T) {
+	t.Parallel()
+
+	fake := &federatedSearchFake{
+		scores: map[string][]float64{"deals": {0.9, 0.2}, "persons": {0.5}},
+		calls:  map[string]int{},
+		query:  map[string]string{},
+	}
+	client := newFederatedSearchClient(t, fake)
+
+	hits, next, err := client.ItemSearch.FederatedSearch(
+		context.Background(), "acme",
+		WithFederatedSearchTypes(ItemSearchTypeDeal, ItemSearchTypePerson),
+		WithFederatedSearchPageSize(2),
+	)
+	if err != nil {
+		t.Fatalf("FederatedSearch error: %v", err)
+	}
+	if len(hits) != 2 || hits[0].Type() != ItemSearchTypeDeal || hits[1].Type() != ItemSearchTypePerson || next == nil {
+		t.Fatalf("unexpected first page: %v, %v", hits, next)
+	}
+	if _, ok := hits[0].(*DealSearchHit); !ok {
+		t.Fatalf("unexpected hit type %T", hits[0])
+	}
+
+	hits, next, err = client.ItemSearch.FederatedSearch(
+		context.Background(), "acme",
+		WithFederatedSearchTypes(ItemSearchTypeDeal, ItemSearchTypePerson),
+		WithFederatedSearchPageSize(2),
+		WithFederatedSearchCursor(*next),
+	)
+	if err != nil {
+		t.Fatalf("FederatedSearch error: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Score() != 0.2 || next != nil {
+		t.Fatalf("unexpected second page: %v, %v", hits, next)
+	}
+	if fake.calls["persons"] != 1 {
+		t.Fatalf("expected exhausted person search to be skipped, got %d calls", fake.calls["persons"])
+	}
+	if len(fake.calls) != 2 {
+		t.Fatalf("unexpected sources searched: %v", fake.calls)
+	}
+
+	if _, _, err := client.ItemSearch.FederatedSearch(context.Background(), "acme", WithFederatedSearchCursor("%%%")); err == nil {
+		t.Fatalf("expected invalid cursor error")
+	}
+}
@@ -0,0 +1,413 @@
+package v2
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Canonical labels accepted by Pipedrive for email and phone values. Emails
+// have no mobile label.
+const (
+	LabelWork   = "work"
+	LabelHome   = "home"
+	LabelMobile = "mobile"
+	LabelOther  = "other"
+)
+
+var labelAliases = map[string]string{
+	"work": LabelWork, "office": LabelWork, "business": LabelWork, "company": LabelWork,
+	"corporate": LabelWork, "job": LabelWork,
+	"home": LabelHome, "personal": LabelHome, "private": LabelHome, "house": LabelHome,
+	"mobile": LabelMobile, "cell": LabelMobile, "cellular": LabelMobile, "cellphone": LabelMobile,
+	"gsm": LabelMobile, "handy": LabelMobile, "mob": LabelMobile,
+	"other": LabelOther,
+}
+
+// LabeledValueError reports an email or phone value that cannot be
+// normalized.
+type LabeledValueError struct {
+	Kind   string
+	Value  string
+	Reason string
+}
+
+func (e *LabeledValueError) Error() string {
+	if e == nil {
+		return "invalid labeled value"
+	}
+	return fmt.Sprintf("invalid %s %q: %s", e.Kind, e.Value, e.Reason)
+}
+
+// ContactNormalization configures how person emails and phones are normalized
+// before Create or Update sends them.
+type ContactNormalization struct {
+	// DefaultRegion is the ISO 3166 region used for phone numbers written
+	// without an international prefix, such as "FI" or "US".
+	DefaultRegion string
+	// SkipInvalid drops values that fail normalization instead of returning
+	// a *LabeledValueError.
+	SkipInvalid bool
+}
+
+// WithPersonContactNormalization normalizes the emails and phones set by
+// WithPersonEmails and WithPersonPhones when the request is sent, regardless
+// of option order.
+func WithPersonContactNormalization(cfg ContactNormalization) PersonOption {
+	return personFieldOption(func(payload *personPayload) {
+		payload.contactNormalization = &cfg
+	})
+}
+
+func (p *personPayload) normalizeContacts() error {
+	cfg := p.contactNormalization
+	if cfg == nil {
+		return nil
+	}
+	if p.emails.set {
+		emails, err := normalizeLabeledValues(p.emails.value, cfg.SkipInvalid, CanonicalEmailLabel, NormalizeEmail)
+		if err != nil {
+			return err
+		}
+		p.emails.value = emails
+	}
+	if p.phones.set {
+		phones, err := normalizeLabeledValues(p.phones.value, cfg.SkipInvalid, CanonicalPhoneLabel, func(phone string) (string, error) {
+			return NormalizePhone(phone, cfg.DefaultRegion)
+		})
+		if err != nil {
+			return err
+		}
+		p.phones.value = phones
+	}
+	return nil
+}
+
+// NormalizeEmails normalizes every value and label, drops duplicates and
+// leaves exactly one value marked primary.
+func NormalizeEmails(values []LabeledValue) ([]LabeledValue, error) {
+	return normalizeLabeledValues(values, false, CanonicalEmailLabel, NormalizeEmail)
+}
+
+// NormalizePhones is NormalizeEmails for phone numbers, which are converted to
+// E.164 using defaultRegion for national numbers.
+func NormalizePhones(values []LabeledValue, defaultRegion string) ([]LabeledValue, error) {
+	return normalizeLabeledValues(values, false, CanonicalPhoneLabel, func(phone string) (string, error) {
+		return NormalizePhone(phone, defaultRegion)
+	})
+}
+
+func normalizeLabeledValues(values []LabeledValue, skipInvalid bool, label func(string) string, normalize func(string) (string, error)) ([]LabeledValue, error) {
+	out := make([]LabeledValue, 0, len(values))
+	index := make(map[string]int, len(values))
+	for _, v := range values {
+		value, err := normalize(v.Value)
+		if err != nil {
+			if skipInvalid {
+				continue
+			}
+			return nil, err
+		}
+		if i, ok := index[value]; ok {
+			// Keep the first spelling but let a primary duplicate promote it.
+			out[i].Primary = out[i].Primary || v.Primary
+			continue
+		}
+		index[value] = len(out)
+		out = append(out, LabeledValue{Value: value, Primary: v.Primary, Label: label(v.Label)})
+	}
+
+	primary := -1
+	for i := range out {
+		if out[i].Primary && primary < 0 {
+			primary = i
+		}
+		out[i].Primary = false
+	}
+	if len(out) > 0 {
+		if primary < 0 {
+			primary = 0
+		}
+		out[primary].Primary = true
+	}
+	return out, nil
+}
+
+// CanonicalPhoneLabel maps common label spellings such as "Cell" or "Office"
+// to work, home, mobile or other. An empty label stays empty so Pipedrive
+// applies its default.
+func CanonicalPhoneLabel(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label == "" {
+		return ""
+	}
+	if canonical, ok := labelAliases[label]; ok {
+		return canonical
+	}
+	return LabelOther
+}
+
+// CanonicalEmailLabel is CanonicalPhoneLabel for emails, where mobile labels
+// become other.
+func CanonicalEmailLabel(label string) string {
+	label = CanonicalPhoneLabel(label)
+	if label == LabelMobile {
+		return LabelOther
+	}
+	return label
+}
+
+// NormalizeEmail lower-cases an address, converts an internationalized domain
+// to its ASCII (punycode) form and checks the syntax of both parts.
+func NormalizeEmail(email string) (string, error) {
+	invalid := func(reason string) (string, error) {
+		return "", &LabeledValueError{Kind: "email", Value: email, Reason: reason}
+	}
+
+	value := strings.ToLower(strings.TrimSpace(email))
+	value = strings.TrimPrefix(value, "mailto:")
+	at := strings.LastIndex(value, "@")
+	if at <= 0 || at == len(value)-1 {
+		return invalid("missing local part or domain")
+	}
+	local, domain := value[:at], strings.TrimSuffix(value[at+1:], ".")
+	if len(local) > 64 {
+		return invalid("local part longer than 64 bytes")
+	}
+	if strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..") {
+		return invalid("misplaced dot in local part")
+	}
+	for _, r := range local {
+		if r < 0x80 && !isEmailAtom(r) && r != '.' {
+			return invalid(fmt.Sprintf("character %q not allowed in local part", r))
+		}
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return invalid("domain needs at least two labels")
+	}
+	for i, label := range labels {
+		ascii, err := idnaLabel(label)
+		if err != nil {
+			return invalid(err.Error())
+		}
+		labels[i] = ascii
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return invalid("top-level domain is numeric")
+	}
+	domain = strings.Join(labels, ".")
+	if len(local)+1+len(domain) > 254 {
+		return invalid("address longer than 254 bytes")
+	}
+	return local + "@" + domain, nil
+}
+
+func isEmailAtom(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+/=?^_`{|}~-", r)
+}
+
+// idnaLabel converts a lower-cased domain label to its ASCII form and checks
+// the letters-digits-hyphen rule.
+func idnaLabel(label string) (string, error) {
+	if label == "" {
+		return "", fmt.Errorf("empty domain label")
+	}
+	for _, r := range label {
+		if r >= 0x80 {
+			encoded, err := punycodeEncode(label)
+			if err != nil {
+				return "", err
+			}
+			label = "xn--" + encoded
+			break
+		}
+	}
+	if len(label) > 63 {
+		return "", fmt.Errorf("domain label longer than 63 bytes")
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return "", fmt.Errorf("domain label starts or ends with a hyphen")
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return "", fmt.Errorf("character %q not allowed in domain", r)
+		}
+	}
+	return label, nil
+}
+
+// punycodeEncode implements the RFC 3492 encoder for a single label.
+func punycodeEncode(label string) (string, error) {
+	const (
+		base        = 36
+		tmin        = 1
+		tmax        = 26
+		skew        = 38
+		damp        = 700
+		initialBias = 72
+		initialN    = 128
+	)
+	adapt := func(delta, points int, first bool) int {
+		if first {
+			delta /= damp
+		} else {
+			delta /= 2
+		}
+		delta += delta / points
+		k := 0
+		for delta > (base-tmin)*tmax/2 {
+			delta /= base - tmin
+			k += base
+		}
+		return k + (base-tmin+1)*delta/(delta+skew)
+	}
+	digit := func(d int) byte {
+		if d < 26 {
+			return byte('a' + d)
+		}
+		return byte('0' + d - 26)
+	}
+
+	runes := []rune(label)
+	var out []byte
+	for _, r := range runes {
+		if r < 0x80 {
+			out = append(out, byte(r))
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) {
+			return "", fmt.Errorf("character %q not allowed in domain", r)
+		}
+	}
+	basic := len(out)
+	handled := basic
+	if basic > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := initialN, 0, initialBias
+	for handled < len(runes) {
+		m := int(unicode.MaxRune) + 1
+		for _, r := range runes {
+			if int(r) >= n && int(r) < m {
+				m = int(r)
+			}
+		}
+		delta += (m - n) * (handled + 1)
+		n = m
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+			}
+			if int(r) != n {
+				continue
+			}
+			q := delta
+			for k := base; ; k += base {
+				t := k - bias
+				if t < tmin {
+					t = tmin
+				} else if t > tmax {
+					t = tmax
+				}
+				if q < t {
+					break
+				}
+				out = append(out, digit(t+(q-t)%(base-t)))
+				q = (q - t) / (base - t)
+			}
+			out = append(out, digit(q))
+			bias = adapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+	return string(out), nil
+}
+
+type phoneRegion struct {
+	code          string
+	trunk         string
+	international string
+}
+
+// phoneRegions maps ISO 3166 regions to their calling code, national trunk
+// prefix and international call prefix. Regions without a trunk prefix keep
+// the leading digits of national numbers.
+var phoneRegions = map[string]phoneRegion{
+	"AE": {"971", "0", "00"}, "AR": {"54", "0", "00"}, "AT": {"43", "0", "00"},
+	"AU": {"61", "0", "0011"}, "BE": {"32", "0", "00"}, "BR": {"55", "0", "00"},
+	"CA": {"1", "1", "011"}, "CH": {"41", "0", "00"}, "CL": {"56", "", "00"},
+	"CN": {"86", "0", "00"}, "CO": {"57", "", "00"}, "CZ": {"420", "", "00"},
+	"DE": {"49", "0", "00"}, "DK": {"45", "", "00"}, "EE": {"372", "", "00"},
+	"EG": {"20", "0", "00"}, "ES": {"34", "", "00"}, "FI": {"358", "0", "00"},
+	"FR": {"33", "0", "00"}, "GB": {"44", "0", "00"}, "GR": {"30", "", "00"},
+	"HK": {"852", "", "001"}, "HU": {"36", "06", "00"}, "IE": {"353", "0", "00"},
+	"IL": {"972", "0", "00"}, "IN": {"91", "0", "00"}, "IS": {"354", "", "00"},
+	"IT": {"39", "", "00"}, "JP": {"81", "0", "010"}, "KE": {"254", "0", "000"},
+	"KR": {"82", "0", "001"}, "LT": {"370", "0", "00"}, "LU": {"352", "", "00"},
+	"LV": {"371", "", "00"}, "MX": {"52", "", "00"}, "NG": {"234", "0", "009"},
+	"NL": {"31", "0", "00"}, "NO": {"47", "", "00"}, "NZ": {"64", "0", "00"},
+	"PH": {"63", "0", "00"}, "PL": {"48", "", "00"}, "PT": {"351", "", "00"},
+	"RO": {"40", "0", "00"}, "RU": {"7", "8", "810"}, "SA": {"966", "0", "00"},
+	"SE": {"46", "0", "00"}, "SG": {"65", "", "000"}, "SK": {"421", "0", "00"},
+	"TR": {"90", "0", "00"}, "UA": {"380", "0", "00"}, "US": {"1", "1", "011"},
+	"ZA": {"27", "0", "00"},
+}
+
+// NormalizePhone converts a phone number to E.164 ("+" and up to 15 digits).
+// Numbers starting with "+" or the region's international prefix keep their
+// country code; other numbers are treated as national numbers of
+// defaultRegion. Formatting characters are ignored, letters and extensions
+// are rejected.
+func NormalizePhone(phone, defaultRegion string) (string, error) {
+	invalid := func(reason string) (string, error) {
+		return "", &LabeledValueError{Kind: "phone", Value: phone, Reason: reason}
+	}
+
+	value := strings.TrimSpace(phone)
+	international := strings.HasPrefix(value, "+")
+	var digits strings.Builder
+	for i, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/' || r == '\u00a0':
+		default:
+			return invalid(fmt.Sprintf("character %q not allowed", r))
+		}
+	}
+	number := digits.String()
+	if number == "" {
+		return invalid("no digits")
+	}
+
+	if !international {
+		region, ok := phoneRegions[strings.ToUpper(strings.TrimSpace(defaultRegion))]
+		switch {
+		case ok && strings.HasPrefix(number, region.international):
+			number = strings.TrimPrefix(number, region.international)
+		case strings.HasPrefix(number, "00") && (!ok || region.international == "00"):
+			number = strings.TrimPrefix(number, "00")
+		case !ok && defaultRegion == "":
+			return invalid("national number without a default region")
+		case !ok:
+			return invalid(fmt.Sprintf("unsupported region %q", defaultRegion))
+		default:
+			if region.trunk != "" {
+				number = strings.TrimPrefix(number, region.trunk)
+			}
+			number = region.code + number
+		}
+	}
+	if number[0] == '0' {
+		return invalid("country code cannot start with 0")
+	}
+	if len(number) < 7 || len(number) > 15 {
+		return invalid("E.164 numbers have 7 to 15 digits")
+	}
+	return "+" + number, nil
+}
@@ -0,0 +1,145 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	t.Parallel()
+
+	valid := map[string]string{
+		" Jane.Doe@Example.COM ":   "jane.doe@example.com",
+		"mailto:info@acme.test.":   "info@acme.test",
+		"hans@Bücher.example":      "hans@xn--bcher-kva.example",
+		"user+tag@münchen.de":      "user+tag@xn--mnchen-3ya.de",
+		"a@xn--mnchen-3ya.de":      "a@xn--mnchen-3ya.de",
+		"o'brien@sub.domain.co.uk": "o'brien@sub.domain.co.uk",
+	}
+	for in, want := range valid {
+		got, err := NormalizeEmail(in)
+		if err != nil || got != want {
+			t.Fatalf("NormalizeEmail(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"", "jane", "@acme.test", "jane@", "jane@localhost", "ja..ne@acme.test", "jane doe@acme.test", "jane@-acme.test", "jane@acme.123", "jane@acme_corp.test"} {
+		_, err := NormalizeEmail(in)
+		var lvErr *LabeledValueError
+		if !errors.As(err, &lvErr) || lvErr.Kind != "email" {
+			t.Fatalf("NormalizeEmail(%q): expected *LabeledValueError, got %v", in, err)
+		}
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		in, region, want string
+	}{
+		{"+358 40 123 4567", "", "+358401234567"},
+		{"040 123 4567", "FI", "+358401234567"},
+		{"00358 40 1234567", "FI", "+358401234567"},
+		{"(415) 555-2671", "US", "+14155552671"},
+		{"1-415-555-2671", "us", "+14155552671"},
+		{"011 44 20 7946 0958", "US", "+442079460958"},
+		{"020 7946 0958", "GB", "+442079460958"},
+		{"06 1 234 5678", "IT", "+390612345678"},
+	}
+	for _, tc := range cases {
+		got, err := NormalizePhone(tc.in, tc.region)
+		if err != nil || got != tc.want {
+			t.Fatalf("NormalizePhone(%q, %q) = %q, %v; want %q", tc.in, tc.region, got, err, tc.want)
+		}
+	}
+
+	for _, tc := range []struct{ in, region string }{
+		{"040 123 4567", ""},
+		{"040 123 4567", "XX"},
+		{"+358 40 123 4567 ext 12", ""},
+		{"call me", "FI"},
+		{"+1234", ""},
+		{"+0 123 456 789", ""},
+	} {
+		if _, err := NormalizePhone(tc.in, tc.region); err == nil {
+			t.Fatalf("NormalizePhone(%q, %q): expected error", tc.in, tc.region)
+		}
+	}
+}
+
+func TestNormalizeLabeledValues(t *testing.T) {
+	t.Parallel()
+
+	emails, err := NormalizeEmails([]LabeledValue{
+		{Value: "Jane@Acme.test", Label: "Office"},
+		{Value: "jane@acme.test", Label: "home", Primary: true},
+		{Value: "jane@home.test", Label: "Mobile", Primary: true},
+		{Value: "j@other.test", Label: "fax"},
+	})
+	if err != nil {
+		t.Fatalf("NormalizeEmails error: %v", err)
+	}
+	want := []LabeledValue{
+		{Value: "jane@acme.test", Label: LabelWork, Primary: true},
+		{Value: "jane@home.test", Label: LabelOther},
+		{Value: "j@other.test", Label: LabelOther},
+	}
+	if !reflect.DeepEqual(emails, want) {
+		t.Fatalf("unexpected emails: %#v", emails)
+	}
+
+	phones, err := NormalizePhones([]LabeledValue{{Value: "040 123 4567", Label: "cell"}, {Value: "+358401234567"}}, "FI")
+	if err != nil {
+		t.Fatalf("NormalizePhones error: %v", err)
+	}
+	if len(phones) != 1 || phones[0] != (LabeledValue{Value: "+358401234567", Label: LabelMobile, Primary: true}) {
+		t.Fatalf("unexpected phones: %#v", phones)
+	}
+}
+
+func TestPersonsService_CreateWithContactNormalization(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"id":1}}`))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	_, err = client.Persons.Create(context.Background(),
+		WithPersonContactNormalization(ContactNormalization{DefaultRegion: "FI", SkipInvalid: true}),
+		WithPersonEmails(LabeledValue{Value: "JANE@ACME.TEST", Label: "Business"}, LabeledValue{Value: "broken"}),
+		WithPersonPhones(LabeledValue{Value: "040 123 4567", Label: "Cell"}),
+	)
+	if err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	got, _ := json.Marshal(map[string]interface{}{"emails": body["emails"], "phones": body["phones"]})
+	want := `{"emails":[{"label":"work","primary":true,"value":"jane@acme.test"}],"phones":[{"label":"mobile","primary":true,"value":"+358401234567"}]}`
+	if string(got) != want {
+		t.Fatalf("unexpected body: %s", got)
+	}
+
+	_, err = client.Persons.Update(context.Background(), 1,
+		WithPersonPhones(LabeledValue{Value: "040 123 4567"}),
+		WithPersonContactNormalization(ContactNormalization{}),
+	)
+	var lvErr *LabeledValueError
+	if !errors.As(err, &lvErr) || lvErr.Kind != "phone" {
+		t.Fatalf("expected phone normalization error, got %v", err)
+	}
+}
@@ -76,6 +76,7 @@ type searchLeadsOptions struct {
 type convertLeadOptions struct {
 	payload        leadConversionPayload
 	requestOptions []pipedrive.RequestOption
+	pollPolicy     *pipedrive.PollPolicy
 }
 
 type getLeadConversionStatusOptions struct {
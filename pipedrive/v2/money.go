@@ -0,0 +1,258 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+// exactAmounts keeps monetary JSON numbers that float64 cannot represent
+// exactly, so the decimal accessors do not lose digits. Models hold it behind
+// a pointer that stays nil when every amount fits a float64, which keeps them
+// comparable and equal to values built in Go.
+type exactAmounts map[string]pipedrive.Decimal
+
+func decodeExactAmounts(data []byte, keys ...string) (*exactAmounts, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var amounts exactAmounts
+	for _, key := range keys {
+		value, ok := raw[key]
+		if !ok || string(value) == "null" {
+			continue
+		}
+		var d pipedrive.Decimal
+		if err := d.UnmarshalJSON(value); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if f, _ := d.Float64(); pipedrive.DecimalFromFloat(f).Equal(d) {
+			continue
+		}
+		if amounts == nil {
+			amounts = exactAmounts{}
+		}
+		amounts[key] = d
+	}
+	if amounts == nil {
+		return nil, nil
+	}
+	return &amounts, nil
+}
+
+// decimal returns the decoded value of key while it still agrees with the
+// float64 field, and the float64 field otherwise, so values built or changed
+// in Go are honoured.
+func (a *exactAmounts) decimal(key string, field *float64) (pipedrive.Decimal, bool) {
+	if field == nil {
+		return pipedrive.Decimal{}, false
+	}
+	if a != nil {
+		if d, ok := (*a)[key]; ok {
+			if f, _ := d.Float64(); f == *field {
+				return d, true
+			}
+		}
+	}
+	return pipedrive.DecimalFromFloat(*field), true
+}
+
+func (d *Deal) UnmarshalJSON(data []byte) error {
+	if d == nil {
+		return fmt.Errorf("v2.Deal: UnmarshalJSON on nil receiver")
+	}
+	type dealAlias Deal
+	var aux dealAlias
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("v2.Deal: decode: %w", err)
+	}
+	exact, err := decodeExactAmounts(data, "value", "arr", "mrr", "acv")
+	if err != nil {
+		return fmt.Errorf("v2.Deal: decode: %w", err)
+	}
+	*d = Deal(aux)
+	d.exact = exact
+	return nil
+}
+
+// ValueMoney returns the deal value in the deal currency with every digit
+// Pipedrive sent.
+func (d Deal) ValueMoney() (pipedrive.Money, bool) {
+	return d.money("value", d.Value)
+}
+
+func (d Deal) ARRMoney() (pipedrive.Money, bool) {
+	return d.money("arr", d.ARR)
+}
+
+func (d Deal) MRRMoney() (pipedrive.Money, bool) {
+	return d.money("mrr", d.MRR)
+}
+
+func (d Deal) ACVMoney() (pipedrive.Money, bool) {
+	return d.money("acv", d.ACV)
+}
+
+func (d Deal) money(key string, fallback *float64) (pipedrive.Money, bool) {
+	amount, ok := d.exact.decimal(key, fallback)
+	if !ok {
+		return pipedrive.Money{}, false
+	}
+	return pipedrive.NewMoney(amount, d.Currency), true
+}
+
+func (p *DealProduct) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return fmt.Errorf("v2.DealProduct: UnmarshalJSON on nil receiver")
+	}
+	type dealProductAlias DealProduct
+	var aux dealProductAlias
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("v2.DealProduct: decode: %w", err)
+	}
+	exact, err := decodeExactAmounts(data, "item_price", "sum")
+	if err != nil {
+		return fmt.Errorf("v2.DealProduct: decode: %w", err)
+	}
+	*p = DealProduct(aux)
+	p.exact = exact
+	return nil
+}
+
+func (p DealProduct) ItemPriceMoney() (pipedrive.Money, bool) {
+	amount, ok := p.exact.decimal("item_price", p.ItemPrice)
+	if !ok {
+		return pipedrive.Money{}, false
+	}
+	return pipedrive.NewMoney(amount, p.Currency), true
+}
+
+func (p DealProduct) SumMoney() (pipedrive.Money, bool) {
+	amount, ok := p.exact.decimal("sum", p.Sum)
+	if !ok {
+		return pipedrive.Money{}, false
+	}
+	return pipedrive.NewMoney(amount, p.Currency), true
+}
+
+func (a *AdditionalDiscount) UnmarshalJSON(data []byte) error {
+	if a == nil {
+		return fmt.Errorf("v2.AdditionalDiscount: UnmarshalJSON on nil receiver")
+	}
+	type additionalDiscountAlias AdditionalDiscount
+	var aux additionalDiscountAlias
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("v2.AdditionalDiscount: decode: %w", err)
+	}
+	exact, err := decodeExactAmounts(data, "amount")
+	if err != nil {
+		return fmt.Errorf("v2.AdditionalDiscount: decode: %w", err)
+	}
+	*a = AdditionalDiscount(aux)
+	a.exact = exact
+	return nil
+}
+
+// AmountDecimal returns the discount amount, which is a percentage or an
+// amount in the deal currency depending on Type.
+func (a AdditionalDiscount) AmountDecimal() (pipedrive.Decimal, bool) {
+	return a.exact.decimal("amount", a.Amount)
+}
+
+func (i *Installment) UnmarshalJSON(data []byte) error {
+	if i == nil {
+		return fmt.Errorf("v2.Installment: UnmarshalJSON on nil receiver")
+	}
+	type installmentAlias Installment
+	var aux installmentAlias
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("v2.Installment: decode: %w", err)
+	}
+	exact, err := decodeExactAmounts(data, "amount")
+	if err != nil {
+		return fmt.Errorf("v2.Installment: decode: %w", err)
+	}
+	*i = Installment(aux)
+	i.exact = exact
+	return nil
+}
+
+// AmountDecimal returns the installment amount in the deal currency.
+func (i Installment) AmountDecimal() (pipedrive.Decimal, bool) {
+	return i.exact.decimal("amount", i.Amount)
+}
+
+func (p *ProductPrice) UnmarshalJSON(data []byte) error {
+	if p == nil {
+		return fmt.Errorf("v2.ProductPrice: UnmarshalJSON on nil receiver")
+	}
+	type productPriceAlias ProductPrice
+	var aux productPriceAlias
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("v2.ProductPrice: decode: %w", err)
+	}
+	exact, err := decodeExactAmounts(data, "price", "cost", "direct_cost")
+	if err != nil {
+		return fmt.Errorf("v2.ProductPrice: decode: %w", err)
+	}
+	*p = ProductPrice(aux)
+	p.exact = exact
+	return nil
+}
+
+func (p ProductPrice) PriceMoney() pipedrive.Money {
+	amount, _ := p.exact.decimal("price", &p.Price)
+	return pipedrive.NewMoney(amount, p.Currency)
+}
+
+func (p ProductPrice) CostMoney() (pipedrive.Money, bool) {
+	amount, ok := p.exact.decimal("cost", p.Cost)
+	if !ok {
+		return pipedrive.Money{}, false
+	}
+	return pipedrive.NewMoney(amount, p.Currency), true
+}
+
+func (p ProductPrice) DirectCostMoney() (pipedrive.Money, bool) {
+	amount, ok := p.exact.decimal("direct_cost", p.DirectCost)
+	if !ok {
+		return pipedrive.Money{}, false
+	}
+	return pipedrive.NewMoney(amount, p.Currency), true
+}
+
+// WithDealValueMoney sets the deal value and currency, sending the amount
+// without a float64 conversion.
+func WithDealValueMoney(value pipedrive.Money) DealOption {
+	return dealFieldOption(func(payload *dealPayload) {
+		payload.value = nil
+		payload.valueExact = &value.Amount
+		if value.Currency != "" {
+			currency := value.Currency
+			payload.currency = &currency
+		}
+	})
+}
+
+func WithDealProductItemPriceDecimal(price pipedrive.Decimal) DealProductOption {
+	return dealProductFieldOption(func(payload *dealProductPayload) {
+		payload.itemPrice = nil
+		payload.itemPriceExact = &price
+	})
+}
+
+func WithAdditionalDiscountAmountDecimal(amount pipedrive.Decimal) AdditionalDiscountOption {
+	return additionalDiscountFieldOption(func(payload *additionalDiscountPayload) {
+		payload.amount = nil
+		payload.amountExact = &amount
+	})
+}
+
+func WithInstallmentAmountDecimal(amount pipedrive.Decimal) InstallmentOption {
+	return installmentFieldOption(func(payload *installmentPayload) {
+		payload.amount = nil
+		payload.amountExact = &amount
+	})
+}
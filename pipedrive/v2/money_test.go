@@ -0,0 +1,103 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+func TestDeal_ValueMoney(t *testing.T) {
+	t.Parallel()
+
+	var deal Deal
+	if err := json.Unmarshal([]byte(`{"id":1,"value":12345678901234567.89,"currency":"EUR","arr":null}`), &deal); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	value, ok := deal.ValueMoney()
+	if !ok || value.Amount.String() != "12345678901234567.89" || value.Currency != "EUR" {
+		t.Fatalf("unexpected value: %v, %v", value, ok)
+	}
+	if _, ok := deal.ARRMoney(); ok {
+		t.Fatalf("expected no ARR")
+	}
+
+	changed := 10.5
+	deal.Value = &changed
+	if value, _ := deal.ValueMoney(); value.Amount.String() != "10.5" {
+		t.Fatalf("expected changed value to win, got %s", value)
+	}
+
+	var products []DealProduct
+	if err := json.Unmarshal([]byte(`[{"id":1,"item_price":0.1,"quantity":3,"sum":0.3,"currency":"USD"}]`), &products); err != nil {
+		t.Fatalf("unmarshal products: %v", err)
+	}
+	if sum, _ := products[0].SumMoney(); sum.String() != "0.30 USD" {
+		t.Fatalf("unexpected sum: %s", sum)
+	}
+	var price ProductPrice
+	if err := json.Unmarshal([]byte(`{"currency":"KWD","price":1.2345,"cost":1}`), &price); err != nil {
+		t.Fatalf("unmarshal price: %v", err)
+	}
+	if price.PriceMoney().String() != "1.235 KWD" {
+		t.Fatalf("unexpected price: %s", price.PriceMoney())
+	}
+}
+
+func TestExactAmounts_KeepModelsComparable(t *testing.T) {
+	t.Parallel()
+
+	var price ProductPrice
+	if err := json.Unmarshal([]byte(`{"currency":"EUR","price":12.5}`), &price); err != nil {
+		t.Fatalf("unmarshal price: %v", err)
+	}
+	if price != (ProductPrice{Currency: "EUR", Price: 12.5}) {
+		t.Fatalf("expected decoded price to equal a built one: %#v", price)
+	}
+	var discount AdditionalDiscount
+	if err := json.Unmarshal([]byte(`{"amount":0.1}`), &discount); err != nil {
+		t.Fatalf("unmarshal discount: %v", err)
+	}
+	amount := 0.1
+	if !reflect.DeepEqual(discount, AdditionalDiscount{Amount: &amount}) {
+		t.Fatalf("expected decoded discount to deep-equal a built one: %#v", discount)
+	}
+	seen := map[Installment]bool{{}: true}
+	if !seen[Installment{}] {
+		t.Fatalf("expected installments to work as map keys")
+	}
+}
+
+func TestDealsService_CreateWithValueMoney(t *testing.T) {
+	t.Parallel()
+
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		body = string(raw)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"id":1,"value":12345678901234567.89,"currency":"EUR"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	value, _ := pipedrive.ParseMoney("12345678901234567.89", "EUR")
+	deal, err := client.Deals.Create(context.Background(), WithDealTitle("Big"), WithDealValueMoney(value))
+	if err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	if body != `{"currency":"EUR","title":"Big","value":12345678901234567.89}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+	if got, _ := deal.ValueMoney(); !got.Amount.Equal(value.Amount) {
+		t.Fatalf("unexpected decoded value: %s", got)
+	}
+}
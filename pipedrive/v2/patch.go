@@ -0,0 +1,378 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// The Patch functions compare the state an entity has with the state it
+// should have and return the update options for the fields that differ, or
+// nil when nothing changed. Fields cleared in the desired state are sent as
+// explicit JSON nulls where Pipedrive accepts them; fields that cannot be
+// cleared are left unchanged. Labels are compared as sets and sent in full,
+// custom fields are compared key by key and removed keys are sent as null.
+// Read-only fields such as counts and timestamps are ignored.
+
+func DealPatch(old, desired Deal) []UpdateDealOption {
+	var opts []UpdateDealOption
+	set := func(apply func(*dealPayload)) {
+		opts = append(opts, dealFieldOption(apply))
+	}
+	if desired.Title != "" && desired.Title != old.Title {
+		set(func(p *dealPayload) { p.title = &desired.Title })
+	}
+	if desired.Value != nil && !ptrEqual(old.Value, desired.Value) {
+		amount, _ := desired.ValueMoney()
+		set(func(p *dealPayload) { p.valueExact = &amount.Amount })
+	}
+	if desired.Currency != "" && desired.Currency != old.Currency {
+		set(func(p *dealPayload) { p.currency = &desired.Currency })
+	}
+	if desired.Status != "" && desired.Status != old.Status {
+		set(func(p *dealPayload) { p.status = &desired.Status })
+	}
+	if desired.OwnerID != nil && !ptrEqual(old.OwnerID, desired.OwnerID) {
+		set(func(p *dealPayload) { p.ownerID = desired.OwnerID })
+	}
+	if desired.PipelineID != nil && !ptrEqual(old.PipelineID, desired.PipelineID) {
+		set(func(p *dealPayload) { p.pipelineID = desired.PipelineID })
+	}
+	if desired.StageID != nil && !ptrEqual(old.StageID, desired.StageID) {
+		set(func(p *dealPayload) { p.stageID = desired.StageID })
+	}
+	if !ptrEqual(old.PersonID, desired.PersonID) {
+		set(func(p *dealPayload) { p.personID = nullablePatch(desired.PersonID) })
+	}
+	if !ptrEqual(old.OrgID, desired.OrgID) {
+		set(func(p *dealPayload) { p.orgID = nullablePatch(desired.OrgID) })
+	}
+	if !ptrEqual(emptyAsNil(old.ExpectedCloseDate), emptyAsNil(desired.ExpectedCloseDate)) {
+		set(func(p *dealPayload) { p.expectedCloseDate = nullablePatch(emptyAsNil(desired.ExpectedCloseDate)) })
+	}
+	if !ptrEqual(old.Probability, desired.Probability) {
+		set(func(p *dealPayload) { p.probability = nullablePatch(desired.Probability) })
+	}
+	if !ptrEqual(emptyAsNil(old.LostReason), emptyAsNil(desired.LostReason)) {
+		set(func(p *dealPayload) { p.lostReason = nullablePatch(emptyAsNil(desired.LostReason)) })
+	}
+	if !timeEqual(old.CloseTime, desired.CloseTime) {
+		set(func(p *dealPayload) {
+			p.closeTime.clear()
+			if desired.CloseTime != nil {
+				p.closeTime.assign(formatTimestamp(*desired.CloseTime))
+			}
+		})
+	}
+	if desired.WonTime != nil && !timeEqual(old.WonTime, desired.WonTime) {
+		set(func(p *dealPayload) {
+			value := formatTimestamp(*desired.WonTime)
+			p.wonTime = &value
+		})
+	}
+	if desired.LostTime != nil && !timeEqual(old.LostTime, desired.LostTime) {
+		set(func(p *dealPayload) {
+			value := formatTimestamp(*desired.LostTime)
+			p.lostTime = &value
+		})
+	}
+	if desired.IsArchived != old.IsArchived {
+		set(func(p *dealPayload) { p.isArchived = &desired.IsArchived })
+	}
+	if desired.VisibleTo != nil && !ptrEqual(old.VisibleTo, desired.VisibleTo) {
+		set(func(p *dealPayload) { p.visibleTo = desired.VisibleTo })
+	}
+	if desired.ChannelID != nil && !ptrEqual(old.ChannelID, desired.ChannelID) {
+		set(func(p *dealPayload) { p.channelID = desired.ChannelID })
+	}
+	if !sameLabels(old.LabelIDs, desired.LabelIDs) {
+		set(func(p *dealPayload) { p.labelIDs = slicePatch(desired.LabelIDs) })
+	}
+	if fields := customFieldsPatch(old.CustomFields, desired.CustomFields); fields != nil {
+		set(func(p *dealPayload) { p.customFields = fields })
+	}
+	return opts
+}
+
+func PersonPatch(old, desired Person) []UpdatePersonOption {
+	var opts []UpdatePersonOption
+	set := func(apply func(*personPayload)) {
+		opts = append(opts, personFieldOption(apply))
+	}
+	if desired.Name != "" && desired.Name != old.Name {
+		set(func(p *personPayload) { p.name = &desired.Name })
+	}
+	if desired.OwnerID != nil && !ptrEqual(old.OwnerID, desired.OwnerID) {
+		set(func(p *personPayload) { p.ownerID = desired.OwnerID })
+	}
+	if !ptrEqual(old.OrgID, desired.OrgID) {
+		set(func(p *personPayload) { p.orgID = nullablePatch(desired.OrgID) })
+	}
+	if !jsonEqual(old.Emails, desired.Emails) {
+		set(func(p *personPayload) { p.emails = slicePatch(desired.Emails) })
+	}
+	if !jsonEqual(old.Phones, desired.Phones) {
+		set(func(p *personPayload) { p.phones = slicePatch(desired.Phones) })
+	}
+	if !jsonEqual(old.IM, desired.IM) {
+		set(func(p *personPayload) { p.im = slicePatch(desired.IM) })
+	}
+	if desired.PostalAddress != nil && !jsonEqual(old.PostalAddress, desired.PostalAddress) {
+		set(func(p *personPayload) { p.postalAddress = desired.PostalAddress })
+	}
+	if desired.Notes != old.Notes {
+		set(func(p *personPayload) { p.notes = &desired.Notes })
+	}
+	if desired.Birthday != nil && !ptrEqual(old.Birthday, desired.Birthday) {
+		set(func(p *personPayload) { p.birthday = desired.Birthday })
+	}
+	if desired.JobTitle != old.JobTitle {
+		set(func(p *personPayload) { p.jobTitle = &desired.JobTitle })
+	}
+	if desired.VisibleTo != nil && !ptrEqual(old.VisibleTo, desired.VisibleTo) {
+		set(func(p *personPayload) { p.visibleTo = desired.VisibleTo })
+	}
+	if desired.MarketingStatus != nil && !ptrEqual(old.MarketingStatus, desired.MarketingStatus) {
+		set(func(p *personPayload) { p.marketingStatus = desired.MarketingStatus })
+	}
+	if !sameLabels(old.LabelIDs, desired.LabelIDs) {
+		set(func(p *personPayload) { p.labelIDs = slicePatch(desired.LabelIDs) })
+	}
+	if fields := customFieldsPatch(old.CustomFields, desired.CustomFields); fields != nil {
+		set(func(p *personPayload) { p.customFields = fields })
+	}
+	return opts
+}
+
+func OrganizationPatch(old, desired Organization) []UpdateOrganizationOption {
+	var opts []UpdateOrganizationOption
+	set := func(apply func(*organizationPayload)) {
+		opts = append(opts, organizationFieldOption(apply))
+	}
+	if desired.Name != "" && desired.Name != old.Name {
+		set(func(p *organizationPayload) { p.name = &desired.Name })
+	}
+	if desired.OwnerID != nil && !ptrEqual(old.OwnerID, desired.OwnerID) {
+		set(func(p *organizationPayload) { p.ownerID = desired.OwnerID })
+	}
+	if desired.VisibleTo != nil && !ptrEqual(old.VisibleTo, desired.VisibleTo) {
+		set(func(p *organizationPayload) { p.visibleTo = desired.VisibleTo })
+	}
+	if desired.Address != nil && !jsonEqual(old.Address, desired.Address) {
+		set(func(p *organizationPayload) { p.address = desired.Address })
+	}
+	if !ptrEqual(emptyAsNil(old.Website), emptyAsNil(desired.Website)) {
+		set(func(p *organizationPayload) { p.website = nullablePatch(emptyAsNil(desired.Website)) })
+	}
+	if !ptrEqual(emptyAsNil(old.LinkedIn), emptyAsNil(desired.LinkedIn)) {
+		set(func(p *organizationPayload) { p.linkedIn = nullablePatch(emptyAsNil(desired.LinkedIn)) })
+	}
+	if !ptrEqual(old.Industry, desired.Industry) {
+		set(func(p *organizationPayload) { p.industry = nullablePatch(desired.Industry) })
+	}
+	if !ptrEqual(old.AnnualRevenue, desired.AnnualRevenue) {
+		set(func(p *organizationPayload) { p.annualRevenue = nullablePatch(desired.AnnualRevenue) })
+	}
+	if !ptrEqual(old.EmployeeCount, desired.EmployeeCount) {
+		set(func(p *organizationPayload) { p.employeeCount = nullablePatch(desired.EmployeeCount) })
+	}
+	if !sameLabels(old.LabelIDs, desired.LabelIDs) {
+		set(func(p *organizationPayload) { p.labelIDs = slicePatch(desired.LabelIDs) })
+	}
+	if fields := customFieldsPatch(old.CustomFields, desired.CustomFields); fields != nil {
+		set(func(p *organizationPayload) { p.customFields = fields })
+	}
+	return opts
+}
+
+func ProductPatch(old, desired Product) []UpdateProductOption {
+	var opts []UpdateProductOption
+	set := func(apply func(*productPayload)) {
+		opts = append(opts, productFieldOption(apply))
+	}
+	if desired.Name != "" && desired.Name != old.Name {
+		set(func(p *productPayload) { p.name = &desired.Name })
+	}
+	if desired.Code != old.Code {
+		set(func(p *productPayload) { p.code = &desired.Code })
+	}
+	if desired.Description != old.Description {
+		set(func(p *productPayload) { p.description = &desired.Description })
+	}
+	if desired.Unit != old.Unit {
+		set(func(p *productPayload) { p.unit = &desired.Unit })
+	}
+	if desired.Tax != old.Tax {
+		set(func(p *productPayload) { p.tax = &desired.Tax })
+	}
+	if desired.Category != old.Category && desired.Category != 0 {
+		set(func(p *productPayload) { p.category = &desired.Category })
+	}
+	if desired.OwnerID != nil && !ptrEqual(old.OwnerID, desired.OwnerID) {
+		set(func(p *productPayload) { p.ownerID = desired.OwnerID })
+	}
+	if desired.IsLinkable != old.IsLinkable {
+		set(func(p *productPayload) { p.isLinkable = &desired.IsLinkable })
+	}
+	if desired.VisibleTo != 0 && desired.VisibleTo != old.VisibleTo {
+		set(func(p *productPayload) { p.visibleTo = &desired.VisibleTo })
+	}
+	if !jsonEqual(old.Prices, desired.Prices) {
+		set(func(p *productPayload) { p.prices = slicePatch(desired.Prices) })
+	}
+	if desired.BillingFrequency != "" && desired.BillingFrequency != old.BillingFrequency {
+		set(func(p *productPayload) { p.billingFrequency = &desired.BillingFrequency })
+	}
+	if !ptrEqual(old.BillingFrequencyCycles, desired.BillingFrequencyCycles) {
+		set(func(p *productPayload) { p.billingFrequencyCycles = nullablePatch(desired.BillingFrequencyCycles) })
+	}
+	if fields := customFieldsPatch(old.CustomFields, desired.CustomFields); fields != nil {
+		set(func(p *productPayload) { p.customFields = fields })
+	}
+	return opts
+}
+
+func ActivityPatch(old, desired Activity) []UpdateActivityOption {
+	var opts []UpdateActivityOption
+	set := func(apply func(*activityPayload)) {
+		opts = append(opts, activityFieldOption(apply))
+	}
+	if desired.Subject != "" && desired.Subject != old.Subject {
+		set(func(p *activityPayload) { p.subject = &desired.Subject })
+	}
+	if desired.Type != "" && desired.Type != old.Type {
+		set(func(p *activityPayload) { p.activityType = &desired.Type })
+	}
+	if desired.OwnerID != nil && !ptrEqual(old.OwnerID, desired.OwnerID) {
+		set(func(p *activityPayload) { p.ownerID = desired.OwnerID })
+	}
+	if !ptrEqual(old.DealID, desired.DealID) {
+		set(func(p *activityPayload) { p.dealID = nullablePatch(desired.DealID) })
+	}
+	if !ptrEqual(old.LeadID, desired.LeadID) {
+		set(func(p *activityPayload) { p.leadID = nullablePatch(desired.LeadID) })
+	}
+	if !ptrEqual(old.PersonID, desired.PersonID) {
+		set(func(p *activityPayload) { p.personID = nullablePatch(desired.PersonID) })
+	}
+	if !ptrEqual(old.OrgID, desired.OrgID) {
+		set(func(p *activityPayload) { p.orgID = nullablePatch(desired.OrgID) })
+	}
+	if !ptrEqual(old.ProjectID, desired.ProjectID) {
+		set(func(p *activityPayload) { p.projectID = nullablePatch(desired.ProjectID) })
+	}
+	if desired.DueDate != "" && desired.DueDate != old.DueDate {
+		set(func(p *activityPayload) { p.dueDate = &desired.DueDate })
+	}
+	if desired.DueTime != "" && desired.DueTime != old.DueTime {
+		set(func(p *activityPayload) { p.dueTime = &desired.DueTime })
+	}
+	if desired.Duration != "" && desired.Duration != old.Duration {
+		set(func(p *activityPayload) { p.duration = &desired.Duration })
+	}
+	if desired.Busy != old.Busy {
+		set(func(p *activityPayload) { p.busy = &desired.Busy })
+	}
+	if desired.Done != old.Done {
+		set(func(p *activityPayload) { p.done = &desired.Done })
+	}
+	if desired.Location != nil && !jsonEqual(old.Location, desired.Location) {
+		set(func(p *activityPayload) { p.location = desired.Location })
+	}
+	if !jsonEqual(old.Participants, desired.Participants) {
+		set(func(p *activityPayload) { p.participants = slicePatch(desired.Participants) })
+	}
+	if !jsonEqual(old.Attendees, desired.Attendees) {
+		set(func(p *activityPayload) { p.attendees = slicePatch(desired.Attendees) })
+	}
+	if desired.PublicDescription != old.PublicDescription {
+		set(func(p *activityPayload) { p.publicDescription = &desired.PublicDescription })
+	}
+	if desired.Priority != nil && !ptrEqual(old.Priority, desired.Priority) {
+		set(func(p *activityPayload) { p.priority = desired.Priority })
+	}
+	if desired.Note != old.Note {
+		set(func(p *activityPayload) { p.note = &desired.Note })
+	}
+	return opts
+}
+
+func ptrEqual[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func timeEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func emptyAsNil(value *string) *string {
+	if value == nil || *value == "" {
+		return nil
+	}
+	return value
+}
+
+func nullablePatch[T any](value *T) nullableValue[T] {
+	return nullableValue[T]{value: value, set: true}
+}
+
+// slicePatch always marks the slice as set so an empty desired slice is sent
+// as [] and clears the field.
+func slicePatch[T any](values []T) optionalSlice[T] {
+	return optionalSlice[T]{value: append(make([]T, 0, len(values)), values...), set: true}
+}
+
+func sameLabels(a, b []int) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// jsonEqual compares values by their JSON encoding, so numbers decoded as
+// float64 equal the ints a caller built the desired state with.
+func jsonEqual(a, b interface{}) bool {
+	left, errLeft := json.Marshal(a)
+	right, errRight := json.Marshal(b)
+	if errLeft != nil || errRight != nil {
+		return false
+	}
+	return bytes.Equal(normalizeEmptyJSON(left), normalizeEmptyJSON(right))
+}
+
+func normalizeEmptyJSON(data []byte) []byte {
+	if string(data) == "[]" || string(data) == "{}" {
+		return []byte("null")
+	}
+	return data
+}
+
+// customFieldsPatch returns the changed custom fields, with keys missing from
+// desired mapped to nil, or nil when nothing changed.
+func customFieldsPatch(old, desired map[string]interface{}) map[string]interface{} {
+	var patch map[string]interface{}
+	add := func(key string, value interface{}) {
+		if patch == nil {
+			patch = map[string]interface{}{}
+		}
+		patch[key] = value
+	}
+	for key, value := range desired {
+		if !jsonEqual(old[key], value) {
+			add(key, value)
+		}
+	}
+	for key, value := range old {
+		if _, ok := desired[key]; !ok && value != nil {
+			add(key, nil)
+		}
+	}
+	return patch
+}
@@ -0,0 +1,150 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+func TestDealPatch(t *testing.T) {
+	t.Parallel()
+
+	person := PersonID(5)
+	stage := StageID(2)
+	newStage := StageID(3)
+	probability := 40.0
+	closeDate := "2024-06-10"
+	closed := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	old := Deal{
+		ID:                1,
+		Title:             "Deal",
+		StageID:           &stage,
+		PersonID:          &person,
+		Probability:       &probability,
+		ExpectedCloseDate: &closeDate,
+		CloseTime:         &closed,
+		LabelIDs:          []int{1, 2},
+		CustomFields:      map[string]interface{}{"abc": float64(10), "gone": "x", "same": map[string]interface{}{"value": float64(1)}},
+		ActivitiesCount:   new(int),
+	}
+
+	if opts := DealPatch(old, old); opts != nil {
+		t.Fatalf("expected no options for an unchanged deal, got %d", len(opts))
+	}
+
+	desired := old
+	desired.StageID = &newStage
+	desired.PersonID = nil
+	desired.Probability = nil
+	desired.ExpectedCloseDate = nil
+	desired.CloseTime = nil
+	desired.LabelIDs = []int{2, 1}
+	desired.CustomFields = map[string]interface{}{"abc": 11, "same": map[string]interface{}{"value": 1}}
+	activities := 4
+	desired.ActivitiesCount = &activities
+
+	cfg := newUpdateDealOptions(DealPatch(old, desired))
+	got, err := json.Marshal(cfg.payload.toMap())
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	want := `{"close_time":null,"custom_fields":{"abc":11,"gone":null},"expected_close_date":null,"person_id":null,"probability":null,"stage_id":3}`
+	if string(got) != want {
+		t.Fatalf("unexpected patch:\n got %s\nwant %s", got, want)
+	}
+
+	desired.LabelIDs = nil
+	cfg = newUpdateDealOptions(DealPatch(old, desired))
+	if labels, ok := cfg.payload.toMap()["label_ids"].([]int); !ok || len(labels) != 0 {
+		t.Fatalf("expected labels to be cleared, got %#v", cfg.payload.toMap()["label_ids"])
+	}
+}
+
+func TestPersonAndOrganizationPatch(t *testing.T) {
+	t.Parallel()
+
+	org := OrganizationID(7)
+	oldPerson := Person{Name: "Jane", OrgID: &org, Emails: []LabeledValue{{Value: "jane@acme.test", Label: "work", Primary: true}}, JobTitle: "CEO"}
+	desiredPerson := oldPerson
+	desiredPerson.OrgID = nil
+	desiredPerson.Emails = nil
+	desiredPerson.JobTitle = ""
+	got, _ := json.Marshal(newUpdatePersonOptions(PersonPatch(oldPerson, desiredPerson)).payload.toMap())
+	if string(got) != `{"emails":[],"job_title":"","org_id":null}` {
+		t.Fatalf("unexpected person patch: %s", got)
+	}
+
+	website := "https://acme.test"
+	employees := 10
+	oldOrg := Organization{Name: "Acme", Website: &website, EmployeeCount: &employees}
+	desiredOrg := oldOrg
+	desiredOrg.Name = "Acme Inc"
+	desiredOrg.Website = nil
+	got, _ = json.Marshal(newUpdateOrganizationOptions(OrganizationPatch(oldOrg, desiredOrg)).payload.toMap())
+	if string(got) != `{"name":"Acme Inc","website":null}` {
+		t.Fatalf("unexpected organization patch: %s", got)
+	}
+}
+
+func TestProductAndActivityPatch(t *testing.T) {
+	t.Parallel()
+
+	cycles := 12
+	oldProduct := Product{Name: "Widget", Code: "W-1", BillingFrequencyCycles: &cycles, Prices: []ProductPrice{{Price: 10, Currency: "EUR"}}}
+	desiredProduct := oldProduct
+	desiredProduct.BillingFrequencyCycles = nil
+	desiredProduct.CustomFields = map[string]interface{}{"color": "red"}
+	got, _ := json.Marshal(newUpdateProductOptions(ProductPatch(oldProduct, desiredProduct)).payload.toMap())
+	if string(got) != `{"billing_frequency_cycles":null,"custom_fields":{"color":"red"}}` {
+		t.Fatalf("unexpected product patch: %s", got)
+	}
+
+	deal := DealID(3)
+	oldActivity := Activity{Subject: "Call", DealID: &deal, Done: false}
+	desiredActivity := oldActivity
+	desiredActivity.DealID = nil
+	desiredActivity.Done = true
+	got, _ = json.Marshal(newUpdateActivityOptions(ActivityPatch(oldActivity, desiredActivity)).payload.toMap())
+	if string(got) != `{"deal_id":null,"done":true}` {
+		t.Fatalf("unexpected activity patch: %s", got)
+	}
+}
+
+func TestDealsService_UpdateWithPatch(t *testing.T) {
+	t.Parallel()
+
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/deals/1" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var decoded map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&decoded)
+		encoded, _ := json.Marshal(decoded)
+		body = string(encoded)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"id":1}}`))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	value := 100.0
+	newValue := 125.5
+	org := OrganizationID(9)
+	old := Deal{ID: 1, Value: &value}
+	desired := Deal{ID: 1, Value: &newValue, OrgID: &org}
+	if _, err := client.Deals.Update(context.Background(), 1, DealPatch(old, desired)...); err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+	if body != `{"org_id":9,"value":125.5}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
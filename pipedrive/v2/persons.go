@@ -273,7 +273,7 @@ type getPersonPictureOptions struct {
 type personPayload struct {
 	name            *string
 	ownerID         *UserID
-	orgID           *OrganizationID
+	orgID           nullableValue[OrganizationID]
 	emails          optionalSlice[LabeledValue]
 	phones          optionalSlice[LabeledValue]
 	postalAddress   *PersonAddress
@@ -285,6 +285,8 @@ type personPayload struct {
 	visibleTo       *int
 	marketingStatus *PersonMarketingStatus
 	customFields    map[string]interface{}
+
+	contactNormalization *ContactNormalization
 }
 
 type personRequestOptions struct {
@@ -432,7 +434,15 @@ func WithPersonOwnerID(id UserID) PersonOption {
 
 func WithPersonOrgID(id OrganizationID) PersonOption {
 	return personFieldOption(func(payload *personPayload) {
-		payload.orgID = &id
+		payload.orgID.assign(id)
+	})
+}
+
+// ClearPersonOrgID sends an explicit JSON null organization, unlinking the
+// person from it.
+func ClearPersonOrgID() PersonOption {
+	return personFieldOption(func(payload *personPayload) {
+		payload.orgID.clear()
 	})
 }
 
@@ -896,6 +906,9 @@ func (s *PersonsService) ForEach(ctx context.Context, fn func(Person) error, opt
 
 func (s *PersonsService) Create(ctx context.Context, opts ...CreatePersonOption) (*Person, error) {
 	cfg := newCreatePersonOptions(opts)
+	if err := cfg.payload.normalizeContacts(); err != nil {
+		return nil, err
+	}
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap())
@@ -928,6 +941,9 @@ func (s *PersonsService) Update(ctx context.Context, id PersonID, opts ...Update
 		return nil, err
 	}
 	cfg := newUpdatePersonOptions(opts)
+	if err := cfg.payload.normalizeContacts(); err != nil {
+		return nil, err
+	}
 	ctx, editors := pipedrive.ApplyRequestOptions(ctx, cfg.requestOptions...)
 
 	body, err := json.Marshal(cfg.payload.toMap())
@@ -1257,8 +1273,12 @@ func (p personPayload) toMap() map[string]interface{} {
 	if p.ownerID != nil {
 		body["owner_id"] = int(*p.ownerID)
 	}
-	if p.orgID != nil {
-		body["org_id"] = int(*p.orgID)
+	if p.orgID.set {
+		if p.orgID.value == nil {
+			body["org_id"] = nil
+		} else {
+			body["org_id"] = int(*p.orgID.value)
+		}
 	}
 	if p.emails.set {
 		body["emails"] = p.emails.value
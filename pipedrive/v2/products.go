@@ -51,6 +51,8 @@ type ProductPrice struct {
 	Cost               *float64            `json:"cost,omitempty"`
 	DirectCost         *float64            `json:"direct_cost,omitempty"`
 	Notes              string              `json:"notes,omitempty"`
+
+	exact *exactAmounts
 }
 
 type Product struct {
@@ -372,6 +374,7 @@ type productPayload struct {
 	prices                 optionalSlice[ProductPrice]
 	billingFrequency       *BillingFrequency
 	billingFrequencyCycles nullableValue[int]
+	customFields           map[string]interface{}
 }
 
 type productVariationPayload struct {
@@ -666,6 +669,15 @@ func WithProductPrices(prices ...ProductPrice) ProductOption {
 	})
 }
 
+func WithProductCustomFieldsMap(fields map[string]interface{}) ProductOption {
+	return productFieldOption(func(payload *productPayload) {
+		if len(fields) == 0 {
+			return
+		}
+		payload.customFields = fields
+	})
+}
+
 func WithProductBillingFrequency(frequency BillingFrequency) ProductOption {
 	return productFieldOption(func(payload *productPayload) {
 		payload.billingFrequency = &frequency
@@ -1769,6 +1781,9 @@ func (p productPayload) toMap() map[string]interface{} {
 			body["billing_frequency_cycles"] = *p.billingFrequencyCycles.value
 		}
 	}
+	if p.customFields != nil {
+		body["custom_fields"] = p.customFields
+	}
 	return body
 }
 
@@ -0,0 +1,475 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SearchHit is a typed search result. The concrete type is one of
+// *DealSearchHit, *PersonSearchHit, *OrganizationSearchHit, *ProductSearchHit,
+// *LeadSearchHit, *ProjectSearchHit, *FileSearchHit, *MailAttachmentSearchHit
+// or *UnknownSearchHit; switch on Type() or on the concrete type.
+type SearchHit interface {
+	Type() ItemSearchType
+	Score() float64
+	// Highlights returns the matched custom field values and note snippets.
+	Highlights() SearchHighlights
+	isSearchHit()
+}
+
+// SearchHighlights holds the values Pipedrive reports as matching the search
+// term besides the item's own fields.
+type SearchHighlights struct {
+	CustomFields []string
+	Notes        []string
+}
+
+type SearchHitOwner struct {
+	ID UserID `json:"id"`
+}
+
+type SearchHitStage struct {
+	ID   StageID `json:"id"`
+	Name string  `json:"name,omitempty"`
+}
+
+type SearchHitPerson struct {
+	ID   PersonID `json:"id"`
+	Name string   `json:"name,omitempty"`
+}
+
+type SearchHitOrganization struct {
+	ID      OrganizationID `json:"id"`
+	Name    string         `json:"name,omitempty"`
+	Address string         `json:"address,omitempty"`
+}
+
+type SearchHitDeal struct {
+	ID    DealID `json:"id"`
+	Title string `json:"title,omitempty"`
+}
+
+type SearchHits struct {
+	Items        []SearchHit
+	RelatedItems []SearchHit
+}
+
+type DealSearchHit struct {
+	ResultScore  float64                `json:"-"`
+	ID           DealID                 `json:"id"`
+	Title        string                 `json:"title,omitempty"`
+	Value        float64                `json:"value,omitempty"`
+	Currency     string                 `json:"currency,omitempty"`
+	Status       string                 `json:"status,omitempty"`
+	VisibleTo    int                    `json:"visible_to,omitempty"`
+	Owner        *SearchHitOwner        `json:"owner,omitempty"`
+	Stage        *SearchHitStage        `json:"stage,omitempty"`
+	Person       *SearchHitPerson       `json:"person,omitempty"`
+	Organization *SearchHitOrganization `json:"organization,omitempty"`
+	CCEmail      string                 `json:"cc_email,omitempty"`
+	IsArchived   bool                   `json:"is_archived,omitempty"`
+	CustomFields []string               `json:"custom_fields,omitempty"`
+	Notes        []string               `json:"notes,omitempty"`
+	Raw          map[string]interface{} `json:"-"`
+}
+
+type PersonSearchHit struct {
+	ResultScore  float64                `json:"-"`
+	ID           PersonID               `json:"id"`
+	Name         string                 `json:"name,omitempty"`
+	Phones       []string               `json:"phones,omitempty"`
+	Emails       []string               `json:"emails,omitempty"`
+	VisibleTo    int                    `json:"visible_to,omitempty"`
+	Owner        *SearchHitOwner        `json:"owner,omitempty"`
+	Organization *SearchHitOrganization `json:"organization,omitempty"`
+	CustomFields []string               `json:"custom_fields,omitempty"`
+	Notes        []string               `json:"notes,omitempty"`
+	Raw          map[string]interface{} `json:"-"`
+}
+
+type OrganizationSearchHit struct {
+	ResultScore  float64                `json:"-"`
+	ID           OrganizationID         `json:"id"`
+	Name         string                 `json:"name,omitempty"`
+	Address      string                 `json:"address,omitempty"`
+	VisibleTo    int                    `json:"visible_to,omitempty"`
+	Owner        *SearchHitOwner        `json:"owner,omitempty"`
+	CustomFields []string               `json:"custom_fields,omitempty"`
+	Notes        []string               `json:"notes,omitempty"`
+	Raw          map[string]interface{} `json:"-"`
+}
+
+type ProductSearchHit struct {
+	ResultScore float64   `json:"-"`
+	ID          ProductID `json:"id"`
+	Name        string    `json:"name,omitempty"`
+	// Code is returned as a number or a string depending on the endpoint.
+	Code         string                 `json:"code,omitempty"`
+	VisibleTo    int                    `json:"visible_to,omitempty"`
+	Owner        *SearchHitOwner        `json:"owner,omitempty"`
+	CustomFields []string               `json:"custom_fields,omitempty"`
+	Raw          map[string]interface{} `json:"-"`
+}
+
+type LeadSearchHit struct {
+	ResultScore  float64                `json:"-"`
+	ID           LeadID                 `json:"id"`
+	Title        string                 `json:"title,omitempty"`
+	Value        float64                `json:"value,omitempty"`
+	Currency     string                 `json:"currency,omitempty"`
+	VisibleTo    int                    `json:"visible_to,omitempty"`
+	Owner        *SearchHitOwner        `json:"owner,omitempty"`
+	Person       *SearchHitPerson       `json:"person,omitempty"`
+	Organization *SearchHitOrganization `json:"organization,omitempty"`
+	Phones       []string               `json:"phones,omitempty"`
+	Emails       []string               `json:"emails,omitempty"`
+	IsArchived   bool                   `json:"is_archived,omitempty"`
+	CustomFields []string               `json:"custom_fields,omitempty"`
+	Notes        []string               `json:"notes,omitempty"`
+	Raw          map[string]interface{} `json:"-"`
+}
+
+type ProjectSearchHit struct {
+	ResultScore  float64                `json:"-"`
+	ID           ProjectID              `json:"id"`
+	Title        string                 `json:"title,omitempty"`
+	Status       *ProjectStatus         `json:"status,omitempty"`
+	Owner        *SearchHitOwner        `json:"owner,omitempty"`
+	BoardID      *ProjectBoardID        `json:"board_id,omitempty"`
+	Phase        *ProjectSearchPhase    `json:"phase,omitempty"`
+	Person       *SearchHitPerson       `json:"person,omitempty"`
+	Organization *SearchHitOrganization `json:"organization,omitempty"`
+	Deal         *SearchHitDeal         `json:"deal,omitempty"`
+	DealCount    int                    `json:"deal_count,omitempty"`
+	Description  string                 `json:"description,omitempty"`
+	EndDate      string                 `json:"end_date,omitempty"`
+	CustomFields []string               `json:"custom_fields,omitempty"`
+	Notes        []string               `json:"notes,omitempty"`
+	Raw          map[string]interface{} `json:"-"`
+}
+
+type FileSearchHit struct {
+	ResultScore  float64                `json:"-"`
+	ID           int64                  `json:"id"`
+	Name         string                 `json:"name,omitempty"`
+	URL          string                 `json:"url,omitempty"`
+	Deal         *SearchHitDeal         `json:"deal,omitempty"`
+	Person       *SearchHitPerson       `json:"person,omitempty"`
+	Organization *SearchHitOrganization `json:"organization,omitempty"`
+	Raw          map[string]interface{} `json:"-"`
+}
+
+type MailAttachmentSearchHit struct {
+	ResultScore  float64                `json:"-"`
+	ID           int64                  `json:"id"`
+	Name         string                 `json:"name,omitempty"`
+	URL          string                 `json:"url,omitempty"`
+	Deal         *SearchHitDeal         `json:"deal,omitempty"`
+	Person       *SearchHitPerson       `json:"person,omitempty"`
+	Organization *SearchHitOrganization `json:"organization,omitempty"`
+	Raw          map[string]interface{} `json:"-"`
+}
+
+// UnknownSearchHit carries hits of item types this package does not know yet.
+type UnknownSearchHit struct {
+	ItemType    ItemSearchType
+	ResultScore float64
+	Raw         map[string]interface{}
+}
+
+func (h *DealSearchHit) Type() ItemSearchType           { return ItemSearchTypeDeal }
+func (h *PersonSearchHit) Type() ItemSearchType         { return ItemSearchTypePerson }
+func (h *OrganizationSearchHit) Type() ItemSearchType   { return ItemSearchTypeOrganization }
+func (h *ProductSearchHit) Type() ItemSearchType        { return ItemSearchTypeProduct }
+func (h *LeadSearchHit) Type() ItemSearchType           { return ItemSearchTypeLead }
+func (h *ProjectSearchHit) Type() ItemSearchType        { return ItemSearchTypeProject }
+func (h *FileSearchHit) Type() ItemSearchType           { return ItemSearchTypeFile }
+func (h *MailAttachmentSearchHit) Type() ItemSearchType { return ItemSearchTypeMailAttachment }
+func (h *UnknownSearchHit) Type() ItemSearchType        { return h.ItemType }
+
+func (h *DealSearchHit) Score() float64           { return h.ResultScore }
+func (h *PersonSearchHit) Score() float64         { return h.ResultScore }
+func (h *OrganizationSearchHit) Score() float64   { return h.ResultScore }
+func (h *ProductSearchHit) Score() float64        { return h.ResultScore }
+func (h *LeadSearchHit) Score() float64           { return h.ResultScore }
+func (h *ProjectSearchHit) Score() float64        { return h.ResultScore }
+func (h *FileSearchHit) Score() float64           { return h.ResultScore }
+func (h *MailAttachmentSearchHit) Score() float64 { return h.ResultScore }
+func (h *UnknownSearchHit) Score() float64        { return h.ResultScore }
+
+func (h *DealSearchHit) Highlights() SearchHighlights {
+	return SearchHighlights{CustomFields: h.CustomFields, Notes: h.Notes}
+}
+
+func (h *PersonSearchHit) Highlights() SearchHighlights {
+	return SearchHighlights{CustomFields: h.CustomFields, Notes: h.Notes}
+}
+
+func (h *OrganizationSearchHit) Highlights() SearchHighlights {
+	return SearchHighlights{CustomFields: h.CustomFields, Notes: h.Notes}
+}
+
+func (h *ProductSearchHit) Highlights() SearchHighlights {
+	return SearchHighlights{CustomFields: h.CustomFields}
+}
+
+func (h *LeadSearchHit) Highlights() SearchHighlights {
+	return SearchHighlights{CustomFields: h.CustomFields, Notes: h.Notes}
+}
+
+func (h *ProjectSearchHit) Highlights() SearchHighlights {
+	return SearchHighlights{CustomFields: h.CustomFields, Notes: h.Notes}
+}
+
+func (h *FileSearchHit) Highlights() SearchHighlights           { return SearchHighlights{} }
+func (h *MailAttachmentSearchHit) Highlights() SearchHighlights { return SearchHighlights{} }
+func (h *UnknownSearchHit) Highlights() SearchHighlights        { return SearchHighlights{} }
+
+func (*DealSearchHit) isSearchHit()           {}
+func (*PersonSearchHit) isSearchHit()         {}
+func (*OrganizationSearchHit) isSearchHit()   {}
+func (*ProductSearchHit) isSearchHit()        {}
+func (*LeadSearchHit) isSearchHit()           {}
+func (*ProjectSearchHit) isSearchHit()        {}
+func (*FileSearchHit) isSearchHit()           {}
+func (*MailAttachmentSearchHit) isSearchHit() {}
+func (*UnknownSearchHit) isSearchHit()        {}
+
+func (h *ProductSearchHit) UnmarshalJSON(data []byte) error {
+	if h == nil {
+		return fmt.Errorf("v2.ProductSearchHit: UnmarshalJSON on nil receiver")
+	}
+	var aux struct {
+		ID           ProductID       `json:"id"`
+		Name         string          `json:"name"`
+		Code         json.RawMessage `json:"code"`
+		VisibleTo    int             `json:"visible_to"`
+		Owner        *SearchHitOwner `json:"owner"`
+		CustomFields []string        `json:"custom_fields"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("v2.ProductSearchHit: decode: %w", err)
+	}
+	code, err := stringOrNumber(aux.Code)
+	if err != nil {
+		return fmt.Errorf("v2.ProductSearchHit: invalid code value %q", string(aux.Code))
+	}
+	h.ID, h.Name, h.Code, h.VisibleTo, h.Owner, h.CustomFields = aux.ID, aux.Name, code, aux.VisibleTo, aux.Owner, aux.CustomFields
+	return nil
+}
+
+func stringOrNumber(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return "", err
+	}
+	return n.String(), nil
+}
+
+// DecodeSearchHit decodes a raw search item using its "type" discriminator.
+func DecodeSearchHit(score float64, item map[string]interface{}) (SearchHit, error) {
+	itemType, _ := item["type"].(string)
+	return decodeSearchHit(ItemSearchType(itemType), score, item)
+}
+
+func decodeSearchHit(itemType ItemSearchType, score float64, item map[string]interface{}) (SearchHit, error) {
+	if item == nil {
+		return nil, fmt.Errorf("missing search item")
+	}
+	var hit SearchHit
+	switch itemType {
+	case ItemSearchTypeDeal:
+		hit = &DealSearchHit{ResultScore: score, Raw: item}
+	case ItemSearchTypePerson:
+		hit = &PersonSearchHit{ResultScore: score, Raw: item}
+	case ItemSearchTypeOrganization:
+		hit = &OrganizationSearchHit{ResultScore: score, Raw: item}
+	case ItemSearchTypeProduct:
+		hit = &ProductSearchHit{ResultScore: score, Raw: item}
+	case ItemSearchTypeLead:
+		hit = &LeadSearchHit{ResultScore: score, Raw: item}
+	case ItemSearchTypeProject:
+		hit = &ProjectSearchHit{ResultScore: score, Raw: item}
+	case ItemSearchTypeFile:
+		hit = &FileSearchHit{ResultScore: score, Raw: item}
+	case ItemSearchTypeMailAttachment:
+		hit = &MailAttachmentSearchHit{ResultScore: score, Raw: item}
+	default:
+		return &UnknownSearchHit{ItemType: itemType, ResultScore: score, Raw: item}, nil
+	}
+
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s search item: %w", itemType, err)
+	}
+	if err := json.Unmarshal(raw, hit); err != nil {
+		return nil, fmt.Errorf("decode %s search item: %w", itemType, err)
+	}
+	return hit, nil
+}
+
+func (i ItemSearchItem) Hit() (SearchHit, error) {
+	return DecodeSearchHit(i.ResultScore, i.Item)
+}
+
+// Hits decodes both the matched items and, when requested with
+// WithItemSearchRelatedItems, the related items.
+func (r *ItemSearchResults) Hits() (*SearchHits, error) {
+	if r == nil {
+		return &SearchHits{}, nil
+	}
+	out := &SearchHits{}
+	for _, item := range r.Items {
+		hit, err := item.Hit()
+		if err != nil {
+			return nil, err
+		}
+		out.Items = append(out.Items, hit)
+	}
+	for _, item := range r.RelatedItems {
+		hit, err := item.Hit()
+		if err != nil {
+			return nil, err
+		}
+		out.RelatedItems = append(out.RelatedItems, hit)
+	}
+	return out, nil
+}
+
+func (i DealSearchItem) Hit() (*DealSearchHit, error) {
+	hit, err := decodeSearchHit(ItemSearchTypeDeal, i.ResultScore, i.Item)
+	if err != nil {
+		return nil, err
+	}
+	return hit.(*DealSearchHit), nil
+}
+
+func (r *DealSearchResults) Hits() ([]*DealSearchHit, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return collectSearchHits(r.Items, DealSearchItem.Hit)
+}
+
+func (i PersonSearchItem) Hit() (*PersonSearchHit, error) {
+	hit, err := decodeSearchHit(ItemSearchTypePerson, i.ResultScore, i.Item)
+	if err != nil {
+		return nil, err
+	}
+	return hit.(*PersonSearchHit), nil
+}
+
+func (r *PersonSearchResults) Hits() ([]*PersonSearchHit, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return collectSearchHits(r.Items, PersonSearchItem.Hit)
+}
+
+func (i OrganizationSearchItem) Hit() (*OrganizationSearchHit, error) {
+	hit, err := decodeSearchHit(ItemSearchTypeOrganization, i.ResultScore, i.Item)
+	if err != nil {
+		return nil, err
+	}
+	return hit.(*OrganizationSearchHit), nil
+}
+
+func (r *OrganizationSearchResults) Hits() ([]*OrganizationSearchHit, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return collectSearchHits(r.Items, OrganizationSearchItem.Hit)
+}
+
+func (i ProductSearchItem) Hit() (*ProductSearchHit, error) {
+	hit, err := decodeSearchHit(ItemSearchTypeProduct, i.ResultScore, i.Item)
+	if err != nil {
+		return nil, err
+	}
+	return hit.(*ProductSearchHit), nil
+}
+
+func (r *ProductSearchResults) Hits() ([]*ProductSearchHit, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return collectSearchHits(r.Items, ProductSearchItem.Hit)
+}
+
+func (i LeadSearchItem) Hit() (*LeadSearchHit, error) {
+	hit, err := decodeSearchHit(ItemSearchTypeLead, i.ResultScore, i.Item)
+	if err != nil {
+		return nil, err
+	}
+	return hit.(*LeadSearchHit), nil
+}
+
+func (r *LeadSearchResults) Hits() ([]*LeadSearchHit, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return collectSearchHits(r.Items, LeadSearchItem.Hit)
+}
+
+// Hit converts a project search result to the shared SearchHit form.
+func (r ProjectSearchResult) Hit() *ProjectSearchHit {
+	item := r.Item
+	hit := &ProjectSearchHit{
+		ResultScore:  r.ResultScore,
+		ID:           item.ID,
+		Title:        item.Title,
+		Status:       item.Status,
+		BoardID:      item.BoardID,
+		Phase:        item.Phase,
+		DealCount:    item.DealCount,
+		CustomFields: item.CustomFields,
+		Notes:        item.Notes,
+	}
+	if item.Owner != nil && item.Owner.ID != nil {
+		hit.Owner = &SearchHitOwner{ID: *item.Owner.ID}
+	}
+	if item.Person != nil {
+		hit.Person = &SearchHitPerson{ID: item.Person.ID, Name: derefString(item.Person.Name)}
+	}
+	if item.Organization != nil {
+		hit.Organization = &SearchHitOrganization{
+			ID:      item.Organization.ID,
+			Name:    derefString(item.Organization.Name),
+			Address: derefString(item.Organization.Address),
+		}
+	}
+	if item.Deal != nil {
+		hit.Deal = &SearchHitDeal{ID: item.Deal.ID, Title: derefString(item.Deal.Title)}
+	}
+	hit.Description = derefString(item.Description)
+	hit.EndDate = derefString(item.EndDate)
+	return hit
+}
+
+func collectSearchHits[I any, H any](items []I, decode func(I) (H, error)) ([]H, error) {
+	out := make([]H, 0, len(items))
+	for _, item := range items {
+		hit, err := decode(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, hit)
+	}
+	return out, nil
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
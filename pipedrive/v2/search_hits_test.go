@@ -0,0 +1,116 @@
+package v2
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testItemSearchData = `{
+	"items":[
+		{"result_score":1.2,"item":{"id":42,"type":"deal","title":"Sample Deal","value":53883,"currency":"USD","status":"open","visible_to":3,
+			"owner":{"id":69},"stage":{"id":3,"name":"Demo Scheduled"},"person":{"id":6,"name":"Sample Person"},
+			"organization":{"id":9,"name":"Sample Organization","address":"Dabas, Hungary"},"custom_fields":["Sample text"],"notes":["Sample note"],"is_archived":false}},
+		{"result_score":0.3,"item":{"id":6,"type":"person","name":"Sample Person","phones":["555123123"],"emails":["primary@email.com"],"owner":{"id":69},"organization":{"id":9,"name":"Sample Organization"}}},
+		{"result_score":0.2,"item":{"id":9,"type":"organization","name":"Sample Organization","address":"Dabas, Hungary","custom_fields":[],"notes":[]}},
+		{"result_score":0.1,"item":{"id":1,"type":"product","name":"Sample Product","code":123,"owner":{"id":69}}},
+		{"result_score":0.09,"item":{"id":"adf21080-0e10-11eb-879b-05d71fb426ec","type":"lead","title":"Lead","emails":["a@b.c"]}},
+		{"result_score":0.08,"item":{"id":5,"type":"project","title":"Rollout","deal":{"id":42,"title":"Sample Deal"}}},
+		{"result_score":0.01,"item":{"id":3,"type":"file","name":"file.txt","url":"/files/3/download","deal":{"id":42,"title":"Sample Deal"}}},
+		{"result_score":0.01,"item":{"id":4,"type":"mail_attachment","name":"mail.txt","url":"/files/4/download"}},
+		{"result_score":0.005,"item":{"id":7,"type":"board","name":"Future type"}}
+	],
+	"related_items":[
+		{"result_score":0,"item":{"id":2,"type":"deal","title":"Other deal","person":{"id":1,"name":"Sample Person"}}}
+	]
+}`
+
+func TestItemSearchResults_Hits(t *testing.T) {
+	t.Parallel()
+
+	var results ItemSearchResults
+	if err := json.Unmarshal([]byte(testItemSearchData), &results); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	hits, err := results.Hits()
+	if err != nil {
+		t.Fatalf("Hits error: %v", err)
+	}
+	if len(hits.Items) != 9 || len(hits.RelatedItems) != 1 {
+		t.Fatalf("unexpected hit counts: %d, %d", len(hits.Items), len(hits.RelatedItems))
+	}
+
+	want := []ItemSearchType{
+		ItemSearchTypeDeal, ItemSearchTypePerson, ItemSearchTypeOrganization, ItemSearchTypeProduct,
+		ItemSearchTypeLead, ItemSearchTypeProject, ItemSearchTypeFile, ItemSearchTypeMailAttachment, "board",
+	}
+	for i, hit := range hits.Items {
+		if hit.Type() != want[i] {
+			t.Fatalf("hit %d: got type %q, want %q", i, hit.Type(), want[i])
+		}
+	}
+
+	deal, ok := hits.Items[0].(*DealSearchHit)
+	if !ok {
+		t.Fatalf("unexpected deal hit: %T", hits.Items[0])
+	}
+	if deal.ID != 42 || deal.Score() != 1.2 || deal.Stage.Name != "Demo Scheduled" || deal.Organization.Address != "Dabas, Hungary" || deal.Owner.ID != 69 {
+		t.Fatalf("unexpected deal hit: %#v", deal)
+	}
+	if h := deal.Highlights(); len(h.CustomFields) != 1 || h.Notes[0] != "Sample note" {
+		t.Fatalf("unexpected highlights: %#v", h)
+	}
+	if person := hits.Items[1].(*PersonSearchHit); person.Emails[0] != "primary@email.com" || person.Organization.ID != 9 {
+		t.Fatalf("unexpected person hit: %#v", person)
+	}
+	if product := hits.Items[3].(*ProductSearchHit); product.Code != "123" || product.Score() != 0.1 || product.Raw["name"] != "Sample Product" {
+		t.Fatalf("unexpected product hit: %#v", product)
+	}
+	if lead := hits.Items[4].(*LeadSearchHit); lead.ID != "adf21080-0e10-11eb-879b-05d71fb426ec" {
+		t.Fatalf("unexpected lead hit: %#v", lead)
+	}
+	if file := hits.Items[6].(*FileSearchHit); file.URL != "/files/3/download" || file.Deal.ID != 42 {
+		t.Fatalf("unexpected file hit: %#v", file)
+	}
+	if unknown := hits.Items[8].(*UnknownSearchHit); unknown.Raw["name"] != "Future type" {
+		t.Fatalf("unexpected unknown hit: %#v", unknown)
+	}
+	if related := hits.RelatedItems[0].(*DealSearchHit); related.ID != 2 || related.Person.ID != 1 {
+		t.Fatalf("unexpected related hit: %#v", related)
+	}
+}
+
+func TestEntitySearchResults_Hits(t *testing.T) {
+	t.Parallel()
+
+	deals := &DealSearchResults{Items: []DealSearchItem{{ResultScore: 0.5, Item: map[string]interface{}{"id": 1, "title": "Deal"}}}}
+	dealHits, err := deals.Hits()
+	if err != nil || len(dealHits) != 1 || dealHits[0].Title != "Deal" || dealHits[0].Score() != 0.5 {
+		t.Fatalf("unexpected deal hits: %#v, %v", dealHits, err)
+	}
+
+	products := &ProductSearchResults{Items: []ProductSearchItem{{Item: map[string]interface{}{"id": 1, "code": "P-1"}}}}
+	productHits, err := products.Hits()
+	if err != nil || productHits[0].Code != "P-1" {
+		t.Fatalf("unexpected product hits: %#v, %v", productHits, err)
+	}
+
+	bad := &PersonSearchResults{Items: []PersonSearchItem{{Item: map[string]interface{}{"id": "not-a-number"}}}}
+	if _, err := bad.Hits(); err == nil {
+		t.Fatalf("expected decode error")
+	}
+
+	name, title := "Jane", "Deal"
+	project := ProjectSearchResult{ResultScore: 0.7, Item: ProjectSearchItem{
+		ID:     3,
+		Title:  "Rollout",
+		Person: &ProjectSearchPerson{ID: 4, Name: &name},
+		Deal:   &ProjectSearchDeal{ID: 5, Title: &title},
+	}}
+	var hit SearchHit = project.Hit()
+	if hit.Type() != ItemSearchTypeProject || hit.Score() != 0.7 {
+		t.Fatalf("unexpected project hit: %#v", hit)
+	}
+	if p := hit.(*ProjectSearchHit); p.Person.Name != "Jane" || p.Deal.Title != "Deal" {
+		t.Fatalf("unexpected project hit: %#v", p)
+	}
+}
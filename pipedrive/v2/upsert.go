@@ -0,0 +1,567 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+const upsertSearchPageSize = 100
+
+type UpsertMatchField string
+
+const (
+	UpsertMatchEmail       UpsertMatchField = "email"
+	UpsertMatchPhone       UpsertMatchField = "phone"
+	UpsertMatchName        UpsertMatchField = "name"
+	UpsertMatchTitle       UpsertMatchField = "title"
+	UpsertMatchCode        UpsertMatchField = "code"
+	UpsertMatchCustomField UpsertMatchField = "custom_field"
+)
+
+// UpsertConflict decides what Upsert does when more than one record matches.
+type UpsertConflict string
+
+const (
+	// UpsertConflictFail returns an *UpsertConflictError and changes nothing.
+	// It is the default.
+	UpsertConflictFail UpsertConflict = "fail"
+	// UpsertConflictUpdateBest updates the match with the highest search
+	// score, preferring the lowest ID on ties.
+	UpsertConflictUpdateBest UpsertConflict = "update_best"
+	// UpsertConflictUpdateOldest updates the match with the lowest ID.
+	UpsertConflictUpdateOldest UpsertConflict = "update_oldest"
+	// UpsertConflictCreate ignores the matches and creates a new record.
+	UpsertConflictCreate UpsertConflict = "create"
+)
+
+// UpsertMatch identifies the existing record an Upsert call should update.
+type UpsertMatch struct {
+	Field UpsertMatchField
+	// FieldKey is the custom field key searched when Field is
+	// UpsertMatchCustomField.
+	FieldKey string
+	Value    string
+	Conflict UpsertConflict
+}
+
+func UpsertByEmail(email string) UpsertMatch {
+	return UpsertMatch{Field: UpsertMatchEmail, Value: email}
+}
+
+func UpsertByPhone(phone string) UpsertMatch {
+	return UpsertMatch{Field: UpsertMatchPhone, Value: phone}
+}
+
+func UpsertByName(name string) UpsertMatch {
+	return UpsertMatch{Field: UpsertMatchName, Value: name}
+}
+
+func UpsertByTitle(title string) UpsertMatch {
+	return UpsertMatch{Field: UpsertMatchTitle, Value: title}
+}
+
+func UpsertByCode(code string) UpsertMatch {
+	return UpsertMatch{Field: UpsertMatchCode, Value: code}
+}
+
+// UpsertByExternalID matches records whose custom field fieldKey holds value
+// exactly, for custom fields that store an ID from another system.
+func UpsertByExternalID(fieldKey, value string) UpsertMatch {
+	return UpsertMatch{Field: UpsertMatchCustomField, FieldKey: fieldKey, Value: value}
+}
+
+// OnConflict returns a copy of the match using the given conflict behaviour.
+func (m UpsertMatch) OnConflict(conflict UpsertConflict) UpsertMatch {
+	m.Conflict = conflict
+	return m
+}
+
+// UpsertResult reports the record written by Upsert and every ID that matched.
+type UpsertResult[T any, ID comparable] struct {
+	Record     *T
+	Created    bool
+	MatchedIDs []ID
+}
+
+// UpsertConflictError is returned when several records match and the conflict
+// behaviour is UpsertConflictFail.
+type UpsertConflictError struct {
+	Entity ItemSearchEntityType
+	Match  UpsertMatch
+	IDs    []int64
+}
+
+func (e *UpsertConflictError) Error() string {
+	if e == nil {
+		return "upsert matched more than one record"
+	}
+	field := string(e.Match.Field)
+	if e.Match.Field == UpsertMatchCustomField {
+		field = e.Match.FieldKey
+	}
+	return fmt.Sprintf("upsert %s: %d records match %s %q: %v", e.Entity, len(e.IDs), field, e.Match.Value, e.IDs)
+}
+
+type upsertCandidate struct {
+	id    int64
+	score float64
+}
+
+// Upsert updates the person identified by match, or creates one when nothing
+// matches. The options are applied to whichever call is made; request options
+// are also used for the lookup.
+func (s *PersonsService) Upsert(ctx context.Context, match UpsertMatch, opts ...PersonOption) (*UpsertResult[Person, PersonID], error) {
+	if err := validateUpsertMatch(ItemSearchEntityTypePerson, match, UpsertMatchEmail, UpsertMatchPhone, UpsertMatchName); err != nil {
+		return nil, err
+	}
+	var cfg createPersonOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt.applyCreatePerson(&cfg)
+		}
+	}
+
+	var candidates []upsertCandidate
+	var err error
+	if match.Field == UpsertMatchCustomField {
+		candidates, err = s.client.ItemSearch.upsertCandidates(ctx, ItemSearchEntityTypePerson, match, cfg.requestOptions)
+	} else {
+		candidates, err = collectUpsertCandidates(func(cursor *string) ([]upsertCandidate, *string, error) {
+			searchOpts := []SearchPersonsOption{
+				WithPersonSearchFields(PersonSearchField(match.Field)),
+				WithPersonSearchExactMatch(true),
+				WithPersonSearchPageSize(upsertSearchPageSize),
+				WithPersonRequestOptions(cfg.requestOptions...),
+			}
+			if cursor != nil {
+				searchOpts = append(searchOpts, WithPersonSearchCursor(*cursor))
+			}
+			results, next, err := s.Search(ctx, match.Value, searchOpts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits, err := results.Hits()
+			if err != nil {
+				return nil, nil, err
+			}
+			var page []upsertCandidate
+			for _, hit := range hits {
+				if personMatches(hit, match) {
+					page = append(page, upsertCandidate{id: int64(hit.ID), score: hit.ResultScore})
+				}
+			}
+			return page, next, nil
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, found, matched, err := chooseUpsertCandidate(ItemSearchEntityTypePerson, match, candidates)
+	if err != nil {
+		return nil, err
+	}
+	result := &UpsertResult[Person, PersonID]{MatchedIDs: convertUpsertIDs[PersonID](matched)}
+	if found {
+		updateOpts := make([]UpdatePersonOption, 0, len(opts))
+		for _, opt := range opts {
+			updateOpts = append(updateOpts, opt)
+		}
+		result.Record, err = s.Update(ctx, PersonID(id), updateOpts...)
+	} else {
+		createOpts := make([]CreatePersonOption, 0, len(opts))
+		for _, opt := range opts {
+			createOpts = append(createOpts, opt)
+		}
+		result.Record, err = s.Create(ctx, createOpts...)
+		result.Created = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Upsert updates the organization identified by match, or creates one when
+// nothing matches.
+func (s *OrganizationsService) Upsert(ctx context.Context, match UpsertMatch, opts ...OrganizationOption) (*UpsertResult[Organization, OrganizationID], error) {
+	if err := validateUpsertMatch(ItemSearchEntityTypeOrganization, match, UpsertMatchName); err != nil {
+		return nil, err
+	}
+	var cfg createOrganizationOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt.applyCreateOrganization(&cfg)
+		}
+	}
+
+	var candidates []upsertCandidate
+	var err error
+	if match.Field == UpsertMatchCustomField {
+		candidates, err = s.client.ItemSearch.upsertCandidates(ctx, ItemSearchEntityTypeOrganization, match, cfg.requestOptions)
+	} else {
+		candidates, err = collectUpsertCandidates(func(cursor *string) ([]upsertCandidate, *string, error) {
+			searchOpts := []SearchOrganizationsOption{
+				WithOrganizationSearchFields(OrganizationSearchField(match.Field)),
+				WithOrganizationSearchExactMatch(true),
+				WithOrganizationSearchPageSize(upsertSearchPageSize),
+				WithOrganizationRequestOptions(cfg.requestOptions...),
+			}
+			if cursor != nil {
+				searchOpts = append(searchOpts, WithOrganizationSearchCursor(*cursor))
+			}
+			results, next, err := s.Search(ctx, match.Value, searchOpts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits, err := results.Hits()
+			if err != nil {
+				return nil, nil, err
+			}
+			var page []upsertCandidate
+			for _, hit := range hits {
+				if sameUpsertText(hit.Name, match.Value) {
+					page = append(page, upsertCandidate{id: int64(hit.ID), score: hit.ResultScore})
+				}
+			}
+			return page, next, nil
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, found, matched, err := chooseUpsertCandidate(ItemSearchEntityTypeOrganization, match, candidates)
+	if err != nil {
+		return nil, err
+	}
+	result := &UpsertResult[Organization, OrganizationID]{MatchedIDs: convertUpsertIDs[OrganizationID](matched)}
+	if found {
+		updateOpts := make([]UpdateOrganizationOption, 0, len(opts))
+		for _, opt := range opts {
+			updateOpts = append(updateOpts, opt)
+		}
+		result.Record, err = s.Update(ctx, OrganizationID(id), updateOpts...)
+	} else {
+		createOpts := make([]CreateOrganizationOption, 0, len(opts))
+		for _, opt := range opts {
+			createOpts = append(createOpts, opt)
+		}
+		result.Record, err = s.Create(ctx, createOpts...)
+		result.Created = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Upsert updates the deal identified by match, or creates one when nothing
+// matches.
+func (s *DealsService) Upsert(ctx context.Context, match UpsertMatch, opts ...DealOption) (*UpsertResult[Deal, DealID], error) {
+	if err := validateUpsertMatch(ItemSearchEntityTypeDeal, match, UpsertMatchTitle); err != nil {
+		return nil, err
+	}
+	var cfg createDealOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt.applyCreateDeal(&cfg)
+		}
+	}
+
+	var candidates []upsertCandidate
+	var err error
+	if match.Field == UpsertMatchCustomField {
+		candidates, err = s.client.ItemSearch.upsertCandidates(ctx, ItemSearchEntityTypeDeal, match, cfg.requestOptions)
+	} else {
+		candidates, err = collectUpsertCandidates(func(cursor *string) ([]upsertCandidate, *string, error) {
+			searchOpts := []SearchDealsOption{
+				WithDealSearchFields(DealSearchFieldTitle),
+				WithDealSearchExactMatch(true),
+				WithDealSearchPageSize(upsertSearchPageSize),
+				WithDealRequestOptions(cfg.requestOptions...),
+			}
+			if cursor != nil {
+				searchOpts = append(searchOpts, WithDealSearchCursor(*cursor))
+			}
+			results, next, err := s.Search(ctx, match.Value, searchOpts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits, err := results.Hits()
+			if err != nil {
+				return nil, nil, err
+			}
+			var page []upsertCandidate
+			for _, hit := range hits {
+				if sameUpsertText(hit.Title, match.Value) {
+					page = append(page, upsertCandidate{id: int64(hit.ID), score: hit.ResultScore})
+				}
+			}
+			return page, next, nil
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, found, matched, err := chooseUpsertCandidate(ItemSearchEntityTypeDeal, match, candidates)
+	if err != nil {
+		return nil, err
+	}
+	result := &UpsertResult[Deal, DealID]{MatchedIDs: convertUpsertIDs[DealID](matched)}
+	if found {
+		updateOpts := make([]UpdateDealOption, 0, len(opts))
+		for _, opt := range opts {
+			updateOpts = append(updateOpts, opt)
+		}
+		result.Record, err = s.Update(ctx, DealID(id), updateOpts...)
+	} else {
+		createOpts := make([]CreateDealOption, 0, len(opts))
+		for _, opt := range opts {
+			createOpts = append(createOpts, opt)
+		}
+		result.Record, err = s.Create(ctx, createOpts...)
+		result.Created = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Upsert updates the product identified by match, or creates one when nothing
+// matches.
+func (s *ProductsService) Upsert(ctx context.Context, match UpsertMatch, opts ...ProductOption) (*UpsertResult[Product, ProductID], error) {
+	if err := validateUpsertMatch(ItemSearchEntityTypeProduct, match, UpsertMatchCode, UpsertMatchName); err != nil {
+		return nil, err
+	}
+	var cfg createProductOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt.applyCreateProduct(&cfg)
+		}
+	}
+
+	var candidates []upsertCandidate
+	var err error
+	if match.Field == UpsertMatchCustomField {
+		candidates, err = s.client.ItemSearch.upsertCandidates(ctx, ItemSearchEntityTypeProduct, match, cfg.requestOptions)
+	} else {
+		candidates, err = collectUpsertCandidates(func(cursor *string) ([]upsertCandidate, *string, error) {
+			searchOpts := []SearchProductsOption{
+				WithProductSearchFields(ProductSearchField(match.Field)),
+				WithProductSearchExactMatch(true),
+				WithProductSearchPageSize(upsertSearchPageSize),
+				WithProductRequestOptions(cfg.requestOptions...),
+			}
+			if cursor != nil {
+				searchOpts = append(searchOpts, WithProductSearchCursor(*cursor))
+			}
+			results, next, err := s.Search(ctx, match.Value, searchOpts...)
+			if err != nil {
+				return nil, nil, err
+			}
+			hits, err := results.Hits()
+			if err != nil {
+				return nil, nil, err
+			}
+			var page []upsertCandidate
+			for _, hit := range hits {
+				ok := sameUpsertText(hit.Name, match.Value)
+				if match.Field == UpsertMatchCode {
+					// Product codes are identifiers; compare them verbatim.
+					ok = strings.TrimSpace(hit.Code) == strings.TrimSpace(match.Value)
+				}
+				if ok {
+					page = append(page, upsertCandidate{id: int64(hit.ID), score: hit.ResultScore})
+				}
+			}
+			return page, next, nil
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, found, matched, err := chooseUpsertCandidate(ItemSearchEntityTypeProduct, match, candidates)
+	if err != nil {
+		return nil, err
+	}
+	result := &UpsertResult[Product, ProductID]{MatchedIDs: convertUpsertIDs[ProductID](matched)}
+	if found {
+		updateOpts := make([]UpdateProductOption, 0, len(opts))
+		for _, opt := range opts {
+			updateOpts = append(updateOpts, opt)
+		}
+		result.Record, err = s.Update(ctx, ProductID(id), updateOpts...)
+	} else {
+		createOpts := make([]CreateProductOption, 0, len(opts))
+		for _, opt := range opts {
+			createOpts = append(createOpts, opt)
+		}
+		result.Record, err = s.Create(ctx, createOpts...)
+		result.Created = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// upsertCandidates looks up records by a custom field. The field search
+// endpoint already filters on the exact value, so every returned item counts.
+func (s *ItemSearchService) upsertCandidates(ctx context.Context, entity ItemSearchEntityType, match UpsertMatch, requestOptions []pipedrive.RequestOption) ([]upsertCandidate, error) {
+	return collectUpsertCandidates(func(cursor *string) ([]upsertCandidate, *string, error) {
+		searchOpts := []SearchItemsByFieldOption{
+			WithItemSearchMatch(ItemSearchMatchExact),
+			WithItemSearchByFieldPageSize(upsertSearchPageSize),
+			WithItemSearchRequestOptions(requestOptions...),
+		}
+		if cursor != nil {
+			searchOpts = append(searchOpts, WithItemSearchByFieldCursor(*cursor))
+		}
+		items, next, err := s.SearchByField(ctx, match.Value, entity, match.FieldKey, searchOpts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		page := make([]upsertCandidate, 0, len(items))
+		for _, item := range items {
+			id, ok := item.Item["id"].(float64)
+			if !ok {
+				return nil, nil, fmt.Errorf("upsert %s: search result without numeric id", entity)
+			}
+			page = append(page, upsertCandidate{id: int64(id), score: item.ResultScore})
+		}
+		return page, next, nil
+	})
+}
+
+func collectUpsertCandidates(fetch func(cursor *string) ([]upsertCandidate, *string, error)) ([]upsertCandidate, error) {
+	var (
+		all    []upsertCandidate
+		cursor *string
+		seen   = map[int64]bool{}
+	)
+	for {
+		page, next, err := fetch(cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range page {
+			if !seen[c.id] {
+				seen[c.id] = true
+				all = append(all, c)
+			}
+		}
+		if next == nil || *next == "" {
+			return all, nil
+		}
+		cursor = next
+	}
+}
+
+func validateUpsertMatch(entity ItemSearchEntityType, match UpsertMatch, fields ...UpsertMatchField) error {
+	if strings.TrimSpace(match.Value) == "" {
+		return fmt.Errorf("upsert %s: match value is required", entity)
+	}
+	switch match.Conflict {
+	case "", UpsertConflictFail, UpsertConflictUpdateBest, UpsertConflictUpdateOldest, UpsertConflictCreate:
+	default:
+		return fmt.Errorf("upsert %s: unknown conflict behaviour %q", entity, match.Conflict)
+	}
+	if match.Field == UpsertMatchCustomField {
+		if match.FieldKey == "" {
+			return fmt.Errorf("upsert %s: custom field key is required", entity)
+		}
+		return nil
+	}
+	for _, field := range fields {
+		if match.Field == field {
+			return nil
+		}
+	}
+	return fmt.Errorf("upsert %s: cannot match by %q", entity, match.Field)
+}
+
+// chooseUpsertCandidate applies the conflict behaviour and returns the ID to
+// update, whether one was chosen, and all matching IDs in ascending order.
+func chooseUpsertCandidate(entity ItemSearchEntityType, match UpsertMatch, candidates []upsertCandidate) (int64, bool, []int64, error) {
+	ids := make([]int64, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	switch {
+	case len(candidates) == 0:
+		return 0, false, ids, nil
+	case len(candidates) == 1:
+		return candidates[0].id, true, ids, nil
+	}
+	switch match.Conflict {
+	case UpsertConflictUpdateBest:
+		best := candidates[0]
+		for _, c := range candidates[1:] {
+			if c.score > best.score || (c.score == best.score && c.id < best.id) {
+				best = c
+			}
+		}
+		return best.id, true, ids, nil
+	case UpsertConflictUpdateOldest:
+		return ids[0], true, ids, nil
+	case UpsertConflictCreate:
+		return 0, false, ids, nil
+	default:
+		return 0, false, ids, &UpsertConflictError{Entity: entity, Match: match, IDs: ids}
+	}
+}
+
+func convertUpsertIDs[ID ~int64](ids []int64) []ID {
+	out := make([]ID, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, ID(id))
+	}
+	return out
+}
+
+func personMatches(hit *PersonSearchHit, match UpsertMatch) bool {
+	switch match.Field {
+	case UpsertMatchEmail:
+		for _, email := range hit.Emails {
+			if strings.EqualFold(strings.TrimSpace(email), strings.TrimSpace(match.Value)) {
+				return true
+			}
+		}
+	case UpsertMatchPhone:
+		want := upsertPhoneDigits(match.Value)
+		for _, phone := range hit.Phones {
+			if want != "" && upsertPhoneDigits(phone) == want {
+				return true
+			}
+		}
+	case UpsertMatchName:
+		return sameUpsertText(hit.Name, match.Value)
+	}
+	return false
+}
+
+// sameUpsertText compares names case-insensitively with runs of whitespace
+// collapsed, since exact search already tolerates both.
+func sameUpsertText(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+func upsertPhoneDigits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+}
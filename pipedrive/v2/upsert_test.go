@@ -0,0 +1,158 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+)
+
+func newUpsertTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	client, err := NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	return client
+}
+
+func TestPersonsService_Upsert(t *testing.T) {
+	t.Parallel()
+
+	var writes []string
+	client := newUpsertTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/persons/search":
+			q := r.URL.Query()
+			if q.Get("fields") != "email" || q.Get("exact_match") != "true" {
+				t.Errorf("unexpected search query: %s", r.URL.RawQuery)
+			}
+			items := `[]`
+			switch q.Get("term") {
+			case "jane@acme.test":
+				// Person 8 only mentions the address in a longer one and must not match.
+				items = `[{"result_score":0.9,"item":{"id":7,"emails":["Jane@Acme.test"]}},{"result_score":0.8,"item":{"id":8,"emails":["mary.jane@acme.test.io"]}}]`
+			case "dup@acme.test":
+				items = `[{"result_score":0.4,"item":{"id":12,"emails":["dup@acme.test"]}},{"result_score":0.9,"item":{"id":11,"emails":["dup@acme.test"]}}]`
+			}
+			_, _ = w.Write([]byte(`{"data":{"items":` + items + `},"additional_data":{"next_cursor":null}}`))
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/persons/"):
+			body, _ := io.ReadAll(r.Body)
+			writes = append(writes, "PATCH "+r.URL.Path+" "+string(body))
+			_, _ = w.Write([]byte(`{"data":{"id":` + strings.TrimPrefix(r.URL.Path, "/persons/") + `,"name":"Jane"}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/persons":
+			body, _ := io.ReadAll(r.Body)
+			writes = append(writes, "POST "+r.URL.Path+" "+string(body))
+			_, _ = w.Write([]byte(`{"data":{"id":99,"name":"Jane"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+
+	res, err := client.Persons.Upsert(ctx, UpsertByEmail("jane@acme.test"), WithPersonName("Jane"))
+	if err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+	if res.Created || res.Record.ID != 7 || len(res.MatchedIDs) != 1 || res.MatchedIDs[0] != 7 {
+		t.Fatalf("unexpected update result: %#v", res)
+	}
+
+	res, err = client.Persons.Upsert(ctx, UpsertByEmail("new@acme.test"), WithPersonName("Jane"))
+	if err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+	if !res.Created || res.Record.ID != 99 || len(res.MatchedIDs) != 0 {
+		t.Fatalf("unexpected create result: %#v", res)
+	}
+
+	_, err = client.Persons.Upsert(ctx, UpsertByEmail("dup@acme.test"), WithPersonName("Jane"))
+	var conflict *UpsertConflictError
+	if !errors.As(err, &conflict) || len(conflict.IDs) != 2 || conflict.IDs[0] != 11 {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+
+	res, err = client.Persons.Upsert(ctx, UpsertByEmail("dup@acme.test").OnConflict(UpsertConflictUpdateBest), WithPersonName("Jane"))
+	if err != nil || res.Record.ID != 11 {
+		t.Fatalf("expected best match 11, got %#v, %v", res, err)
+	}
+	res, err = client.Persons.Upsert(ctx, UpsertByEmail("dup@acme.test").OnConflict(UpsertConflictUpdateOldest), WithPersonName("Jane"))
+	if err != nil || res.Record.ID != 11 {
+		t.Fatalf("expected oldest match 11, got %#v, %v", res, err)
+	}
+
+	want := []string{
+		`PATCH /persons/7 {"name":"Jane"}`,
+		`POST /persons {"name":"Jane"}`,
+		`PATCH /persons/11 {"name":"Jane"}`,
+		`PATCH /persons/11 {"name":"Jane"}`,
+	}
+	if len(writes) != len(want) {
+		t.Fatalf("unexpected writes: %v", writes)
+	}
+	for i := range want {
+		if strings.TrimSpace(writes[i]) != want[i] {
+			t.Fatalf("write %d: got %s, want %s", i, writes[i], want[i])
+		}
+	}
+
+	if _, err := client.Persons.Upsert(ctx, UpsertByCode("X-1")); err == nil {
+		t.Fatalf("expected unsupported match field error")
+	}
+}
+
+func TestDealsService_UpsertByExternalID(t *testing.T) {
+	t.Parallel()
+
+	var searches int
+	client := newUpsertTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/itemSearch/field":
+			searches++
+			q := r.URL.Query()
+			if q.Get("field") != "abc123" || q.Get("entity_type") != "deal" || q.Get("match") != "exact" || q.Get("term") != "ERP-1" {
+				t.Errorf("unexpected field search query: %s", r.URL.RawQuery)
+			}
+			if r.Header.Get("X-Test") != "1" {
+				t.Errorf("request options not applied to lookup")
+			}
+			if q.Get("cursor") == "" {
+				_, _ = w.Write([]byte(`{"data":[{"result_score":1,"item":{"id":3}}],"additional_data":{"next_cursor":"c2"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[{"result_score":1,"item":{"id":3}}],"additional_data":{"next_cursor":null}}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/deals/3":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["title"] != "Renewal" {
+				t.Errorf("unexpected update body: %v", body)
+			}
+			_, _ = w.Write([]byte(`{"data":{"id":3,"title":"Renewal"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+
+	header := pipedrive.WithHeader("X-Test", "1")
+	res, err := client.Deals.Upsert(context.Background(), UpsertByExternalID("abc123", "ERP-1"),
+		WithDealTitle("Renewal"), WithDealRequestOptions(header))
+	if err != nil {
+		t.Fatalf("Upsert error: %v", err)
+	}
+	if res.Created || res.Record.ID != 3 || len(res.MatchedIDs) != 1 || searches != 2 {
+		t.Fatalf("unexpected result: %#v after %d searches", res, searches)
+	}
+}
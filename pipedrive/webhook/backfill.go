@@ -0,0 +1,235 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+// ChangeSourceBackfill is the Meta.ChangeSource of events synthesized by
+// Backfill.
+const ChangeSourceBackfill = "backfill"
+
+// BackfillObjects are the objects Backfill can list. WebhookEventObjectAll
+// expands to them.
+var BackfillObjects = []v1.WebhookEventObject{
+	v1.WebhookEventObjectDeal,
+	v1.WebhookEventObjectPerson,
+	v1.WebhookEventObjectOrganization,
+	v1.WebhookEventObjectActivity,
+	v1.WebhookEventObjectProduct,
+	v1.WebhookEventObjectPipeline,
+	v1.WebhookEventObjectStage,
+}
+
+// Backfill reconstructs the events of entities updated in [from, to) and
+// passes them to fn, such as Handler.Dispatch, in update order per object,
+// including deleted and archived deals. A zero to means now.
+//
+// The events are shaped like version 2.0 deliveries with ChangeSource set to
+// ChangeSourceBackfill. Entities added in the window are reported as
+// creations and the others as changes. The API only returns the current
+// state, so Previous is nil and Changes is empty; deals deleted in the window
+// are reported as deletions with Previous set. Other deletions cannot be
+// recovered. Backfill stops at the first error of fn.
+//
+// Backfilled events carry no event ID, so their DeliveryKey never matches a
+// live delivery. When fn is a Handler with WithDeduplication, an event whose
+// update_time equals the last one applied is dropped as a duplicate, so a
+// window that overlaps live deliveries only dispatches newer state.
+func Backfill(ctx context.Context, client *v2.Client, from, to time.Time, objects []v1.WebhookEventObject, fn HandlerFunc) error {
+	if fn == nil {
+		return fmt.Errorf("webhook: backfill handler is required")
+	}
+	w := window{from: from, to: to}
+	seen := map[v1.WebhookEventObject]bool{}
+	for _, object := range expandObjects(objects) {
+		if seen[object] {
+			continue
+		}
+		seen[object] = true
+		if err := listUpdated(ctx, client, object, w, func(ev Event) error { return fn(ctx, ev) }); err != nil {
+			return fmt.Errorf("webhook: backfill %s: %w", object, err)
+		}
+	}
+	return nil
+}
+
+func expandObjects(objects []v1.WebhookEventObject) []v1.WebhookEventObject {
+	var out []v1.WebhookEventObject
+	for _, object := range objects {
+		if object == v1.WebhookEventObjectAll {
+			out = append(out, BackfillObjects...)
+			continue
+		}
+		out = append(out, object)
+	}
+	return out
+}
+
+type window struct {
+	from, to time.Time
+}
+
+func (w window) contains(t *time.Time) bool {
+	if t == nil {
+		return false
+	}
+	return !t.Before(w.from) && (w.to.IsZero() || t.Before(w.to))
+}
+
+// listUpdated emits an event for every object entity updated in w.
+func listUpdated(ctx context.Context, client *v2.Client, object v1.WebhookEventObject, w window, emit func(Event) error) error {
+	switch object {
+	case v1.WebhookEventObjectDeal:
+		opts := []v2.ListDealsOption{v2.WithDealsUpdatedSince(w.from), v2.WithDealsSortBy(v2.DealSortByUpdateTime), v2.WithDealsSortDirection(v2.SortAsc)}
+		archived := []v2.ListArchivedDealsOption{v2.WithArchivedDealsUpdatedSince(w.from), v2.WithArchivedDealsSortBy(v2.DealSortByUpdateTime), v2.WithArchivedDealsSortDirection(v2.SortAsc)}
+		if !w.to.IsZero() {
+			opts = append(opts, v2.WithDealsUpdatedUntil(w.to))
+			archived = append(archived, v2.WithArchivedDealsUpdatedUntil(w.to))
+		}
+		// Deleted and archived deals are listed separately; the three
+		// listings are merged to keep the update order.
+		return mergeDeals(ctx, []*pipedrive.CursorPager[v2.Deal]{
+			client.Deals.ListPager(opts...),
+			client.Deals.ListPager(append(opts, v2.WithDealsStatus(v2.DealStatusDeleted))...),
+			client.Deals.ListArchivedPager(archived...),
+		}, func(d v2.Deal) error {
+			return emitEntity(object, int64(d.ID), d, d.AddTime, d.UpdateTime, d.Status == v2.DealStatusDeleted, w, emit)
+		})
+	case v1.WebhookEventObjectPerson:
+		opts := []v2.ListPersonsOption{v2.WithPersonsUpdatedSince(w.from), v2.WithPersonsSortBy(v2.PersonSortByUpdateTime), v2.WithPersonsSortDirection(v2.SortAsc)}
+		if !w.to.IsZero() {
+			opts = append(opts, v2.WithPersonsUpdatedUntil(w.to))
+		}
+		return client.Persons.ForEach(ctx, func(p v2.Person) error {
+			return emitEntity(object, int64(p.ID), p, p.AddTime, p.UpdateTime, false, w, emit)
+		}, opts...)
+	case v1.WebhookEventObjectOrganization:
+		opts := []v2.ListOrganizationsOption{v2.WithOrganizationsUpdatedSince(w.from), v2.WithOrganizationsSortBy(v2.OrganizationSortByUpdateTime), v2.WithOrganizationsSortDirection(v2.SortAsc)}
+		if !w.to.IsZero() {
+			opts = append(opts, v2.WithOrganizationsUpdatedUntil(w.to))
+		}
+		return client.Organizations.ForEach(ctx, func(o v2.Organization) error {
+			return emitEntity(object, int64(o.ID), o, o.AddTime, o.UpdateTime, false, w, emit)
+		}, opts...)
+	case v1.WebhookEventObjectActivity:
+		opts := []v2.ListActivitiesOption{v2.WithActivitiesUpdatedSince(w.from), v2.WithActivitiesSortBy(v2.ActivitySortByUpdateTime), v2.WithActivitiesSortDirection(v2.SortAsc)}
+		if !w.to.IsZero() {
+			opts = append(opts, v2.WithActivitiesUpdatedUntil(w.to))
+		}
+		return client.Activities.ForEach(ctx, func(a v2.Activity) error {
+			return emitEntity(object, int64(a.ID), a, a.AddTime, a.UpdateTime, false, w, emit)
+		}, opts...)
+	case v1.WebhookEventObjectProduct:
+		// Products have no updated_until filter; the window is applied here.
+		return client.Products.ForEach(ctx, func(p v2.Product) error {
+			return emitEntity(object, int64(p.ID), p, p.AddTime, p.UpdateTime, false, w, emit)
+		}, v2.WithProductsUpdatedSince(w.from), v2.WithProductsSortBy(v2.ProductSortByUpdateTime), v2.WithProductsSortDirection(v2.SortAsc))
+	case v1.WebhookEventObjectPipeline:
+		// Pipelines and stages cannot be filtered by update time, but there
+		// are few of them.
+		return client.Pipelines.ForEach(ctx, func(p v2.Pipeline) error {
+			return emitEntity(object, int64(p.ID), p, p.AddTime, p.UpdateTime, false, w, emit)
+		}, v2.WithPipelinesSortBy(v2.PipelineSortByUpdateTime), v2.WithPipelinesSortDirection(v2.SortAsc))
+	case v1.WebhookEventObjectStage:
+		return client.Stages.ForEach(ctx, func(s v2.Stage) error {
+			return emitEntity(object, int64(s.ID), s, s.AddTime, s.UpdateTime, false, w, emit)
+		}, v2.WithStagesSortBy(v2.StageSortByUpdateTime), v2.WithStagesSortDirection(v2.SortAsc))
+	}
+	return fmt.Errorf("object %q cannot be listed by update time", object)
+}
+
+// mergeDeals passes the deals of pagers, each sorted by update time, to fn
+// in update time order, fetching each pager's pages as they are reached.
+func mergeDeals(ctx context.Context, pagers []*pipedrive.CursorPager[v2.Deal], fn func(v2.Deal) error) error {
+	pending := make([][]v2.Deal, len(pagers))
+	for {
+		next := -1
+		for i, pager := range pagers {
+			for len(pending[i]) == 0 && pager.Next(ctx) {
+				pending[i] = pager.Items()
+			}
+			if err := pager.Err(); err != nil {
+				return err
+			}
+			if len(pending[i]) > 0 && (next < 0 || updatedBefore(pending[i][0], pending[next][0])) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return nil
+		}
+		deal := pending[next][0]
+		pending[next] = pending[next][1:]
+		if err := fn(deal); err != nil {
+			return err
+		}
+	}
+}
+
+func updatedBefore(a, b v2.Deal) bool {
+	if a.UpdateTime == nil || b.UpdateTime == nil {
+		return a.UpdateTime == nil && b.UpdateTime != nil
+	}
+	return a.UpdateTime.Before(*b.UpdateTime)
+}
+
+// emitEntity emits entity as an event if its update time is in w.
+func emitEntity[T any](object v1.WebhookEventObject, id int64, entity T, added, updated *time.Time, deleted bool, w window, emit func(Event) error) error {
+	if !w.contains(updated) {
+		return nil
+	}
+	action := v1.WebhookEventActionChange
+	switch {
+	case deleted:
+		action = v1.WebhookEventActionDelete
+	case w.contains(added):
+		action = v1.WebhookEventActionCreate
+	}
+	ev, err := syntheticEvent(object, action, id, &entity, nil, *updated, ChangeSourceBackfill)
+	if err != nil {
+		return err
+	}
+	return emit(ev)
+}
+
+// syntheticEvent builds an event shaped like a version 2.0 delivery. For
+// deletions the entity is reported as Previous.
+func syntheticEvent[T any](object v1.WebhookEventObject, action v1.WebhookEventAction, id int64, current, previous *T, at time.Time, source string) (*EntityEvent[T], error) {
+	if action == v1.WebhookEventActionDelete && previous == nil {
+		current, previous = nil, current
+	}
+	ev := &EntityEvent[T]{
+		Meta: Meta{
+			Version:      v1.WebhookVersion2,
+			Action:       action,
+			Object:       object,
+			Event:        string(action) + "." + string(object),
+			EntityID:     strconv.FormatInt(id, 10),
+			Timestamp:    at,
+			ChangeSource: source,
+			Attempt:      1,
+		},
+		Current:  current,
+		Previous: previous,
+	}
+	var err error
+	if current != nil {
+		if ev.RawCurrent, err = json.Marshal(current); err != nil {
+			return nil, fmt.Errorf("encode %s %d: %w", object, id, err)
+		}
+	}
+	if previous != nil {
+		if ev.RawPrevious, err = json.Marshal(previous); err != nil {
+			return nil, fmt.Errorf("encode %s %d: %w", object, id, err)
+		}
+	}
+	return ev, nil
+}
@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+func TestBackfill(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2024, 4, 16, 10, 0, 0, 0, time.UTC)
+	to := from.Add(2 * time.Hour)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/deals":
+			if q.Get("updated_since") != "2024-04-16T10:00:00Z" || q.Get("updated_until") != "2024-04-16T12:00:00Z" || q.Get("sort_by") != "update_time" {
+				t.Errorf("unexpected deals query %s", r.URL.RawQuery)
+			}
+			if q.Get("status") == "deleted" {
+				_, _ = w.Write([]byte(`{"success":true,"data":[{"id":3,"status":"deleted","add_time":"2024-01-01T00:00:00Z","update_time":"2024-04-16T10:45:00Z"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"data":[
+				{"id":1,"status":"open","add_time":"2024-01-01T00:00:00Z","update_time":"2024-04-16T10:30:00Z"},
+				{"id":2,"status":"won","add_time":"2024-04-16T11:00:00Z","update_time":"2024-04-16T11:00:00Z"}
+			]}`))
+		case "/deals/archived":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"id":4,"status":"won","add_time":"2024-01-01T00:00:00Z","update_time":"2024-04-16T10:40:00Z"}]}`))
+		case "/products":
+			if q.Get("updated_since") != "2024-04-16T10:00:00Z" {
+				t.Errorf("unexpected products query %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"success":true,"data":[
+				{"id":7,"name":"Widget","add_time":"2024-01-01T00:00:00Z","update_time":"2024-04-16T10:15:00Z"},
+				{"id":8,"name":"Later","add_time":"2024-01-01T00:00:00Z","update_time":"2024-04-16T12:15:00Z"}
+			]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	client, err := v2.NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+
+	var got []string
+	h := NewHandler()
+	h.OnDeal(func(ctx context.Context, ev *DealEvent) error {
+		if ev.Meta.ChangeSource != ChangeSourceBackfill || ev.Meta.Version != v1.WebhookVersion2 {
+			t.Errorf("unexpected meta %#v", ev.Meta)
+		}
+		if ev.Meta.Action == v1.WebhookEventActionDelete && (ev.Current != nil || ev.Previous == nil) {
+			t.Errorf("expected deleted deal as previous: %#v", ev)
+		}
+		got = append(got, ev.Meta.Event+":"+ev.Meta.EntityID)
+		return nil
+	})
+	h.OnProduct(func(ctx context.Context, ev *ProductEvent) error {
+		if ev.Current == nil || ev.Current.Name != "Widget" || ev.RawCurrent == nil {
+			t.Errorf("unexpected product event %#v", ev)
+		}
+		got = append(got, ev.Meta.Event+":"+ev.Meta.EntityID)
+		return nil
+	})
+
+	objects := []v1.WebhookEventObject{v1.WebhookEventObjectDeal, v1.WebhookEventObjectProduct}
+	if err := Backfill(context.Background(), client, from, to, objects, h.Dispatch); err != nil {
+		t.Fatalf("Backfill error: %v", err)
+	}
+	want := "change.deal:1,change.deal:4,delete.deal:3,create.deal:2,change.product:7"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("unexpected events %v", got)
+	}
+
+	err = Backfill(context.Background(), client, from, to, []v1.WebhookEventObject{v1.WebhookEventObjectNote}, h.Dispatch)
+	if err == nil || !strings.Contains(err.Error(), "cannot be listed") {
+		t.Fatalf("expected unsupported object error, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package webhook
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+// FieldChange is one field that differs between the previous and current
+// entity payloads of a change event.
+type FieldChange struct {
+	// Field is the API key, such as "stage_id", or the custom field key.
+	Field string
+	// Name is the custom field name when a resolver knows it, and Field
+	// otherwise.
+	Name     string
+	Custom   bool
+	Previous interface{}
+	Current  interface{}
+}
+
+// StageTransition describes a deal moving between stages. The pipelines are
+// equal unless the deal also moved to another pipeline.
+type StageTransition struct {
+	From         v2.StageID
+	To           v2.StageID
+	FromPipeline v2.PipelineID
+	ToPipeline   v2.PipelineID
+}
+
+type StatusTransition struct {
+	From v2.DealStatus
+	To   v2.DealStatus
+}
+
+func (e *EntityEvent[T]) setFieldResolver(r *v2.CustomFieldResolver) {
+	e.fields = r
+}
+
+// Changes lists the fields a change event modified, sorted by key. Version 2.0
+// payloads name the modified fields in previous; version 1.0 payloads are
+// compared field by field. Custom fields are listed individually and named
+// through the resolver registered with WithCustomFieldResolver or passed to
+// ChangesWith. Other actions have no changes.
+func (e *EntityEvent[T]) Changes() []FieldChange {
+	return e.ChangesWith(e.fields)
+}
+
+func (e *EntityEvent[T]) ChangesWith(fields *v2.CustomFieldResolver) []FieldChange {
+	if e.Meta.Action != v1.WebhookEventActionChange || e.RawPrevious == nil || e.RawCurrent == nil {
+		return nil
+	}
+	current, previous := e.payloadMap(e.RawCurrent), e.payloadMap(e.RawPrevious)
+	if current == nil || previous == nil {
+		return nil
+	}
+	// Version 2.0 sends only the old values of modified fields.
+	sparse := e.Meta.Version == v1.WebhookVersion2
+
+	var changes []FieldChange
+	for _, key := range changedKeys(previous, current, sparse) {
+		if key == "custom_fields" {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: key, Name: key, Previous: previous[key], Current: current[key]})
+	}
+	prevCustom, _ := previous["custom_fields"].(map[string]interface{})
+	curCustom, _ := current["custom_fields"].(map[string]interface{})
+	for _, key := range changedKeys(prevCustom, curCustom, sparse) {
+		change := FieldChange{
+			Field:    key,
+			Name:     key,
+			Custom:   true,
+			Previous: customFieldValue(prevCustom[key]),
+			Current:  customFieldValue(curCustom[key]),
+		}
+		if sparse && reflect.DeepEqual(change.Previous, change.Current) {
+			continue
+		}
+		if fields != nil {
+			if name, ok := fields.Name(key); ok {
+				change.Name = name
+			}
+		}
+		changes = append(changes, change)
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Custom != changes[j].Custom {
+			return !changes[i].Custom
+		}
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// Change returns the change of field, matched by key or by custom field name.
+func (e *EntityEvent[T]) Change(field string) (FieldChange, bool) {
+	for _, change := range e.Changes() {
+		if change.Field == field || (change.Custom && strings.EqualFold(change.Name, field)) {
+			return change, true
+		}
+	}
+	return FieldChange{}, false
+}
+
+func (e *EntityEvent[T]) Changed(field string) bool {
+	_, ok := e.Change(field)
+	return ok
+}
+
+// StageTransition reports a stage change of a deal or other entity with a
+// stage_id.
+func (e *EntityEvent[T]) StageTransition() (StageTransition, bool) {
+	change, ok := e.Change("stage_id")
+	if !ok {
+		return StageTransition{}, false
+	}
+	t := StageTransition{From: v2.StageID(idValue(change.Previous)), To: v2.StageID(idValue(change.Current))}
+	current := e.payloadMap(e.RawCurrent)
+	t.ToPipeline = v2.PipelineID(idValue(current["pipeline_id"]))
+	t.FromPipeline = t.ToPipeline
+	if pipeline, ok := e.Change("pipeline_id"); ok {
+		t.FromPipeline = v2.PipelineID(idValue(pipeline.Previous))
+	}
+	return t, true
+}
+
+// StatusTransition reports a deal status change, such as open to won.
+func (e *EntityEvent[T]) StatusTransition() (StatusTransition, bool) {
+	change, ok := e.Change("status")
+	if !ok {
+		return StatusTransition{}, false
+	}
+	from, _ := change.Previous.(string)
+	to, _ := change.Current.(string)
+	return StatusTransition{From: v2.DealStatus(from), To: v2.DealStatus(to)}, true
+}
+
+// payloadMap decodes an entity payload, reshaping version 1.0 payloads the
+// way decodeEntity does so keys match the v2 models.
+func (e *EntityEvent[T]) payloadMap(raw json.RawMessage) map[string]interface{} {
+	if raw == nil {
+		return nil
+	}
+	if e.Meta.Version == v1.WebhookVersion1 && v2Models[e.Meta.Object] {
+		normalized, err := normalizeV1(e.Meta.Object, raw)
+		if err != nil {
+			return nil
+		}
+		raw = normalized
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+func changedKeys(previous, current map[string]interface{}, sparse bool) []string {
+	var keys []string
+	if sparse {
+		for key := range previous {
+			keys = append(keys, key)
+		}
+		return keys
+	}
+	for key, value := range previous {
+		if !reflect.DeepEqual(value, current[key]) {
+			keys = append(keys, key)
+		}
+	}
+	for key, value := range current {
+		if _, ok := previous[key]; !ok && value != nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// customFieldValue unwraps the {"type": ..., "value": ...} objects version 2.0
+// payloads use for custom fields. Values with more parts, such as monetary
+// amounts with a currency, keep everything but the type.
+func customFieldValue(raw interface{}) interface{} {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return raw
+	}
+	if _, typed := m["type"]; !typed {
+		return raw
+	}
+	if _, ok := m["value"]; ok && len(m) == 2 {
+		return m["value"]
+	}
+	out := make(map[string]interface{}, len(m)-1)
+	for key, value := range m {
+		if key != "type" {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+func idValue(value interface{}) int64 {
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	case map[string]interface{}:
+		return idValue(v["value"])
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+const customKey = "0123456789abcdef0123456789abcdef01234567"
+
+func TestChangesVersion2(t *testing.T) {
+	t.Parallel()
+
+	body := `{
+		"data": {"id": 42, "stage_id": 5, "pipeline_id": 2, "status": "won", "title": "Big deal", "custom_fields": {"` + customKey + `": {"type": "varchar", "value": "new"}, "other": {"type": "varchar", "value": "same"}}},
+		"previous": {"stage_id": 3, "pipeline_id": 1, "status": "open", "custom_fields": {"` + customKey + `": {"type": "varchar", "value": "old"}}},
+		"meta": {"action": "change", "entity": "deal", "entity_id": "42", "version": "2.0"}
+	}`
+	ev, err := Parse([]byte(body))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	deal := ev.(*DealEvent)
+
+	changes := deal.Changes()
+	var fields []string
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	want := []string{"pipeline_id", "stage_id", "status", customKey}
+	if len(fields) != len(want) {
+		t.Fatalf("unexpected changes: %v", fields)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Fatalf("unexpected changes: %v", fields)
+		}
+	}
+	if custom := changes[3]; !custom.Custom || custom.Previous != "old" || custom.Current != "new" {
+		t.Fatalf("unexpected custom change: %#v", custom)
+	}
+	if deal.Changed("title") || !deal.Changed("stage_id") {
+		t.Fatal("expected only stage_id among title and stage_id to change")
+	}
+
+	stage, ok := deal.StageTransition()
+	if !ok || stage != (StageTransition{From: 3, To: 5, FromPipeline: 1, ToPipeline: 2}) {
+		t.Fatalf("unexpected stage transition: %#v", stage)
+	}
+	status, ok := deal.StatusTransition()
+	if !ok || status.From != v2.DealStatusOpen || status.To != v2.DealStatusWon {
+		t.Fatalf("unexpected status transition: %#v", status)
+	}
+
+	resolver := v2.NewCustomFieldResolver([]v2.Field{{FieldCode: customKey, FieldName: "Region", IsCustomField: true}})
+	named := deal.ChangesWith(resolver)
+	if named[3].Name != "Region" {
+		t.Fatalf("expected resolved name, got %#v", named[3])
+	}
+}
+
+func TestChangesVersion1(t *testing.T) {
+	t.Parallel()
+
+	ev, err := Parse([]byte(v1PersonUpdate))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	person := ev.(*PersonEvent)
+	if !person.Changed("name") || person.Changed("owner_id") || person.Changed("id") {
+		t.Fatalf("unexpected changes: %#v", person.Changes())
+	}
+	change, ok := person.Change(customKey)
+	if !ok || !change.Custom || change.Current != "custom" || change.Previous != nil {
+		t.Fatalf("unexpected custom change: %#v", change)
+	}
+	if _, ok := person.StageTransition(); ok {
+		t.Fatal("expected no stage transition")
+	}
+}
+
+func TestChangesOnlyForChangeAction(t *testing.T) {
+	t.Parallel()
+
+	body := `{"data": {"id": 1, "stage_id": 2}, "previous": null, "meta": {"action": "create", "entity": "deal", "version": "2.0"}}`
+	ev, err := Parse([]byte(body))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if changes := ev.(*DealEvent).Changes(); changes != nil {
+		t.Fatalf("expected no changes, got %#v", changes)
+	}
+}
+
+func TestHandlerCustomFieldResolver(t *testing.T) {
+	t.Parallel()
+
+	resolver := v2.NewCustomFieldResolver([]v2.Field{{FieldCode: customKey, FieldName: "Region", IsCustomField: true}})
+	h := NewHandler(WithCustomFieldResolver(v1.WebhookEventObjectDeal, resolver))
+	var got FieldChange
+	h.OnDeal(func(ctx context.Context, ev *DealEvent) error {
+		got, _ = ev.Change("region")
+		return nil
+	})
+
+	body := `{
+		"data": {"id": 42, "custom_fields": {"` + customKey + `": {"type": "varchar", "value": "EU"}}},
+		"previous": {"custom_fields": {"` + customKey + `": null}},
+		"meta": {"action": "change", "entity": "deal", "entity_id": "42", "version": "2.0"}
+	}`
+	ev, err := Parse([]byte(body))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if err := h.Dispatch(context.Background(), ev); err != nil {
+		t.Fatalf("Dispatch error: %v", err)
+	}
+	if got.Field != customKey || got.Name != "Region" || got.Current != "EU" || got.Previous != nil {
+		t.Fatalf("unexpected change: %#v", got)
+	}
+}
@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Store remembers handled deliveries and the update_time last applied per
+// entity. Implementations must be safe for concurrent use.
+type Store interface {
+	// Seen reports whether a delivery with key was recorded.
+	Seen(ctx context.Context, key string) (bool, error)
+	// LastUpdate returns the latest update_time recorded for entity.
+	LastUpdate(ctx context.Context, entity string) (time.Time, bool, error)
+	// Record stores key and, unless updated is zero, advances the update_time
+	// of entity to updated when it is later than the stored one.
+	Record(ctx context.Context, key, entity string, updated time.Time) error
+}
+
+type DropReason string
+
+const (
+	// DropDuplicate is a redelivery of an event that was already handled.
+	DropDuplicate DropReason = "duplicate"
+	// DropStale is an event older than the last one applied to its entity.
+	DropStale DropReason = "stale"
+)
+
+// WithDeduplication makes Dispatch skip redelivered events and events whose
+// entity update_time is older than the last one applied, so handlers see
+// each change once and in order. Events of the same entity are dispatched
+// one at a time; a concurrent delivery waits for the one in progress. Events
+// are recorded in store after every handler succeeded; skipped deliveries
+// are acknowledged with a 200.
+//
+// Events synthesized by Backfill and Poller have no event ID to match a live
+// delivery of the same change, so they are also skipped as duplicates when
+// their update_time is not after the last one applied.
+func WithDeduplication(store Store) Option {
+	return func(h *Handler) {
+		h.store = store
+	}
+}
+
+// WithDropHandler is called with every event WithDeduplication skips.
+func WithDropHandler(fn func(context.Context, Event, DropReason)) Option {
+	return func(h *Handler) {
+		h.onDrop = fn
+	}
+}
+
+// DeliveryKey identifies the event independently of delivery attempts and
+// of the webhook it was delivered through. Version 2.0 events are keyed by
+// their event ID. Version 1.0 payloads carry none, so they are keyed by the
+// event, entity and microsecond timestamp.
+func (m Meta) DeliveryKey() string {
+	if m.ID != "" {
+		return "id:" + m.ID
+	}
+	entity := string(m.Object) + ":" + m.EntityID
+	if m.CorrelationID != "" {
+		return "correlation:" + m.CorrelationID + ":" + string(m.Action) + ":" + entity
+	}
+	return "event:" + string(m.Action) + ":" + entity + ":" + strconv.FormatInt(m.Timestamp.UnixMicro(), 10)
+}
+
+func (m Meta) entityKey() string {
+	return string(m.Object) + ":" + m.EntityID
+}
+
+type updateTimer interface {
+	updateTime() time.Time
+}
+
+// updateTime returns the update_time of the entity after the event, or of
+// the deleted entity for deletions.
+func (e *EntityEvent[T]) updateTime() time.Time {
+	raw := e.RawCurrent
+	if raw == nil {
+		raw = e.RawPrevious
+	}
+	fields := e.payloadMap(raw)
+	value, _ := fields["update_time"].(string)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// lockEntity waits until no other event of the entity of ev is being
+// dispatched and claims it, so that the update_time check, the handlers and
+// Record run for one event of an entity at a time. The returned unlock must
+// be called once the event was recorded or failed.
+func (h *Handler) lockEntity(ctx context.Context, ev Event) (func(), error) {
+	key := ev.EventMeta().entityKey()
+	for {
+		h.mu.Lock()
+		if h.inflight == nil {
+			h.inflight = make(map[string]chan struct{})
+		}
+		busy, ok := h.inflight[key]
+		if !ok {
+			done := make(chan struct{})
+			h.inflight[key] = done
+			h.mu.Unlock()
+			return func() {
+				h.mu.Lock()
+				delete(h.inflight, key)
+				h.mu.Unlock()
+				close(done)
+			}, nil
+		}
+		h.mu.Unlock()
+		select {
+		case <-busy:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// admit decides whether ev should be dispatched. The caller must hold the
+// lock of the entity of ev.
+func (h *Handler) admit(ctx context.Context, ev Event) (DropReason, error) {
+	meta := ev.EventMeta()
+	key := meta.DeliveryKey()
+
+	seen, err := h.store.Seen(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if seen {
+		return DropDuplicate, nil
+	}
+	if updated := eventUpdateTime(ev); !updated.IsZero() {
+		last, ok, err := h.store.LastUpdate(ctx, meta.entityKey())
+		if err != nil {
+			return "", err
+		}
+		if ok && updated.Before(last) {
+			return DropStale, nil
+		}
+		if ok && synthesized(meta) && updated.Equal(last) {
+			return DropDuplicate, nil
+		}
+	}
+	return "", nil
+}
+
+// synthesized reports whether the event was built from a listing rather
+// than delivered by Pipedrive.
+func synthesized(meta Meta) bool {
+	return meta.ID == "" && (meta.ChangeSource == ChangeSourceBackfill || meta.ChangeSource == ChangeSourcePoll)
+}
+
+func (h *Handler) record(ctx context.Context, ev Event) error {
+	meta := ev.EventMeta()
+	return h.store.Record(ctx, meta.DeliveryKey(), meta.entityKey(), eventUpdateTime(ev))
+}
+
+func eventUpdateTime(ev Event) time.Time {
+	if timed, ok := ev.(updateTimer); ok {
+		return timed.updateTime()
+	}
+	return time.Time{}
+}
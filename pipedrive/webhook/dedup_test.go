@@ -0,0 +1,233 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+func dealChange(id, stage, updated string) string {
+	return `{
+		"data": {"id": 42, "stage_id": ` + stage + `, "update_time": "` + updated + `"},
+		"previous": {"stage_id": 1},
+		"meta": {"action": "change", "entity": "deal", "entity_id": "42", "id": "` + id + `", "version": "2.0"}
+	}`
+}
+
+func TestHandlerDeduplication(t *testing.T) {
+	t.Parallel()
+
+	var (
+		handled []string
+		dropped []DropReason
+		fail    = true
+	)
+	h := NewHandler(
+		WithDeduplication(NewMemoryStore(0)),
+		WithDropHandler(func(ctx context.Context, ev Event, reason DropReason) {
+			dropped = append(dropped, reason)
+		}),
+	)
+	h.OnDeal(func(ctx context.Context, ev *DealEvent) error {
+		if ev.Meta.ID == "e1" && fail {
+			fail = false
+			return errors.New("temporary")
+		}
+		handled = append(handled, ev.Meta.ID)
+		return nil
+	})
+	dispatch := func(body string) error {
+		ev, err := Parse([]byte(body))
+		if err != nil {
+			t.Fatalf("Parse error: %v", err)
+		}
+		return h.Dispatch(context.Background(), ev)
+	}
+
+	if err := dispatch(dealChange("e1", "2", "2024-04-16T12:00:00Z")); err == nil {
+		t.Fatal("expected handler error")
+	}
+	// A failed event is not recorded, so its redelivery is handled.
+	for _, body := range []string{
+		dealChange("e1", "2", "2024-04-16T12:00:00Z"),
+		dealChange("e1", "2", "2024-04-16T12:00:00Z"),
+		dealChange("e3", "4", "2024-04-16T12:05:00Z"),
+		dealChange("e2", "3", "2024-04-16T12:01:00Z"),
+		dealChange("e4", "5", "2024-04-16T12:05:00Z"),
+	} {
+		if err := dispatch(body); err != nil {
+			t.Fatalf("Dispatch error: %v", err)
+		}
+	}
+	if strings.Join(handled, ",") != "e1,e3,e4" {
+		t.Fatalf("unexpected handled events: %v", handled)
+	}
+	if len(dropped) != 2 || dropped[0] != DropDuplicate || dropped[1] != DropStale {
+		t.Fatalf("unexpected drops: %v", dropped)
+	}
+}
+
+func TestHandlerDeduplicationBackfillOverlap(t *testing.T) {
+	t.Parallel()
+
+	var handled []string
+	h := NewHandler(WithDeduplication(NewMemoryStore(0)))
+	h.OnDeal(func(ctx context.Context, ev *DealEvent) error {
+		handled = append(handled, ev.Meta.ChangeSource+"@"+ev.Current.UpdateTime.Format("15:04"))
+		return nil
+	})
+	live, err := Parse([]byte(dealChange("e1", "2", "2024-04-16T12:00:00Z")))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if err := h.Dispatch(context.Background(), live); err != nil {
+		t.Fatalf("Dispatch error: %v", err)
+	}
+	for _, at := range []time.Time{
+		time.Date(2024, 4, 16, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 16, 12, 10, 0, 0, time.UTC),
+	} {
+		ev, err := syntheticEvent(v1.WebhookEventObjectDeal, v1.WebhookEventActionChange, 42, &v2.Deal{ID: 42, UpdateTime: &at}, nil, at, ChangeSourceBackfill)
+		if err != nil {
+			t.Fatalf("syntheticEvent error: %v", err)
+		}
+		if err := h.Dispatch(context.Background(), ev); err != nil {
+			t.Fatalf("Dispatch error: %v", err)
+		}
+	}
+	if strings.Join(handled, ",") != "@12:00,backfill@12:10" {
+		t.Fatalf("unexpected handled events: %v", handled)
+	}
+}
+
+func TestHandlerDeduplicationConcurrentEntity(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		handled []string
+		dropped []DropReason
+		started = make(chan struct{})
+		proceed = make(chan struct{})
+	)
+	h := NewHandler(
+		WithDeduplication(NewMemoryStore(0)),
+		WithDropHandler(func(ctx context.Context, ev Event, reason DropReason) {
+			mu.Lock()
+			dropped = append(dropped, reason)
+			mu.Unlock()
+		}),
+	)
+	h.OnDeal(func(ctx context.Context, ev *DealEvent) error {
+		if ev.Meta.ID == "later" {
+			close(started)
+			<-proceed
+		}
+		mu.Lock()
+		handled = append(handled, ev.Meta.ID)
+		mu.Unlock()
+		return nil
+	})
+	dispatch := func(body string) error {
+		ev, err := Parse([]byte(body))
+		if err != nil {
+			return err
+		}
+		return h.Dispatch(context.Background(), ev)
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- dispatch(dealChange("later", "3", "2024-04-16T12:05:00Z"))
+	}()
+	<-started
+	// The earlier change arrives while the later one is still dispatching.
+	go func() {
+		errs <- dispatch(dealChange("earlier", "2", "2024-04-16T12:00:00Z"))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(proceed)
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatalf("Dispatch error: %v", err)
+		}
+	}
+	if strings.Join(handled, ",") != "later" {
+		t.Fatalf("unexpected handled events: %v", handled)
+	}
+	if len(dropped) != 1 || dropped[0] != DropStale {
+		t.Fatalf("unexpected drops: %v", dropped)
+	}
+}
+
+func TestDeliveryKey(t *testing.T) {
+	t.Parallel()
+
+	ts := time.UnixMicro(1713268800123456)
+	cases := []struct {
+		meta Meta
+		want string
+	}{
+		{Meta{ID: "e1", CorrelationID: "c1", Object: "deal", EntityID: "1"}, "id:e1"},
+		{Meta{CorrelationID: "c1", Action: "change", Object: "deal", EntityID: "1"}, "correlation:c1:change:deal:1"},
+		{Meta{Action: "change", Object: "deal", EntityID: "1", Timestamp: ts}, "event:change:deal:1:1713268800123456"},
+	}
+	for _, c := range cases {
+		if got := c.meta.DeliveryKey(); got != c.want {
+			t.Fatalf("DeliveryKey() = %q, want %q", got, c.want)
+		}
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := NewMemoryStore(2)
+	t0 := time.Date(2024, 4, 16, 12, 0, 0, 0, time.UTC)
+	_ = s.Record(ctx, "a", "deal:1", t0)
+	_ = s.Record(ctx, "b", "deal:2", t0)
+	if seen, _ := s.Seen(ctx, "a"); !seen {
+		t.Fatal("expected a to be seen")
+	}
+	_ = s.Record(ctx, "c", "deal:1", t0.Add(-time.Hour))
+	if seen, _ := s.Seen(ctx, "b"); seen {
+		t.Fatal("expected b to be evicted")
+	}
+	if last, ok, _ := s.LastUpdate(ctx, "deal:1"); !ok || !last.Equal(t0) {
+		t.Fatalf("expected update time to stay at %s, got %s", t0, last)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	s, err := NewFileStore(path, 10)
+	if err != nil {
+		t.Fatalf("NewFileStore error: %v", err)
+	}
+	t0 := time.Date(2024, 4, 16, 12, 0, 0, 0, time.UTC)
+	if err := s.Record(ctx, "a", "deal:1", t0); err != nil {
+		t.Fatalf("Record error: %v", err)
+	}
+
+	reopened, err := NewFileStore(path, 10)
+	if err != nil {
+		t.Fatalf("NewFileStore error: %v", err)
+	}
+	if seen, _ := reopened.Seen(ctx, "a"); !seen {
+		t.Fatal("expected a to survive a restart")
+	}
+	if last, ok, _ := reopened.LastUpdate(ctx, "deal:1"); !ok || !last.Equal(t0) {
+		t.Fatalf("unexpected update time %s", last)
+	}
+}
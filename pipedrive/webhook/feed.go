@@ -0,0 +1,373 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+// ChangeSourcePoll is the Meta.ChangeSource of events emitted by a Poller.
+const ChangeSourcePoll = "poll"
+
+const (
+	defaultPollInterval = time.Minute
+	recentsPageSize     = 500
+)
+
+// Poller is a change feed for receivers that cannot expose a webhook
+// endpoint. Every poll lists the entities updated since the previous one
+// through the v2 updated_since filters, compares them with the last seen
+// snapshot and emits version 2.0 shaped create and change events. As in live
+// deliveries, the previous payload of a change only holds the fields that
+// changed. Deletions are found through the v1 recents endpoint.
+//
+// Snapshots are kept in memory for every entity seen, so the first change of
+// an entity after the poller started has no previous values. Deleted entities
+// leave a tombstone so the inclusive since filters do not report their
+// deletion again.
+type Poller struct {
+	v1       *v1.Client
+	v2       *v2.Client
+	objects  []v1.WebhookEventObject
+	interval time.Duration
+
+	mu           sync.Mutex
+	since        map[v1.WebhookEventObject]time.Time
+	recentsSince time.Time
+	snapshots    map[string]json.RawMessage
+	tombstones   map[string]struct{}
+
+	// errMu is separate from mu, which Poll holds while the Events consumer
+	// is still reading, so Err never waits on a poll.
+	errMu sync.Mutex
+	err   error
+}
+
+type PollerOption func(*Poller)
+
+// WithPollObjects selects the objects to poll. The default is every object in
+// BackfillObjects.
+func WithPollObjects(objects ...v1.WebhookEventObject) PollerOption {
+	return func(p *Poller) {
+		p.objects = objects
+	}
+}
+
+// WithPollInterval sets the time between polls in Run. The default is one
+// minute.
+func WithPollInterval(d time.Duration) PollerOption {
+	return func(p *Poller) {
+		if d > 0 {
+			p.interval = d
+		}
+	}
+}
+
+// WithPollSince starts the feed at t instead of the time NewPoller is called.
+func WithPollSince(t time.Time) PollerOption {
+	return func(p *Poller) {
+		for _, object := range expandObjects(p.objects) {
+			p.since[object] = t
+		}
+		p.recentsSince = t
+	}
+}
+
+// NewPoller returns a poller reading entities through v2Client and
+// deletions through v1Client. Without a v1 client deletions other than those
+// of deals are not reported.
+func NewPoller(v1Client *v1.Client, v2Client *v2.Client, opts ...PollerOption) *Poller {
+	now := time.Now()
+	p := &Poller{
+		v1:           v1Client,
+		v2:           v2Client,
+		objects:      BackfillObjects,
+		interval:     defaultPollInterval,
+		since:        map[v1.WebhookEventObject]time.Time{},
+		recentsSince: now,
+		snapshots:    map[string]json.RawMessage{},
+		tombstones:   map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+	for _, object := range expandObjects(p.objects) {
+		if _, ok := p.since[object]; !ok {
+			p.since[object] = now
+		}
+	}
+	return p
+}
+
+// Poll runs one poll and passes the events to fn in update order per object.
+// When fn fails the poll stops; events fn did not accept are emitted again by
+// the next poll.
+func (p *Poller) Poll(ctx context.Context, fn HandlerFunc) error {
+	if fn == nil {
+		return errors.New("webhook: poll handler is required")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, object := range expandObjects(p.objects) {
+		next := p.since[object]
+		err := listUpdated(ctx, p.v2, object, window{from: p.since[object]}, func(ev Event) error {
+			if ts := ev.EventMeta().Timestamp; ts.After(next) {
+				next = ts
+			}
+			return p.emitListed(ctx, ev, fn)
+		})
+		if err != nil {
+			return fmt.Errorf("webhook: poll %s: %w", object, err)
+		}
+		p.since[object] = next
+	}
+	if err := p.pollDeletions(ctx, fn); err != nil {
+		return fmt.Errorf("webhook: poll deletions: %w", err)
+	}
+	return nil
+}
+
+// Run polls until ctx is done, first immediately and then at the poll
+// interval. It returns the first error of Poll, or nil once ctx is done.
+func (p *Poller) Run(ctx context.Context, fn HandlerFunc) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Poll(ctx, fn); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Events runs the poller in the background and delivers its events on the
+// returned channel, which is closed when ctx is done or polling fails. Err
+// reports the failure.
+func (p *Poller) Events(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		err := p.Run(ctx, func(ctx context.Context, ev Event) error {
+			select {
+			case events <- ev:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		p.errMu.Lock()
+		p.err = err
+		p.errMu.Unlock()
+	}()
+	return events
+}
+
+// Err returns the error that closed the channel of Events.
+func (p *Poller) Err() error {
+	p.errMu.Lock()
+	defer p.errMu.Unlock()
+	return p.err
+}
+
+type rawPayloader interface {
+	rawPayloads() (json.RawMessage, json.RawMessage)
+}
+
+func (e *EntityEvent[T]) rawPayloads() (json.RawMessage, json.RawMessage) {
+	return e.RawCurrent, e.RawPrevious
+}
+
+// emitListed turns an event built from a listed entity into a change against
+// the snapshot, skipping entities that did not change since the last poll.
+func (p *Poller) emitListed(ctx context.Context, ev Event, fn HandlerFunc) error {
+	payloads, ok := ev.(rawPayloader)
+	if !ok {
+		return nil
+	}
+	meta := ev.EventMeta()
+	meta.ChangeSource = ChangeSourcePoll
+	key := meta.entityKey()
+	current, deleted := payloads.rawPayloads()
+	snapshot, known := p.snapshots[key]
+
+	if meta.Action == v1.WebhookEventActionDelete {
+		if _, done := p.tombstones[key]; done {
+			return nil
+		}
+		if known {
+			deleted = snapshot
+		}
+		return p.emit(ctx, fn, meta, key, nil, deleted)
+	}
+	if known && bytes.Equal(snapshot, current) {
+		return nil
+	}
+	var previous json.RawMessage
+	if known {
+		meta.Action = v1.WebhookEventActionChange
+		var err error
+		if previous, err = changedPrevious(snapshot, current); err != nil {
+			return err
+		}
+		if previous == nil {
+			p.snapshots[key] = current
+			return nil
+		}
+	}
+	return p.emit(ctx, fn, meta, key, current, previous)
+}
+
+func (p *Poller) emit(ctx context.Context, fn HandlerFunc, meta Meta, key string, current, previous json.RawMessage) error {
+	meta.Event = string(meta.Action) + "." + string(meta.Object)
+	ev, err := decodeEvent(meta, current, previous)
+	if err != nil {
+		return err
+	}
+	if err := fn(ctx, ev); err != nil {
+		return err
+	}
+	if current == nil {
+		delete(p.snapshots, key)
+		p.tombstones[key] = struct{}{}
+	} else {
+		p.snapshots[key] = current
+		delete(p.tombstones, key)
+	}
+	return nil
+}
+
+// pollDeletions reports entities the recents endpoint lists as deleted.
+func (p *Poller) pollDeletions(ctx context.Context, fn HandlerFunc) error {
+	if p.v1 == nil {
+		return nil
+	}
+	var items []v1.RecentsItemType
+	objects := map[v1.WebhookEventObject]bool{}
+	for _, object := range expandObjects(p.objects) {
+		if object != v1.WebhookEventObjectDeal {
+			items = append(items, v1.RecentsItemType(object))
+		}
+		objects[object] = true
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	next, err := p.v1.Recents.ForEachSince(ctx, p.recentsSince, func(item v1.RecentItem) error {
+		object := v1.WebhookEventObject(item.Item)
+		if !objects[object] || !recentDeleted(item.Raw) {
+			return nil
+		}
+		return p.emitDeletion(ctx, fn, object, item)
+	}, v1.WithRecentsItems(items...), v1.WithRecentsLimit(recentsPageSize))
+	if err != nil {
+		return err
+	}
+	p.recentsSince = next
+	return nil
+}
+
+func (p *Poller) emitDeletion(ctx context.Context, fn HandlerFunc, object v1.WebhookEventObject, r v1.RecentItem) error {
+	meta := Meta{
+		Version:      v1.WebhookVersion2,
+		Action:       v1.WebhookEventActionDelete,
+		Object:       object,
+		EntityID:     fmt.Sprint(r.ID),
+		Timestamp:    time.Now().UTC(),
+		ChangeSource: ChangeSourcePoll,
+		Attempt:      1,
+	}
+	key := meta.entityKey()
+	if _, done := p.tombstones[key]; done {
+		return nil
+	}
+	previous, known := p.snapshots[key]
+	if !known {
+		if present(r.Raw) == nil {
+			return nil
+		}
+		normalized, err := normalizeV1(object, r.Raw)
+		if err != nil {
+			return err
+		}
+		previous = normalized
+	}
+	return p.emit(ctx, fn, meta, key, nil, previous)
+}
+
+// recentDeleted reports whether a recents item describes a deleted entity.
+func recentDeleted(data json.RawMessage) bool {
+	if present(data) == nil {
+		return true
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+	if active, ok := fields["active_flag"].(bool); ok && !active {
+		return true
+	}
+	if deleted, ok := fields["deleted"].(bool); ok && deleted {
+		return true
+	}
+	if deleted, ok := fields["is_deleted"].(bool); ok && deleted {
+		return true
+	}
+	return fields["status"] == string(v2.DealStatusDeleted)
+}
+
+// changedPrevious returns the fields of snapshot that differ from current, in
+// the shape of a version 2.0 previous payload, or nil when none differ.
+func changedPrevious(snapshot, current json.RawMessage) (json.RawMessage, error) {
+	var old, cur map[string]interface{}
+	if err := json.Unmarshal(snapshot, &old); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(current, &cur); err != nil {
+		return nil, err
+	}
+	previous := diffFields(old, cur)
+	oldCustom, _ := old["custom_fields"].(map[string]interface{})
+	curCustom, _ := cur["custom_fields"].(map[string]interface{})
+	delete(previous, "custom_fields")
+	if custom := diffFields(oldCustom, curCustom); len(custom) > 0 {
+		previous["custom_fields"] = custom
+	}
+	if len(previous) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(previous)
+}
+
+func diffFields(old, cur map[string]interface{}) map[string]interface{} {
+	diff := map[string]interface{}{}
+	for key, value := range old {
+		if !reflect.DeepEqual(value, cur[key]) {
+			diff[key] = value
+		}
+	}
+	for key, value := range cur {
+		if _, ok := old[key]; !ok && value != nil {
+			diff[key] = nil
+		}
+	}
+	return diff
+}
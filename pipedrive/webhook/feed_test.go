@@ -0,0 +1,212 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+func TestPoller(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		round int
+		since []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/deals":
+			if q.Get("status") == "deleted" {
+				if round == 2 {
+					_, _ = w.Write([]byte(`{"success":true,"data":[{"id":1,"title":"Deal","stage_id":3,"status":"deleted","add_time":"2024-01-01T00:00:00Z","update_time":"2024-04-16T12:00:00Z"}]}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"success":true,"data":[]}`))
+				return
+			}
+			since = append(since, q.Get("updated_since"))
+			switch round {
+			case 2:
+				_, _ = w.Write([]byte(`{"success":true,"data":[]}`))
+			case 0:
+				_, _ = w.Write([]byte(`{"success":true,"data":[{"id":1,"title":"Deal","stage_id":2,"status":"open","add_time":"2024-01-01T00:00:00Z","update_time":"2024-04-16T10:00:00Z","custom_fields":{"abc":"x"}}]}`))
+			default:
+				_, _ = w.Write([]byte(`{"success":true,"data":[{"id":1,"title":"Deal","stage_id":3,"status":"won","add_time":"2024-01-01T00:00:00Z","update_time":"2024-04-16T11:00:00Z","custom_fields":{"abc":"y"}}]}`))
+			}
+		case "/deals/archived":
+			_, _ = w.Write([]byte(`{"success":true,"data":[]}`))
+		case "/persons":
+			if round == 0 {
+				_, _ = w.Write([]byte(`{"success":true,"data":[{"id":5,"name":"Jane","add_time":"2024-04-16T09:30:00Z","update_time":"2024-04-16T09:30:00Z"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"data":[]}`))
+		case "/recents":
+			if q.Get("items") != "person" {
+				t.Errorf("unexpected recents items %q", q.Get("items"))
+			}
+			if round == 0 {
+				_, _ = w.Write([]byte(`{"success":true,"data":[],"additional_data":{"last_timestamp_on_page":"2024-04-16 10:00:00"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"item":"person","id":5,"data":{"id":5,"name":"Jane","active_flag":false}}],"additional_data":{"last_timestamp_on_page":"2024-04-16 11:00:00"}}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	cfg := pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()}
+	v1Client, err := v1.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("v1 NewClient error: %v", err)
+	}
+	v2Client, err := v2.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("v2 NewClient error: %v", err)
+	}
+
+	start := time.Date(2024, 4, 16, 9, 0, 0, 0, time.UTC)
+	p := NewPoller(v1Client, v2Client,
+		WithPollObjects(v1.WebhookEventObjectDeal, v1.WebhookEventObjectPerson),
+		WithPollSince(start),
+	)
+	var events []Event
+	collect := func(ctx context.Context, ev Event) error {
+		events = append(events, ev)
+		return nil
+	}
+
+	ctx := context.Background()
+	if err := p.Poll(ctx, collect); err != nil {
+		t.Fatalf("Poll error: %v", err)
+	}
+	if len(events) != 2 || events[0].EventMeta().Event != "change.deal" || events[1].EventMeta().Event != "create.person" {
+		t.Fatalf("unexpected first poll events: %#v", events)
+	}
+	// Polling again without changes emits nothing.
+	events = nil
+	if err := p.Poll(ctx, collect); err != nil || len(events) != 0 {
+		t.Fatalf("expected no events, got %d, %v", len(events), err)
+	}
+
+	mu.Lock()
+	round = 1
+	mu.Unlock()
+	events = nil
+	if err := p.Poll(ctx, collect); err != nil {
+		t.Fatalf("Poll error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("unexpected events: %#v", events)
+	}
+	deal := events[0].(*DealEvent)
+	if deal.Meta.ChangeSource != ChangeSourcePoll || deal.Previous == nil || *deal.Previous.StageID != 2 || *deal.Current.StageID != 3 {
+		t.Fatalf("unexpected deal change: %#v", deal)
+	}
+	if status, ok := deal.StatusTransition(); !ok || status.To != v2.DealStatusWon {
+		t.Fatalf("unexpected status transition: %#v", status)
+	}
+	var changed []string
+	for _, c := range deal.Changes() {
+		changed = append(changed, c.Field)
+	}
+	if strings.Join(changed, ",") != "stage_id,status,update_time,abc" {
+		t.Fatalf("unexpected changes: %v", changed)
+	}
+	person := events[1].(*PersonEvent)
+	if person.Meta.Action != v1.WebhookEventActionDelete || person.Previous == nil || person.Previous.Name != "Jane" || person.Current != nil {
+		t.Fatalf("unexpected person deletion: %#v", person)
+	}
+
+	mu.Lock()
+	if strings.Join(since, ",") != "2024-04-16T09:00:00Z,2024-04-16T10:00:00Z,2024-04-16T10:00:00Z" {
+		t.Fatalf("unexpected updated_since values: %v", since)
+	}
+	mu.Unlock()
+
+	// The recents since filter is inclusive, so the person is listed as
+	// deleted again; its deletion was already delivered.
+	events = nil
+	if err := p.Poll(ctx, collect); err != nil || len(events) != 0 {
+		t.Fatalf("expected no repeated deletion, got %d, %v", len(events), err)
+	}
+
+	mu.Lock()
+	round = 2
+	mu.Unlock()
+	for i := 0; i < 2; i++ {
+		events = nil
+		if err := p.Poll(ctx, collect); err != nil {
+			t.Fatalf("Poll error: %v", err)
+		}
+		want := 1
+		if i > 0 {
+			want = 0
+		}
+		if len(events) != want || want == 1 && events[0].EventMeta().Event != "delete.deal" {
+			t.Fatalf("poll %d after deal deletion: expected %d events, got %#v", i+1, want, events)
+		}
+	}
+	if _, ok := p.tombstones["deal:1"]; !ok {
+		t.Fatalf("expected deal tombstone")
+	}
+}
+
+func TestPollerEvents(t *testing.T) {
+	t.Parallel()
+
+	polled := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case polled <- struct{}{}:
+		default:
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":[{"id":7,"name":"Widget","add_time":"2024-04-16T10:00:00Z","update_time":"2024-04-16T10:00:00Z"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	v2Client, err := v2.NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	p := NewPoller(nil, v2Client, WithPollObjects(v1.WebhookEventObjectProduct), WithPollSince(time.Date(2024, 4, 16, 0, 0, 0, 0, time.UTC)), WithPollInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := p.Events(ctx)
+	// Err must not block while a poll waits for the consumer.
+	<-polled
+	errDone := make(chan error)
+	go func() { errDone <- p.Err() }()
+	select {
+	case err := <-errDone:
+		if err != nil {
+			t.Fatalf("unexpected error while polling: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Err blocked while the channel was open")
+	}
+	ev := <-events
+	if product, ok := ev.(*ProductEvent); !ok || product.Meta.Action != v1.WebhookEventActionCreate || product.Current.Name != "Widget" {
+		t.Fatalf("unexpected event %#v", ev)
+	}
+	cancel()
+	for range events {
+	}
+	if err := p.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+const defaultMaxBodyBytes = 5 << 20
+
+// HandlerFunc handles one event. Returning an error answers the delivery with
+// a 500 so Pipedrive retries it.
+type HandlerFunc func(ctx context.Context, ev Event) error
+
+// Handler receives Pipedrive webhook deliveries over HTTP, parses them and
+// dispatches the events to the registered handlers.
+type Handler struct {
+	user         string
+	password     string
+	maxBodyBytes int64
+	onError      func(*http.Request, error)
+	fields       map[v1.WebhookEventObject]*v2.CustomFieldResolver
+	store        Store
+	onDrop       func(context.Context, Event, DropReason)
+
+	mu       sync.RWMutex
+	routes   []route
+	inflight map[string]chan struct{}
+}
+
+type route struct {
+	object v1.WebhookEventObject
+	action v1.WebhookEventAction
+	fn     HandlerFunc
+}
+
+type Option func(*Handler)
+
+type fieldResolverSetter interface {
+	setFieldResolver(*v2.CustomFieldResolver)
+}
+
+// WithBasicAuth requires deliveries to carry the credentials the webhook was
+// created with through v1.WithWebhookHTTPAuthUser and
+// v1.WithWebhookHTTPAuthPassword. Without it every delivery is accepted.
+func WithBasicAuth(user, password string) Option {
+	return func(h *Handler) {
+		h.user = user
+		h.password = password
+	}
+}
+
+// WithMaxBodyBytes limits the accepted delivery size. The default is 5 MiB.
+func WithMaxBodyBytes(n int64) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxBodyBytes = n
+		}
+	}
+}
+
+// WithErrorHandler is called with deliveries that are rejected or whose
+// handlers fail, for logging.
+func WithErrorHandler(fn func(*http.Request, error)) Option {
+	return func(h *Handler) {
+		h.onError = fn
+	}
+}
+
+// WithCustomFieldResolver names the custom fields of object's events in
+// FieldChange.Name, using a resolver from the matching fields service such as
+// v2.DealFieldsService.Resolver.
+func WithCustomFieldResolver(object v1.WebhookEventObject, r *v2.CustomFieldResolver) Option {
+	return func(h *Handler) {
+		if h.fields == nil {
+			h.fields = make(map[v1.WebhookEventObject]*v2.CustomFieldResolver)
+		}
+		h.fields[object] = r
+	}
+}
+
+func NewHandler(opts ...Option) *Handler {
+	h := &Handler{maxBodyBytes: defaultMaxBodyBytes}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(h)
+		}
+	}
+	return h
+}
+
+// Handle registers fn for events matching object and action. Use
+// v1.WebhookEventObjectAll and v1.WebhookEventActionAll as wildcards.
+func (h *Handler) Handle(object v1.WebhookEventObject, action v1.WebhookEventAction, fn HandlerFunc) {
+	if fn == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.routes = append(h.routes, route{object: object, action: action, fn: fn})
+}
+
+func on[T any](h *Handler, object v1.WebhookEventObject, fn func(context.Context, *EntityEvent[T]) error) {
+	if fn == nil {
+		return
+	}
+	h.Handle(object, v1.WebhookEventActionAll, func(ctx context.Context, ev Event) error {
+		typed, ok := ev.(*EntityEvent[T])
+		if !ok {
+			return nil
+		}
+		return fn(ctx, typed)
+	})
+}
+
+func (h *Handler) OnDeal(fn func(context.Context, *DealEvent) error) {
+	on(h, v1.WebhookEventObjectDeal, fn)
+}
+
+func (h *Handler) OnPerson(fn func(context.Context, *PersonEvent) error) {
+	on(h, v1.WebhookEventObjectPerson, fn)
+}
+
+func (h *Handler) OnOrganization(fn func(context.Context, *OrganizationEvent) error) {
+	on(h, v1.WebhookEventObjectOrganization, fn)
+}
+
+func (h *Handler) OnProduct(fn func(context.Context, *ProductEvent) error) {
+	on(h, v1.WebhookEventObjectProduct, fn)
+}
+
+func (h *Handler) OnActivity(fn func(context.Context, *ActivityEvent) error) {
+	on(h, v1.WebhookEventObjectActivity, fn)
+}
+
+func (h *Handler) OnPipeline(fn func(context.Context, *PipelineEvent) error) {
+	on(h, v1.WebhookEventObjectPipeline, fn)
+}
+
+func (h *Handler) OnStage(fn func(context.Context, *StageEvent) error) {
+	on(h, v1.WebhookEventObjectStage, fn)
+}
+
+func (h *Handler) OnLead(fn func(context.Context, *LeadEvent) error) {
+	on(h, v1.WebhookEventObjectLead, fn)
+}
+
+func (h *Handler) OnNote(fn func(context.Context, *NoteEvent) error) {
+	on(h, v1.WebhookEventObjectNote, fn)
+}
+
+func (h *Handler) OnUser(fn func(context.Context, *UserEvent) error) {
+	on(h, v1.WebhookEventObjectUser, fn)
+}
+
+// Dispatch runs the handlers registered for ev in registration order and
+// joins their errors. It is what ServeHTTP calls after parsing, and can be
+// used to feed events from other sources through the same handlers.
+func (h *Handler) Dispatch(ctx context.Context, ev Event) error {
+	if h.store == nil {
+		return h.dispatch(ctx, ev)
+	}
+	unlock, err := h.lockEntity(ctx, ev)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	reason, err := h.admit(ctx, ev)
+	if err != nil {
+		return fmt.Errorf("webhook: deduplication store: %w", err)
+	}
+	if reason != "" {
+		if h.onDrop != nil {
+			h.onDrop(ctx, ev, reason)
+		}
+		return nil
+	}
+	if err := h.dispatch(ctx, ev); err != nil {
+		return err
+	}
+	if err := h.record(ctx, ev); err != nil {
+		return fmt.Errorf("webhook: deduplication store: %w", err)
+	}
+	return nil
+}
+
+func (h *Handler) dispatch(ctx context.Context, ev Event) error {
+	meta := ev.EventMeta()
+	if r, ok := h.fields[meta.Object]; ok {
+		if setter, ok := ev.(fieldResolverSetter); ok {
+			setter.setFieldResolver(r)
+		}
+	}
+	h.mu.RLock()
+	routes := append([]route(nil), h.routes...)
+	h.mu.RUnlock()
+
+	var errs []error
+	for _, r := range routes {
+		if r.object != v1.WebhookEventObjectAll && r.object != meta.Object {
+			continue
+		}
+		if r.action != v1.WebhookEventActionAll && r.action != meta.Action {
+			continue
+		}
+		if err := r.fn(ctx, ev); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		h.fail(w, r, http.StatusMethodNotAllowed, errors.New("webhook: method not allowed"))
+		return
+	}
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="pipedrive-webhook"`)
+		h.fail(w, r, http.StatusUnauthorized, errors.New("webhook: unauthorized"))
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.fail(w, r, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	ev, err := Parse(body)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := h.Dispatch(r.Context(), ev); err != nil {
+		h.fail(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.user == "" && h.password == "" {
+		return true
+	}
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(h.user)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(h.password)) == 1
+	return userOK && passwordOK
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if h.onError != nil {
+		h.onError(r, err)
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+var (
+	_ http.Handler = (*Handler)(nil)
+	_ Event        = (*EntityEvent[v2.Deal])(nil)
+)
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+)
+
+var (
+	v1Timestamp    = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`)
+	customFieldKey = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	numericString  = regexp.MustCompile(`^-?\d+$`)
+)
+
+// v1Renames maps version 1.0 keys to the API v2 names of the same field.
+var v1Renames = map[v1.WebhookEventObject]map[string]string{
+	v1.WebhookEventObjectDeal:         {"user_id": "owner_id"},
+	v1.WebhookEventObjectPerson:       {"user_id": "owner_id", "email": "emails", "phone": "phones"},
+	v1.WebhookEventObjectOrganization: {"user_id": "owner_id"},
+	v1.WebhookEventObjectProduct:      {"user_id": "owner_id"},
+	v1.WebhookEventObjectActivity:     {"user_id": "owner_id", "busy_flag": "busy"},
+}
+
+// normalizeV1 reshapes a version 1.0 entity payload so it decodes into the API
+// v2 model: custom fields keyed by their 40 character hash move under
+// custom_fields, "YYYY-MM-DD HH:MM:SS" timestamps become RFC 3339, related
+// objects collapse to their IDs, addresses sent as text become objects and
+// renamed keys get their v2 names.
+func normalizeV1(object v1.WebhookEventObject, raw json.RawMessage) (json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	renames := v1Renames[object]
+	out := make(map[string]interface{}, len(fields))
+	custom := map[string]interface{}{}
+	for key, value := range fields {
+		if customFieldKey.MatchString(key) {
+			custom[key] = value
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			switch {
+			case v1Timestamp.MatchString(v):
+				value = strings.Replace(v, " ", "T", 1) + "Z"
+			case key == "visible_to" && numericString.MatchString(v):
+				value = json.Number(v)
+			case key == "address" || key == "postal_address" || key == "location":
+				value = map[string]interface{}{"value": v}
+			}
+		case map[string]interface{}:
+			if id, ok := v["value"]; ok && strings.HasSuffix(key, "_id") {
+				value = id
+			}
+		}
+		if key == "label" {
+			if ids := labelIDs(value); ids != nil {
+				out["label_ids"] = ids
+			}
+			continue
+		}
+		if renamed, ok := renames[key]; ok {
+			if _, exists := fields[renamed]; !exists {
+				key = renamed
+			}
+		}
+		out[key] = value
+	}
+	if len(custom) > 0 {
+		out["custom_fields"] = custom
+	}
+	return json.Marshal(out)
+}
+
+// labelIDs converts the version 1.0 label field, a single ID or a comma
+// separated list, into label IDs.
+func labelIDs(value interface{}) []json.Number {
+	var parts []string
+	switch v := value.(type) {
+	case json.Number:
+		parts = []string{v.String()}
+	case string:
+		parts = strings.Split(v, ",")
+	default:
+		return nil
+	}
+	var ids []json.Number
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if numericString.MatchString(part) {
+			ids = append(ids, json.Number(part))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,366 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+)
+
+// Subscription is a webhook that should exist in the account.
+type Subscription struct {
+	Object v1.WebhookEventObject
+	Action v1.WebhookEventAction
+	URL    string
+	// Version defaults to 2.0.
+	Version v1.WebhookVersion
+	Name    string
+	// HTTPAuthUser and HTTPAuthPassword are sent with every delivery; see
+	// WithBasicAuth. Pipedrive does not always return the password, so a
+	// changed password is only detected when it does.
+	HTTPAuthUser     string
+	HTTPAuthPassword string
+	// UserID creates the webhook on behalf of another user. It is not
+	// compared with existing webhooks.
+	UserID v1.UserID
+}
+
+type SubscriptionChangeKind string
+
+const (
+	SubscriptionKeep    SubscriptionChangeKind = "keep"
+	SubscriptionCreate  SubscriptionChangeKind = "create"
+	SubscriptionDelete  SubscriptionChangeKind = "delete"
+	SubscriptionReplace SubscriptionChangeKind = "replace"
+	// SubscriptionMigrate replaces a version 1.0 webhook with a version 2.0
+	// one.
+	SubscriptionMigrate SubscriptionChangeKind = "migrate"
+)
+
+// SubscriptionChange is one planned or applied step of a reconciliation.
+// Webhooks cannot be updated, so replacements and migrations create the new
+// webhook before deleting Existing.
+type SubscriptionChange struct {
+	Kind     SubscriptionChangeKind
+	Desired  *Subscription
+	Existing *v1.Webhook
+	Created  *v1.Webhook
+	Done     bool
+	Err      error
+}
+
+// DisabledWebhook is a managed webhook Pipedrive stopped delivering to,
+// usually after repeated failed deliveries.
+type DisabledWebhook struct {
+	Webhook        v1.Webhook
+	RemoveReason   string
+	LastHTTPStatus int
+}
+
+type ReconcileReport struct {
+	DryRun   bool
+	Changes  []SubscriptionChange
+	Disabled []DisabledWebhook
+}
+
+type ReconcileOption func(*reconcileOptions)
+
+type reconcileOptions struct {
+	dryRun  bool
+	managed func(v1.Webhook) bool
+}
+
+// WithReconcileDryRun plans the changes without creating or deleting
+// webhooks.
+func WithReconcileDryRun(enabled bool) ReconcileOption {
+	return func(o *reconcileOptions) {
+		o.dryRun = enabled
+	}
+}
+
+// WithManagedURLPrefix limits the reconciliation to webhooks whose
+// subscription URL starts with prefix, so environments sharing an account
+// leave each other's webhooks alone. Every desired URL must have the prefix.
+func WithManagedURLPrefix(prefix string) ReconcileOption {
+	return WithManagedWebhooks(func(w v1.Webhook) bool {
+		return strings.HasPrefix(w.SubscriptionURL, prefix)
+	})
+}
+
+// WithManagedWebhooks limits the reconciliation to the webhooks fn accepts,
+// and makes Reconcile delete the accepted webhooks that are not desired.
+// Every desired subscription must be accepted, or it could not be matched
+// with the webhook created for it on the next run. Application and
+// automation webhooks are never managed.
+func WithManagedWebhooks(fn func(v1.Webhook) bool) ReconcileOption {
+	return func(o *reconcileOptions) {
+		o.managed = fn
+	}
+}
+
+// Reconcile converges the account's webhooks to desired: missing
+// subscriptions are created, managed webhooks nobody wants are deleted, and
+// webhooks that differ in version, name or credentials, or that Pipedrive
+// disabled, are replaced. Subscriptions are matched by object, action and
+// URL. The returned error joins every failed step; the report lists all of
+// them either way.
+//
+// Without WithManagedURLPrefix or WithManagedWebhooks nothing is deleted,
+// as the account's webhooks may belong to other environments or
+// integrations.
+func Reconcile(ctx context.Context, client *v1.Client, desired []Subscription, opts ...ReconcileOption) (*ReconcileReport, error) {
+	cfg := reconcileOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	for i := range desired {
+		if err := desired[i].validate(); err != nil {
+			return nil, fmt.Errorf("webhook: subscription %d: %w", i, err)
+		}
+		if cfg.managed != nil && !cfg.managed(desired[i].webhook()) {
+			return nil, fmt.Errorf("webhook: subscription %d: URL %s is outside the managed webhooks", i, desired[i].URL)
+		}
+	}
+
+	existing, err := client.Webhooks.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: list webhooks: %w", err)
+	}
+	var managed []v1.Webhook
+	for _, w := range existing {
+		if w.Type != "" && w.Type != v1.WebhookTypeGeneral {
+			continue
+		}
+		if cfg.managed == nil || cfg.managed(w) {
+			managed = append(managed, w)
+		}
+	}
+
+	report := &ReconcileReport{DryRun: cfg.dryRun}
+	for _, w := range managed {
+		if !bool(w.IsActive) {
+			d := DisabledWebhook{Webhook: w}
+			if w.RemoveReason != nil {
+				d.RemoveReason = *w.RemoveReason
+			}
+			if w.LastHTTPStatus != nil {
+				d.LastHTTPStatus = *w.LastHTTPStatus
+			}
+			report.Disabled = append(report.Disabled, d)
+		}
+	}
+
+	used := make([]bool, len(managed))
+	for i := range desired {
+		want := &desired[i]
+		change := SubscriptionChange{Kind: SubscriptionCreate, Desired: want}
+		match := -1
+		for j := range managed {
+			if used[j] || !want.sameTarget(managed[j]) {
+				continue
+			}
+			if kind := want.compare(managed[j]); rank(kind) < rank(change.Kind) {
+				match, change.Kind = j, kind
+			}
+		}
+		if match >= 0 {
+			used[match] = true
+			change.Existing = &managed[match]
+		}
+		report.Changes = append(report.Changes, change)
+	}
+	for j := range managed {
+		if !used[j] && cfg.managed != nil {
+			report.Changes = append(report.Changes, SubscriptionChange{Kind: SubscriptionDelete, Existing: &managed[j]})
+		}
+	}
+	if cfg.dryRun {
+		return report, nil
+	}
+
+	var errs []error
+	for i := range report.Changes {
+		change := &report.Changes[i]
+		if change.Kind == SubscriptionKeep {
+			continue
+		}
+		change.Err = change.apply(ctx, client)
+		if change.Err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %s %s: %w", change.Kind, change.describe(), change.Err))
+			continue
+		}
+		change.Done = true
+	}
+	return report, errors.Join(errs...)
+}
+
+func (s *Subscription) validate() error {
+	switch {
+	case s.Object == "":
+		return errors.New("event object is required")
+	case s.Action == "":
+		return errors.New("event action is required")
+	case s.URL == "":
+		return errors.New("subscription URL is required")
+	case s.Name == "":
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+// webhook returns the general webhook s creates, for WithManagedWebhooks.
+func (s *Subscription) webhook() v1.Webhook {
+	w := v1.Webhook{
+		EventObject:     string(s.Object),
+		EventAction:     string(s.Action),
+		SubscriptionURL: s.URL,
+		Version:         string(s.version()),
+		Name:            s.Name,
+		Type:            v1.WebhookTypeGeneral,
+		IsActive:        true,
+	}
+	if s.HTTPAuthUser != "" {
+		w.HTTPAuthUser = &s.HTTPAuthUser
+	}
+	return w
+}
+
+func (s *Subscription) version() v1.WebhookVersion {
+	if s.Version == "" {
+		return v1.WebhookVersion2
+	}
+	return s.Version
+}
+
+func (s *Subscription) sameTarget(w v1.Webhook) bool {
+	return w.EventObject == string(s.Object) && w.EventAction == string(s.Action) && w.SubscriptionURL == s.URL
+}
+
+// compare decides what it takes to turn w into s.
+func (s *Subscription) compare(w v1.Webhook) SubscriptionChangeKind {
+	if v1.WebhookVersion(w.Version) != s.version() {
+		if s.version() == v1.WebhookVersion2 && v1.WebhookVersion(w.Version) == v1.WebhookVersion1 {
+			return SubscriptionMigrate
+		}
+		return SubscriptionReplace
+	}
+	switch {
+	case !bool(w.IsActive), w.Name != s.Name, stringValue(w.HTTPAuthUser) != s.HTTPAuthUser:
+		return SubscriptionReplace
+	case w.HTTPAuthPassword != nil && *w.HTTPAuthPassword != s.HTTPAuthPassword:
+		return SubscriptionReplace
+	}
+	return SubscriptionKeep
+}
+
+// rank orders candidate matches so an up-to-date webhook is kept over one
+// that needs replacing.
+func rank(kind SubscriptionChangeKind) int {
+	switch kind {
+	case SubscriptionKeep:
+		return 0
+	case SubscriptionReplace:
+		return 1
+	case SubscriptionMigrate:
+		return 2
+	}
+	return 3
+}
+
+func (c *SubscriptionChange) apply(ctx context.Context, client *v1.Client) error {
+	if c.Desired != nil {
+		created, err := client.Webhooks.Create(ctx, c.Desired.createOptions()...)
+		if err != nil {
+			return err
+		}
+		c.Created = created
+	}
+	if c.Existing != nil {
+		ok, err := client.Webhooks.Delete(ctx, c.Existing.ID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("delete webhook %d was not successful", c.Existing.ID)
+		}
+	}
+	return nil
+}
+
+func (s *Subscription) createOptions() []v1.CreateWebhookOption {
+	opts := []v1.CreateWebhookOption{
+		v1.WithWebhookEventObject(s.Object),
+		v1.WithWebhookEventAction(s.Action),
+		v1.WithWebhookSubscriptionURL(s.URL),
+		v1.WithWebhookName(s.Name),
+		v1.WithWebhookVersion(s.version()),
+	}
+	if s.HTTPAuthUser != "" {
+		opts = append(opts, v1.WithWebhookHTTPAuthUser(s.HTTPAuthUser))
+	}
+	if s.HTTPAuthPassword != "" {
+		opts = append(opts, v1.WithWebhookHTTPAuthPassword(s.HTTPAuthPassword))
+	}
+	if s.UserID != 0 {
+		opts = append(opts, v1.WithWebhookUserID(s.UserID))
+	}
+	return opts
+}
+
+func (c *SubscriptionChange) describe() string {
+	if c.Desired != nil {
+		return fmt.Sprintf("%s.%s -> %s (%s)", c.Desired.Action, c.Desired.Object, c.Desired.URL, c.Desired.version())
+	}
+	return fmt.Sprintf("webhook %d %s.%s -> %s (%s)", c.Existing.ID, c.Existing.EventAction, c.Existing.EventObject, c.Existing.SubscriptionURL, c.Existing.Version)
+}
+
+// WriteTo writes a plain text summary of the changes and disabled webhooks.
+func (r *ReconcileReport) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	mode := "applied"
+	if r.DryRun {
+		mode = "dry run"
+	}
+	fmt.Fprintf(&b, "%d webhook changes (%s)\n", r.pending(), mode)
+	for i := range r.Changes {
+		c := &r.Changes[i]
+		status := "planned"
+		switch {
+		case c.Kind == SubscriptionKeep:
+			status = "up to date"
+		case c.Err != nil:
+			status = "failed: " + c.Err.Error()
+		case c.Done:
+			status = "done"
+		}
+		fmt.Fprintf(&b, "  %-7s %s  %s\n", c.Kind, c.describe(), status)
+	}
+	for _, d := range r.Disabled {
+		fmt.Fprintf(&b, "disabled webhook %d %s.%s -> %s: %s (last HTTP status %d)\n",
+			d.Webhook.ID, d.Webhook.EventAction, d.Webhook.EventObject, d.Webhook.SubscriptionURL, d.RemoveReason, d.LastHTTPStatus)
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (r *ReconcileReport) pending() int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Kind != SubscriptionKeep {
+			n++
+		}
+	}
+	return n
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
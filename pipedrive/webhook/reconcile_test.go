@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/juhokoskela/pipedrive-go/pipedrive"
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+)
+
+type fakeWebhooks struct {
+	mu       sync.Mutex
+	webhooks []map[string]interface{}
+	nextID   int
+	calls    []string
+	failPost bool
+}
+
+func (f *fakeWebhooks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/webhooks":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": f.webhooks})
+	case r.Method == http.MethodPost && r.URL.Path == "/webhooks":
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.calls = append(f.calls, "create "+body["event_action"].(string)+"."+body["event_object"].(string)+" "+body["version"].(string))
+		if f.failPost {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"success":false,"error":"bad subscription"}`))
+			return
+		}
+		f.nextID++
+		body["id"] = f.nextID
+		body["is_active"] = 1
+		f.webhooks = append(f.webhooks, body)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": body})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/webhooks/"):
+		id := strings.TrimPrefix(r.URL.Path, "/webhooks/")
+		f.calls = append(f.calls, "delete "+id)
+		for i, hook := range f.webhooks {
+			if jsonID(hook["id"]) == id {
+				f.webhooks = append(f.webhooks[:i], f.webhooks[i+1:]...)
+				break
+			}
+		}
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":1}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func jsonID(value interface{}) string {
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
+
+func newReconcileClient(t *testing.T, fake *fakeWebhooks) *v1.Client {
+	t.Helper()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	client, err := v1.NewClient(pipedrive.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+	if err != nil {
+		t.Fatalf("NewClient error: %v", err)
+	}
+	return client
+}
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeWebhooks{nextID: 100, webhooks: []map[string]interface{}{
+		{"id": 1, "event_action": "change", "event_object": "deal", "subscription_url": "https://prod.test/hook", "version": "2.0", "name": "deals", "http_auth_user": "hook", "is_active": 1, "type": "general"},
+		{"id": 2, "event_action": "*", "event_object": "person", "subscription_url": "https://prod.test/hook", "version": "1.0", "name": "persons", "http_auth_user": "hook", "is_active": 1, "type": "general"},
+		{"id": 3, "event_action": "create", "event_object": "note", "subscription_url": "https://prod.test/hook", "version": "2.0", "name": "notes", "is_active": 0, "remove_reason": "Too many failures", "last_http_status": 502, "type": "general"},
+		{"id": 4, "event_action": "*", "event_object": "*", "subscription_url": "https://prod.test/old", "version": "2.0", "name": "old", "is_active": 1, "type": "general"},
+		{"id": 5, "event_action": "*", "event_object": "*", "subscription_url": "https://staging.test/hook", "version": "2.0", "name": "staging", "is_active": 1, "type": "general"},
+		{"id": 6, "event_action": "*", "event_object": "*", "subscription_url": "https://prod.test/app", "version": "2.0", "name": "app", "is_active": 1, "type": "application"},
+	}}
+	client := newReconcileClient(t, fake)
+	desired := []Subscription{
+		{Object: v1.WebhookEventObjectDeal, Action: v1.WebhookEventActionChange, URL: "https://prod.test/hook", Name: "deals", HTTPAuthUser: "hook", HTTPAuthPassword: "secret"},
+		{Object: v1.WebhookEventObjectPerson, Action: v1.WebhookEventActionAll, URL: "https://prod.test/hook", Name: "persons", HTTPAuthUser: "hook", HTTPAuthPassword: "secret"},
+		{Object: v1.WebhookEventObjectNote, Action: v1.WebhookEventActionCreate, URL: "https://prod.test/hook", Name: "notes"},
+		{Object: v1.WebhookEventObjectActivity, Action: v1.WebhookEventActionAll, URL: "https://prod.test/hook", Name: "activities"},
+	}
+	ctx := context.Background()
+
+	plan, err := Reconcile(ctx, client, desired, WithManagedURLPrefix("https://prod.test/"), WithReconcileDryRun(true))
+	if err != nil {
+		t.Fatalf("dry run error: %v", err)
+	}
+	var kinds []string
+	for _, c := range plan.Changes {
+		kinds = append(kinds, string(c.Kind))
+	}
+	if got := strings.Join(kinds, " "); got != "keep migrate replace create delete" || len(fake.calls) != 0 {
+		t.Fatalf("unexpected plan %q, calls %v", got, fake.calls)
+	}
+	if len(plan.Disabled) != 1 || plan.Disabled[0].Webhook.ID != 3 || plan.Disabled[0].RemoveReason != "Too many failures" || plan.Disabled[0].LastHTTPStatus != 502 {
+		t.Fatalf("unexpected disabled webhooks: %#v", plan.Disabled)
+	}
+	var text strings.Builder
+	if _, err := plan.WriteTo(&text); err != nil || !strings.Contains(text.String(), "4 webhook changes (dry run)") || !strings.Contains(text.String(), "Too many failures (last HTTP status 502)") {
+		t.Fatalf("unexpected report:\n%s", text.String())
+	}
+
+	report, err := Reconcile(ctx, client, desired, WithManagedURLPrefix("https://prod.test/"))
+	if err != nil {
+		t.Fatalf("Reconcile error: %v", err)
+	}
+	want := []string{
+		"create *.person 2.0", "delete 2",
+		"create create.note 2.0", "delete 3",
+		"create *.activity 2.0",
+		"delete 4",
+	}
+	if strings.Join(fake.calls, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("unexpected calls: %v", fake.calls)
+	}
+	for _, c := range report.Changes {
+		if c.Kind != SubscriptionKeep && !c.Done {
+			t.Fatalf("change not applied: %#v", c)
+		}
+	}
+
+	fake.calls = nil
+	again, err := Reconcile(ctx, client, desired, WithManagedURLPrefix("https://prod.test/"))
+	if err != nil || len(fake.calls) != 0 || again.pending() != 0 {
+		t.Fatalf("expected converged account, calls %v, err %v", fake.calls, err)
+	}
+}
+
+func TestReconcileErrors(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeWebhooks{failPost: true}
+	client := newReconcileClient(t, fake)
+	ctx := context.Background()
+
+	if _, err := Reconcile(ctx, client, []Subscription{{Object: v1.WebhookEventObjectDeal, Action: v1.WebhookEventActionAll, Name: "x"}}); err == nil || !strings.Contains(err.Error(), "subscription URL is required") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	outside := []Subscription{{Object: v1.WebhookEventObjectDeal, Action: v1.WebhookEventActionAll, URL: "https://staging.test/hook", Name: "x"}}
+	if _, err := Reconcile(ctx, client, outside, WithManagedURLPrefix("https://prod.test/")); err == nil || !strings.Contains(err.Error(), "outside the managed webhooks") {
+		t.Fatalf("expected managed URL error, got %v", err)
+	}
+
+	report, err := Reconcile(ctx, client, []Subscription{{Object: v1.WebhookEventObjectDeal, Action: v1.WebhookEventActionAll, URL: "https://prod.test/hook", Name: "x"}})
+	var apiErr *pipedrive.APIError
+	if !errors.As(err, &apiErr) || report == nil || report.Changes[0].Err == nil || report.Changes[0].Done {
+		t.Fatalf("expected failed create, got %v, %#v", err, report)
+	}
+}
+
+func TestReconcileUnscopedKeepsOtherWebhooks(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeWebhooks{nextID: 100, webhooks: []map[string]interface{}{
+		{"id": 1, "event_action": "*", "event_object": "deal", "subscription_url": "https://prod.test/hook", "version": "2.0", "name": "deals", "is_active": 1, "type": "general"},
+		{"id": 2, "event_action": "*", "event_object": "*", "subscription_url": "https://other.test/hook", "version": "2.0", "name": "other", "is_active": 1, "type": "general"},
+	}}
+	client := newReconcileClient(t, fake)
+
+	report, err := Reconcile(context.Background(), client, []Subscription{
+		{Object: v1.WebhookEventObjectDeal, Action: v1.WebhookEventActionAll, URL: "https://prod.test/hook", Name: "deals"},
+	})
+	if err != nil {
+		t.Fatalf("Reconcile error: %v", err)
+	}
+	if len(fake.calls) != 0 || len(report.Changes) != 1 || report.Changes[0].Kind != SubscriptionKeep {
+		t.Fatalf("unexpected changes %#v, calls %v", report.Changes, fake.calls)
+	}
+}
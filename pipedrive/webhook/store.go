@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const defaultStoreCapacity = 10000
+
+// MemoryStore is a Store that keeps the most recently used delivery keys and
+// entity update times, up to its capacity each.
+type MemoryStore struct {
+	mu         sync.Mutex
+	deliveries *lru[struct{}]
+	entities   *lru[time.Time]
+}
+
+// NewMemoryStore returns a store holding up to capacity deliveries and
+// entities. A capacity of zero or less uses 10000.
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = defaultStoreCapacity
+	}
+	return &MemoryStore{deliveries: newLRU[struct{}](capacity), entities: newLRU[time.Time](capacity)}
+}
+
+func (s *MemoryStore) Seen(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.deliveries.get(key)
+	return ok, nil
+}
+
+func (s *MemoryStore) LastUpdate(ctx context.Context, entity string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.entities.get(entity)
+	return t, ok, nil
+}
+
+func (s *MemoryStore) Record(ctx context.Context, key, entity string, updated time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(key, entity, updated)
+	return nil
+}
+
+func (s *MemoryStore) record(key, entity string, updated time.Time) {
+	s.deliveries.put(key, struct{}{})
+	if updated.IsZero() {
+		return
+	}
+	if last, ok := s.entities.get(entity); ok && !updated.After(last) {
+		return
+	}
+	s.entities.put(entity, updated)
+}
+
+// FileStore is a MemoryStore persisted to a JSON file, so a restarted
+// receiver still recognises redeliveries. The file is rewritten atomically
+// on every Record, which suits a single receiver process at webhook rates.
+type FileStore struct {
+	*MemoryStore
+	path string
+}
+
+type fileStoreState struct {
+	// Deliveries and Entities are ordered from least to most recently used.
+	Deliveries []string          `json:"deliveries"`
+	Entities   []fileStoreEntity `json:"entities"`
+}
+
+type fileStoreEntity struct {
+	Entity     string    `json:"entity"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+// NewFileStore opens the store at path, creating it on the first Record.
+// Capacity works as for NewMemoryStore.
+func NewFileStore(path string, capacity int) (*FileStore, error) {
+	s := &FileStore{MemoryStore: NewMemoryStore(capacity), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("webhook: read store: %w", err)
+	}
+	var state fileStoreState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("webhook: decode store %s: %w", path, err)
+	}
+	for _, key := range state.Deliveries {
+		s.deliveries.put(key, struct{}{})
+	}
+	for _, e := range state.Entities {
+		s.entities.put(e.Entity, e.UpdateTime)
+	}
+	return s, nil
+}
+
+func (s *FileStore) Record(ctx context.Context, key, entity string, updated time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(key, entity, updated)
+
+	state := fileStoreState{Deliveries: []string{}, Entities: []fileStoreEntity{}}
+	s.deliveries.each(func(key string, _ struct{}) {
+		state.Deliveries = append(state.Deliveries, key)
+	})
+	s.entities.each(func(entity string, t time.Time) {
+		state.Entities = append(state.Entities, fileStoreEntity{Entity: entity, UpdateTime: t})
+	})
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("webhook: encode store: %w", err)
+	}
+	return writeFileAtomic(s.path, data)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("webhook: write store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("webhook: write store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("webhook: write store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("webhook: write store: %w", err)
+	}
+	return nil
+}
+
+type lru[V any] struct {
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+}
+
+func newLRU[V any](capacity int) *lru[V] {
+	return &lru[V]{capacity: capacity, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *lru[V]) get(key string) (V, bool) {
+	el, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry[V]).value, true
+}
+
+func (c *lru[V]) put(key string, value V) {
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry[V]).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+// each visits the entries from least to most recently used.
+func (c *lru[V]) each(fn func(string, V)) {
+	for el := c.order.Back(); el != nil; el = el.Prev() {
+		entry := el.Value.(*lruEntry[V])
+		fn(entry.key, entry.value)
+	}
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*FileStore)(nil)
+)
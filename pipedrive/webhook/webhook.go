@@ -0,0 +1,332 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+	v2 "github.com/juhokoskela/pipedrive-go/pipedrive/v2"
+)
+
+// Meta describes a webhook delivery. Version 1.0 and 2.0 payloads are mapped
+// onto the same fields; values a version does not send are left empty.
+type Meta struct {
+	Version v1.WebhookVersion
+	// Action is create, change or delete. Version 1.0 merges are reported as
+	// changes.
+	Action v1.WebhookEventAction
+	Object v1.WebhookEventObject
+	// Event is the event name as delivered, such as "updated.deal" for
+	// version 1.0 or "change.deal" for version 2.0.
+	Event    string
+	EntityID string
+	// ID identifies the event. Version 1.0 payloads carry no event ID.
+	ID            string
+	CorrelationID string
+	WebhookID     string
+	CompanyID     string
+	UserID        string
+	Host          string
+	Timestamp     time.Time
+	ChangeSource  string
+	IsBulkEdit    bool
+	// Attempt counts deliveries of the event, starting at 1.
+	Attempt          int
+	PermittedUserIDs []string
+}
+
+// Event is a parsed webhook delivery. The concrete type is one of the
+// *EntityEvent aliases below, chosen by Meta.Object.
+type Event interface {
+	EventMeta() Meta
+}
+
+// EntityEvent carries the entity state after and before the event. Current is
+// nil for deletions and Previous is nil for creations. Version 2.0 payloads
+// only include changed fields in previous, so Previous is sparse for them.
+type EntityEvent[T any] struct {
+	Meta     Meta
+	Current  *T
+	Previous *T
+	// RawCurrent and RawPrevious hold the entity payloads as delivered.
+	RawCurrent  json.RawMessage
+	RawPrevious json.RawMessage
+
+	fields *v2.CustomFieldResolver
+}
+
+func (e *EntityEvent[T]) EventMeta() Meta {
+	return e.Meta
+}
+
+type (
+	DealEvent         = EntityEvent[v2.Deal]
+	PersonEvent       = EntityEvent[v2.Person]
+	OrganizationEvent = EntityEvent[v2.Organization]
+	ProductEvent      = EntityEvent[v2.Product]
+	ActivityEvent     = EntityEvent[v2.Activity]
+	PipelineEvent     = EntityEvent[v2.Pipeline]
+	StageEvent        = EntityEvent[v2.Stage]
+	LeadEvent         = EntityEvent[v1.Lead]
+	NoteEvent         = EntityEvent[v1.Note]
+	UserEvent         = EntityEvent[v1.User]
+	// GenericEvent carries objects this package has no model for.
+	GenericEvent = EntityEvent[map[string]interface{}]
+)
+
+// PayloadError reports a delivery body that is not a Pipedrive webhook
+// payload.
+type PayloadError struct {
+	Err error
+}
+
+func (e *PayloadError) Error() string {
+	if e == nil || e.Err == nil {
+		return "invalid webhook payload"
+	}
+	return "invalid webhook payload: " + e.Err.Error()
+}
+
+func (e *PayloadError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+type envelope struct {
+	Meta     rawMeta         `json:"meta"`
+	Data     json.RawMessage `json:"data"`
+	Current  json.RawMessage `json:"current"`
+	Previous json.RawMessage `json:"previous"`
+	Event    string          `json:"event"`
+	Retry    *int            `json:"retry"`
+}
+
+type rawMeta struct {
+	Version          flexString      `json:"version"`
+	V                flexString      `json:"v"`
+	Action           string          `json:"action"`
+	Object           string          `json:"object"`
+	Entity           string          `json:"entity"`
+	ID               flexString      `json:"id"`
+	EntityID         flexString      `json:"entity_id"`
+	CorrelationID    flexString      `json:"correlation_id"`
+	WebhookID        flexString      `json:"webhook_id"`
+	CompanyID        flexString      `json:"company_id"`
+	UserID           flexString      `json:"user_id"`
+	Host             string          `json:"host"`
+	Timestamp        json.RawMessage `json:"timestamp"`
+	TimestampMicro   json.RawMessage `json:"timestamp_micro"`
+	ChangeSource     string          `json:"change_source"`
+	IsBulkEdit       bool            `json:"is_bulk_edit"`
+	IsBulkUpdate     bool            `json:"is_bulk_update"`
+	Attempt          int             `json:"attempt"`
+	PermittedUserIDs []flexString    `json:"permitted_user_ids"`
+}
+
+// flexString decodes IDs that one payload version sends as numbers and the
+// other as strings.
+type flexString string
+
+func (s *flexString) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*s = flexString(value)
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	*s = flexString(number.String())
+	return nil
+}
+
+var v1Actions = map[string]v1.WebhookEventAction{
+	"added":   v1.WebhookEventActionCreate,
+	"updated": v1.WebhookEventActionChange,
+	"merged":  v1.WebhookEventActionChange,
+	"deleted": v1.WebhookEventActionDelete,
+}
+
+// Parse decodes a version 1.0 or 2.0 webhook body into a typed event.
+func Parse(body []byte) (Event, error) {
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, &PayloadError{Err: err}
+	}
+	meta, current, previous, err := env.normalize()
+	if err != nil {
+		return nil, &PayloadError{Err: err}
+	}
+	ev, err := decodeEvent(meta, current, previous)
+	if err != nil {
+		return nil, &PayloadError{Err: err}
+	}
+	return ev, nil
+}
+
+func (env envelope) normalize() (Meta, json.RawMessage, json.RawMessage, error) {
+	m := env.Meta
+	meta := Meta{
+		WebhookID:     string(m.WebhookID),
+		CompanyID:     string(m.CompanyID),
+		UserID:        string(m.UserID),
+		Host:          m.Host,
+		ChangeSource:  m.ChangeSource,
+		CorrelationID: string(m.CorrelationID),
+	}
+	for _, id := range m.PermittedUserIDs {
+		meta.PermittedUserIDs = append(meta.PermittedUserIDs, string(id))
+	}
+
+	if m.Version == flexString(v1.WebhookVersion2) || (m.Entity != "" && env.Event == "") {
+		meta.Version = v1.WebhookVersion2
+		meta.Action = v1.WebhookEventAction(m.Action)
+		meta.Object = v1.WebhookEventObject(m.Entity)
+		meta.Event = m.Action + "." + m.Entity
+		meta.EntityID = string(m.EntityID)
+		meta.ID = string(m.ID)
+		meta.IsBulkEdit = m.IsBulkEdit
+		meta.Attempt = max(m.Attempt, 1)
+		if ts := unquote(m.Timestamp); ts != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, ts)
+			if err != nil {
+				return Meta{}, nil, nil, fmt.Errorf("meta.timestamp: %w", err)
+			}
+			meta.Timestamp = parsed
+		}
+		if meta.Object == "" || meta.Action == "" {
+			return Meta{}, nil, nil, errors.New("meta.entity and meta.action are required")
+		}
+		return meta, env.Data, env.Previous, nil
+	}
+
+	meta.Version = v1.WebhookVersion1
+	action, ok := v1Actions[m.Action]
+	if !ok {
+		return Meta{}, nil, nil, fmt.Errorf("unknown version 1.0 action %q", m.Action)
+	}
+	meta.Action = action
+	meta.Object = v1.WebhookEventObject(m.Object)
+	meta.Event = env.Event
+	if meta.Event == "" {
+		meta.Event = m.Action + "." + m.Object
+	}
+	meta.EntityID = string(m.ID)
+	meta.IsBulkEdit = m.IsBulkUpdate
+	meta.Attempt = 1
+	if env.Retry != nil {
+		meta.Attempt = *env.Retry + 1
+	}
+	if micro, err := strconv.ParseInt(unquote(m.TimestampMicro), 10, 64); err == nil {
+		meta.Timestamp = time.UnixMicro(micro).UTC()
+	} else if seconds, err := strconv.ParseInt(unquote(m.Timestamp), 10, 64); err == nil {
+		meta.Timestamp = time.Unix(seconds, 0).UTC()
+	}
+	if meta.Object == "" {
+		return Meta{}, nil, nil, errors.New("meta.object is required")
+	}
+	return meta, env.Current, env.Previous, nil
+}
+
+func unquote(raw json.RawMessage) string {
+	s := strings.TrimSpace(string(raw))
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	if s == "null" {
+		return ""
+	}
+	return s
+}
+
+// v2Models lists the objects whose events carry API v2 models.
+var v2Models = map[v1.WebhookEventObject]bool{
+	v1.WebhookEventObjectDeal:         true,
+	v1.WebhookEventObjectPerson:       true,
+	v1.WebhookEventObjectOrganization: true,
+	v1.WebhookEventObjectProduct:      true,
+	v1.WebhookEventObjectActivity:     true,
+	v1.WebhookEventObjectPipeline:     true,
+	v1.WebhookEventObjectStage:        true,
+}
+
+func decodeEvent(meta Meta, current, previous json.RawMessage) (Event, error) {
+	switch meta.Object {
+	case v1.WebhookEventObjectDeal:
+		return decodeEntity[v2.Deal](meta, current, previous)
+	case v1.WebhookEventObjectPerson:
+		return decodeEntity[v2.Person](meta, current, previous)
+	case v1.WebhookEventObjectOrganization:
+		return decodeEntity[v2.Organization](meta, current, previous)
+	case v1.WebhookEventObjectProduct:
+		return decodeEntity[v2.Product](meta, current, previous)
+	case v1.WebhookEventObjectActivity:
+		return decodeEntity[v2.Activity](meta, current, previous)
+	case v1.WebhookEventObjectPipeline:
+		return decodeEntity[v2.Pipeline](meta, current, previous)
+	case v1.WebhookEventObjectStage:
+		return decodeEntity[v2.Stage](meta, current, previous)
+	case v1.WebhookEventObjectLead:
+		return decodeEntity[v1.Lead](meta, current, previous)
+	case v1.WebhookEventObjectNote:
+		return decodeEntity[v1.Note](meta, current, previous)
+	case v1.WebhookEventObjectUser:
+		return decodeEntity[v1.User](meta, current, previous)
+	default:
+		return decodeEntity[map[string]interface{}](meta, current, previous)
+	}
+}
+
+// decodeEntity decodes the current and previous payloads. Version 1.0 payloads
+// of objects modelled after API v2 are reshaped first; see normalizeV1.
+func decodeEntity[T any](meta Meta, current, previous json.RawMessage) (*EntityEvent[T], error) {
+	ev := &EntityEvent[T]{Meta: meta, RawCurrent: present(current), RawPrevious: present(previous)}
+	decode := func(name string, raw json.RawMessage) (*T, error) {
+		if raw == nil {
+			return nil, nil
+		}
+		if v2Models[meta.Object] && meta.Version == v1.WebhookVersion1 {
+			normalized, err := normalizeV1(meta.Object, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			raw = normalized
+		}
+		value := new(T)
+		if err := json.Unmarshal(raw, value); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", name, meta.Object, err)
+		}
+		return value, nil
+	}
+	var err error
+	if ev.Current, err = decode("current", ev.RawCurrent); err != nil {
+		return nil, err
+	}
+	if ev.Previous, err = decode("previous", ev.RawPrevious); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+func present(raw json.RawMessage) json.RawMessage {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+	return raw
+}
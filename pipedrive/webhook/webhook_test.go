@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/juhokoskela/pipedrive-go/pipedrive/v1"
+)
+
+const v2DealChange = `{
+	"data": {"id": 42, "title": "Big deal", "stage_id": 3, "value": 1500.5, "currency": "EUR", "update_time": "2024-04-16T12:00:00Z", "custom_fields": {"abc": {"type": "varchar", "value": "x"}}},
+	"previous": {"stage_id": 2},
+	"meta": {"action": "change", "entity": "deal", "entity_id": "42", "id": "8a1e0f4c-0000-4000-8000-000000000001", "correlation_id": "c0ffee", "company_id": "7", "user_id": "9", "timestamp": "2024-04-16T12:00:00.123Z", "version": "2.0", "webhook_id": "11", "attempt": 2, "is_bulk_edit": false, "permitted_user_ids": ["9"], "change_source": "app"}
+}`
+
+const v1PersonUpdate = `{
+	"v": 1,
+	"event": "updated.person",
+	"retry": 0,
+	"current": {"id": 5, "name": "Jane Doe", "user_id": 9, "org_id": {"name": "Acme", "value": 3}, "email": [{"label": "work", "value": "jane@acme.test", "primary": true}], "visible_to": "3", "update_time": "2024-04-16 12:00:00", "postal_address": "Main St 1", "label": "4,5", "0123456789abcdef0123456789abcdef01234567": "custom"},
+	"previous": {"id": 5, "name": "Jane", "user_id": 9, "update_time": "2024-04-15 08:00:00"},
+	"meta": {"v": 1, "action": "updated", "object": "person", "id": 5, "company_id": 7, "user_id": 9, "host": "acme.pipedrive.com", "timestamp": 1713268800, "timestamp_micro": 1713268800123456, "webhook_id": "11", "is_bulk_update": false}
+}`
+
+func TestParseVersion2(t *testing.T) {
+	t.Parallel()
+
+	ev, err := Parse([]byte(v2DealChange))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	deal, ok := ev.(*DealEvent)
+	if !ok {
+		t.Fatalf("expected *DealEvent, got %T", ev)
+	}
+	meta := deal.Meta
+	if meta.Version != v1.WebhookVersion2 || meta.Action != v1.WebhookEventActionChange || meta.Object != v1.WebhookEventObjectDeal {
+		t.Fatalf("unexpected meta: %#v", meta)
+	}
+	if meta.EntityID != "42" || meta.CorrelationID != "c0ffee" || meta.Attempt != 2 || meta.Event != "change.deal" || !meta.Timestamp.Equal(time.Date(2024, 4, 16, 12, 0, 0, 123e6, time.UTC)) {
+		t.Fatalf("unexpected meta: %#v", meta)
+	}
+	if deal.Current == nil || deal.Current.ID != 42 || *deal.Current.StageID != 3 {
+		t.Fatalf("unexpected current: %#v", deal.Current)
+	}
+	if deal.Previous == nil || *deal.Previous.StageID != 2 {
+		t.Fatalf("unexpected previous: %#v", deal.Previous)
+	}
+	if money, _ := deal.Current.ValueMoney(); money.String() != "1500.50 EUR" {
+		t.Fatalf("unexpected value: %s", money)
+	}
+}
+
+func TestParseVersion1(t *testing.T) {
+	t.Parallel()
+
+	ev, err := Parse([]byte(v1PersonUpdate))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	person, ok := ev.(*PersonEvent)
+	if !ok {
+		t.Fatalf("expected *PersonEvent, got %T", ev)
+	}
+	if person.Meta.Version != v1.WebhookVersion1 || person.Meta.Action != v1.WebhookEventActionChange || person.Meta.EntityID != "5" || person.Meta.Attempt != 1 {
+		t.Fatalf("unexpected meta: %#v", person.Meta)
+	}
+	if !person.Meta.Timestamp.Equal(time.UnixMicro(1713268800123456)) {
+		t.Fatalf("unexpected timestamp: %s", person.Meta.Timestamp)
+	}
+	p := person.Current
+	if p.Name != "Jane Doe" || p.OwnerID == nil || *p.OwnerID != 9 || p.OrgID == nil || *p.OrgID != 3 {
+		t.Fatalf("unexpected person: %#v", p)
+	}
+	if len(p.Emails) != 1 || p.Emails[0].Value != "jane@acme.test" || p.VisibleTo == nil || *p.VisibleTo != 3 {
+		t.Fatalf("unexpected person contact data: %#v", p)
+	}
+	if p.UpdateTime == nil || !p.UpdateTime.Equal(time.Date(2024, 4, 16, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected update time: %v", p.UpdateTime)
+	}
+	if p.PostalAddress == nil || p.PostalAddress.Value != "Main St 1" || len(p.LabelIDs) != 2 || p.CustomFields["0123456789abcdef0123456789abcdef01234567"] != "custom" {
+		t.Fatalf("unexpected person fields: %#v", p)
+	}
+	if person.Previous == nil || person.Previous.Name != "Jane" {
+		t.Fatalf("unexpected previous: %#v", person.Previous)
+	}
+}
+
+func TestParseOtherObjects(t *testing.T) {
+	t.Parallel()
+
+	ev, err := Parse([]byte(`{"data":{"id":"3d2c1b0a-0000-4000-8000-000000000000","title":"Lead"},"previous":null,"meta":{"action":"create","entity":"lead","entity_id":"3d2c1b0a-0000-4000-8000-000000000000","version":"2.0"}}`))
+	if err != nil {
+		t.Fatalf("Parse lead error: %v", err)
+	}
+	if lead, ok := ev.(*LeadEvent); !ok || lead.Current.Title != "Lead" || lead.Previous != nil {
+		t.Fatalf("unexpected lead event: %#v", ev)
+	}
+
+	ev, err = Parse([]byte(`{"data":null,"previous":{"id":1,"title":"Board"},"meta":{"action":"delete","entity":"project","entity_id":"1","version":"2.0"}}`))
+	if err != nil {
+		t.Fatalf("Parse project error: %v", err)
+	}
+	if generic, ok := ev.(*GenericEvent); !ok || generic.Current != nil || (*generic.Previous)["title"] != "Board" {
+		t.Fatalf("unexpected generic event: %#v", ev)
+	}
+
+	var payloadErr *PayloadError
+	for _, body := range []string{`not json`, `{"meta":{"v":1,"action":"exploded","object":"deal"}}`, `{"data":{"id":"x"},"meta":{"action":"change","entity":"deal","version":"2.0"}}`} {
+		if _, err := Parse([]byte(body)); !errors.As(err, &payloadErr) {
+			t.Fatalf("Parse(%s): expected *PayloadError, got %v", body, err)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	var deals, all int
+	failNext := false
+	h := NewHandler(WithBasicAuth("hook", "s3cret"))
+	h.OnDeal(func(ctx context.Context, ev *DealEvent) error {
+		deals++
+		if failNext {
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	})
+	h.Handle(v1.WebhookEventObjectAll, v1.WebhookEventActionChange, func(ctx context.Context, ev Event) error {
+		all++
+		return nil
+	})
+	h.OnPerson(func(ctx context.Context, ev *PersonEvent) error {
+		t.Fatalf("person handler called for %s", ev.Meta.Object)
+		return nil
+	})
+
+	post := func(body, user, password string) int {
+		req := httptest.NewRequest(http.MethodPost, "/pipedrive", strings.NewReader(body))
+		if user != "" {
+			req.SetBasicAuth(user, password)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := post(v2DealChange, "hook", "s3cret"); code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", code)
+	}
+	if deals != 1 || all != 1 {
+		t.Fatalf("unexpected dispatch counts: deals=%d all=%d", deals, all)
+	}
+	if code := post(v2DealChange, "hook", "wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", code)
+	}
+	if code := post(v2DealChange, "", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", code)
+	}
+	if code := post(`{}`, "hook", "s3cret"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", code)
+	}
+	failNext = true
+	if code := post(v2DealChange, "hook", "s3cret"); code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 on handler error, got %d", code)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pipedrive", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+
+	small := NewHandler(WithMaxBodyBytes(10))
+	rec = httptest.NewRecorder()
+	small.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(v2DealChange)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", rec.Code)
+	}
+}